@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"teerminal/constants"
+	"teerminal/sdk/go"
+	"teerminal/web"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+const usage = `Usage: teerminal-cli [flags] <command> [command flags]
+
+Commands:
+  device key [-formats f]            Get the device key, verified against the vendor root
+  device challenge                   Get a nonce for version attestation
+  device version [-key k]            Get a version attestation for a fresh nonce, signed by the requester key
+                                     (random if not set), and verify it
+  app key [-formats f]               Get the app key, verified against the vendor root
+  sign (-data hex | -text s)         Sign with the app key
+  kv write -key k -value v [-overwrite] [-protected p] [-provisioner key]
+                                     Write a key-value pair, provisioned by the provisioner private key if set
+  kv read -key k                     Read a key-value pair
+  kv delete -key k                   Delete a key-value pair
+  kv quota                           Get the kv quota
+  verify signature -data hex -signature hex (-pubkey hex | -address a)
+  verify chain -chain hex [-root hex]
+  verify attestation -attestation hex -response file [-root hex]
+  verify enrollment -enrollment file -chain hex [-root hex]
+  verify vrf -pubkey hex -alpha hex -proof hex
+                                     Verify with the device's diagnostics, files are JSON responses of the api, - is stdin
+
+Flags:
+`
+
+var (
+	output string
+	client *sdk.Client
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	baseUrl := flag.String("url", envOr("TEERMINAL_URL", "http://127.0.0.1:4100"), "Device url, or TEERMINAL_URL")
+	root := flag.String("root", os.Getenv("TEERMINAL_VENDOR_ROOT"), "Vendor root public key in hex, or TEERMINAL_VENDOR_ROOT, keys and signatures are verified against it")
+	timeout := flag.Duration("timeout", 10*time.Second, "Request timeout")
+	flag.StringVar(&output, "o", "text", "Output format: text or json")
+	flag.Parse()
+	if output != "text" && output != "json" {
+		fail(fmt.Errorf("unknown output format %q", output))
+	}
+	vendorRoot, err := decodeHex("root", *root)
+	if err != nil {
+		fail(err)
+	}
+	client = sdk.NewClient(*baseUrl, vendorRoot)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command := args[0]
+	if len(args) > 1 && (command == "device" || command == "app" || command == "kv" || command == "verify") {
+		command += " " + args[1]
+		args = args[1:]
+	}
+	run, ok := commands[command]
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	if err := run(ctx, fs, args[1:]); err != nil {
+		fail(err)
+	}
+}
+
+var commands = map[string]func(ctx context.Context, fs *flag.FlagSet, args []string) error{
+	"device key": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		formats := fs.String("formats", "", "Additional public key formats, comma separated: compressed, address, xonly, did or all")
+		fs.Parse(args)
+		resp, key, err := client.DeviceKey(ctx, *formats)
+		if err != nil {
+			return err
+		}
+		return printResult(struct {
+			*web.DeviceKey
+			ChainVerified bool `json:"chainVerified"`
+			ChainLength   int  `json:"chainLength"`
+		}{resp, true, len(key.Certs)})
+	},
+	"device challenge": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		fs.Parse(args)
+		resp, _, err := client.Challenge(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(resp)
+	},
+	"device version": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		key := fs.String("key", "", "Requester private key in hex, random if empty")
+		fs.Parse(args)
+		requesterKey, err := decodeHex("key", *key)
+		if err != nil {
+			return err
+		}
+		if len(requesterKey) == 0 {
+			generated, err := secp256k1.GeneratePrivateKey()
+			if err != nil {
+				return err
+			}
+			requesterKey = generated.Serialize()
+		}
+		resp, check, err := client.VersionAttestation(ctx, requesterKey)
+		if check != nil {
+			// The diagnostics are printed for invalid attestations as well
+			if printErr := printResult(struct {
+				Attestation  *web.Attestation               `json:"attestation"`
+				Verification *web.VerifyAttestationResponse `json:"verification"`
+			}{resp, check}); printErr != nil {
+				return printErr
+			}
+		}
+		return err
+	},
+	"app key": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		formats := fs.String("formats", "", "Additional public key formats, comma separated: compressed, address, xonly, did or all")
+		fs.Parse(args)
+		resp, key, err := client.AppKey(ctx, *formats)
+		if err != nil {
+			return err
+		}
+		return printResult(struct {
+			*web.ApplicationKey
+			ChainVerified bool `json:"chainVerified"`
+			ChainLength   int  `json:"chainLength"`
+		}{resp, true, len(key.Certs)})
+	},
+	"sign": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		data := fs.String("data", "", "Data to sign in hex")
+		text := fs.String("text", "", "Text to sign, instead of data")
+		fs.Parse(args)
+		payload, err := decodeHex("data", *data)
+		if err != nil {
+			return err
+		}
+		if *text != "" {
+			payload = []byte(*text)
+		}
+		if len(payload) == 0 {
+			return errors.New("missing -data or -text")
+		}
+		resp, err := client.Sign(ctx, payload)
+		if err != nil {
+			return err
+		}
+		return printResult(resp)
+	},
+	"kv write": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		req := web.WriteKvRequest{}
+		fs.StringVar(&req.Key, "key", "", "Key")
+		fs.StringVar(&req.Value, "value", "", "Value")
+		fs.StringVar(&req.Protected, "protected", "", "Protector information, only the protector can read the key")
+		fs.BoolVar(&req.Overwrite, "overwrite", false, "Overwrite the existing key")
+		provisioner := fs.String("provisioner", "", "Provisioner private key in hex, signs the provision of the key for the verified app key")
+		fs.Parse(args)
+		provisionerKey, err := decodeHex("provisioner", *provisioner)
+		if err != nil {
+			return err
+		}
+		var resp *web.WriteKvResponse
+		if len(provisionerKey) > 0 {
+			resp, err = client.WriteProvisionedKv(ctx, provisionerKey, req)
+		} else {
+			resp, err = client.WriteKv(ctx, req)
+		}
+		if err != nil {
+			return err
+		}
+		return printResult(resp)
+	},
+	"kv read": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		key := fs.String("key", "", "Key")
+		fs.Parse(args)
+		resp, err := client.ReadKv(ctx, *key)
+		if err != nil {
+			return err
+		}
+		return printResult(resp)
+	},
+	"kv delete": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		key := fs.String("key", "", "Key")
+		fs.Parse(args)
+		resp, err := client.DeleteKv(ctx, *key)
+		if err != nil {
+			return err
+		}
+		return printResult(resp)
+	},
+	"kv quota": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		fs.Parse(args)
+		resp, err := client.Quota(ctx)
+		if err != nil {
+			return err
+		}
+		return printResult(resp)
+	},
+	"verify signature": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		req := web.VerifySignatureRequest{}
+		fs.StringVar(&req.Data, "data", "", "Signed data in hex")
+		fs.StringVar(&req.Signature, "signature", "", "65 bytes signature in hex")
+		fs.StringVar(&req.PubKey, "pubkey", "", "Expected 64 bytes public key in hex")
+		fs.StringVar(&req.Address, "address", "", "Expected ethereum address")
+		fs.Parse(args)
+		resp, err := client.VerifySignature(ctx, req)
+		if err != nil {
+			return err
+		}
+		return printValid(resp.Valid, resp)
+	},
+	"verify chain": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		req := web.VerifyChainRequest{}
+		fs.StringVar(&req.Chain, "chain", "", "Concatenated certs in hex")
+		fs.StringVar(&req.Root, "root", "", "Vendor root public key in hex, default is the device's vendor root")
+		fs.Parse(args)
+		resp, err := client.VerifyChain(ctx, req)
+		if err != nil {
+			return err
+		}
+		return printValid(resp.Valid, resp)
+	},
+	"verify attestation": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		req := web.VerifyAttestationRequest{}
+		fs.StringVar(&req.Attestation, "attestation", "", "The attestation query sent to /api/v1/device/version in hex")
+		response := fs.String("response", "", "File of the attestation returned by /api/v1/device/version")
+		fs.StringVar(&req.Root, "root", "", "Vendor root public key in hex, default is the device's vendor root")
+		fs.Parse(args)
+		if err := readJson(*response, &req.Response); err != nil {
+			return err
+		}
+		resp, err := client.VerifyAttestation(ctx, req)
+		if err != nil {
+			return err
+		}
+		return printValid(resp.Valid, resp)
+	},
+	"verify enrollment": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		req := web.VerifyEnrollmentRequest{}
+		enrollment := fs.String("enrollment", "", "File of the enrollment returned by /api/v1/device/sign")
+		fs.StringVar(&req.Chain, "chain", "", "Device cert chain in hex")
+		fs.StringVar(&req.Root, "root", "", "Vendor root public key in hex, default is the device's vendor root")
+		fs.Parse(args)
+		if err := readJson(*enrollment, &req.Enrollment); err != nil {
+			return err
+		}
+		resp, err := client.VerifyEnrollment(ctx, req)
+		if err != nil {
+			return err
+		}
+		return printValid(resp.Valid, resp)
+	},
+	"verify vrf": func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+		req := web.VerifyVrfRequest{}
+		fs.StringVar(&req.PubKey, "pubkey", "", "64 bytes public key in hex")
+		fs.StringVar(&req.Alpha, "alpha", "", "VRF input in hex")
+		fs.StringVar(&req.Proof, "proof", "", "81 bytes proof in hex")
+		fs.Parse(args)
+		resp, err := client.VerifyVrf(ctx, req)
+		if err != nil {
+			return err
+		}
+		return printValid(resp.Valid, resp)
+	},
+}
+
+func envOr(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func decodeHex(name string, s string) ([]byte, error) {
+	decoded, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex in -%s: %w", name, err)
+	}
+	return decoded, nil
+}
+
+// readJson decodes the file, or stdin if the name is -
+func readJson(name string, v interface{}) error {
+	if name == "" {
+		return errors.New("missing input file")
+	}
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	return json.NewDecoder(r).Decode(v)
+}
+
+func fail(err error) {
+	if errors.Is(err, constants.ErrorMissingVendorRoot) {
+		err = fmt.Errorf("%w, set -root or TEERMINAL_VENDOR_ROOT", err)
+	}
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
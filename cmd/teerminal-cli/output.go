@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var errorInvalid = errors.New("verification failed")
+
+// printResult writes v as indented JSON, or as one "name: value" line per field in text output
+func printResult(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output == "json" {
+		_, err = fmt.Fprintln(os.Stdout, string(data))
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return printText(os.Stdout, decoder, "", "")
+}
+
+// printValid prints the diagnostics, and fails if they are not valid
+func printValid(valid bool, v interface{}) error {
+	if err := printResult(v); err != nil {
+		return err
+	}
+	if !valid {
+		return errorInvalid
+	}
+	return nil
+}
+
+// printText walks the JSON tokens, so the fields are printed in the order of the structs, nested values are indented
+func printText(w io.Writer, decoder *json.Decoder, indent string, name string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		if token == nil {
+			token = "null"
+		}
+		if name == "" {
+			_, err = fmt.Fprintf(w, "%s%v\n", indent, token)
+		} else {
+			_, err = fmt.Fprintf(w, "%s%s: %v\n", indent, name, token)
+		}
+		return err
+	}
+	if name != "" {
+		fmt.Fprintf(w, "%s%s:\n", indent, name)
+		indent += "  "
+	}
+	for i := 0; decoder.More(); i++ {
+		child := fmt.Sprintf("[%d]", i)
+		if delim == '{' {
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			child = key.(string)
+		}
+		if err := printText(w, decoder, indent, child); err != nil {
+			return err
+		}
+	}
+	// Closing delimiter
+	_, err = decoder.Token()
+	return err
+}
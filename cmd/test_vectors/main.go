@@ -0,0 +1,551 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/eat"
+	"teerminal/service/encryption"
+	"teerminal/service/enrollment"
+	"teerminal/service/handshake"
+	"teerminal/service/identity"
+	"teerminal/service/measurement"
+	"teerminal/service/nostr"
+	"teerminal/service/ratls"
+	"teerminal/service/sensor"
+	"teerminal/web"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ugorji/go/codec"
+)
+
+// Deterministic test vectors for the SDKs in other languages
+// Every signature is deterministic (RFC 6979), so the same inputs always produce the same file
+
+const vectorsVersion = 1
+
+type Inputs struct {
+	VendorRoot         string `json:"vendorRoot"`
+	RootKey            string `json:"rootKey"`
+	AppName            string `json:"appName"`
+	Version            string `json:"version"`
+	TeePlatformVersion uint32 `json:"teePlatformVersion"`
+	Timestamp          uint64 `json:"timestamp"`
+	Nonce              string `json:"nonce"`
+	RequesterPubKey    string `json:"requesterPubKey"`
+	Data               string `json:"data"`
+}
+
+type KeyDerivationVector struct {
+	Key           string `json:"key"`
+	Derivation    string `json:"derivation"`    // Derivation is the raw derivation path
+	PaddedPath    string `json:"paddedPath"`    // PaddedPath is the derivation path right padded with zeros to 64 bytes
+	Preimage      string `json:"preimage"`      // Preimage is key || "_derive_" || paddedPath
+	DerivedKey    string `json:"derivedKey"`    // DerivedKey is keccak256(preimage)
+	DerivedPubKey string `json:"derivedPubKey"` // DerivedPubKey is the 64 bytes uncompressed public key without 0x04
+}
+
+type CertVector struct {
+	Name        string `json:"name"`
+	ProverKey   string `json:"proverKey"`
+	Derivation  string `json:"derivation"`
+	SigningBody string `json:"signingBody"` // SigningBody is derivation (64 bytes) || provee public key
+	Hash        string `json:"hash"`        // Hash is keccak256(signingBody)
+	Signature   string `json:"signature"`   // Signature is r || s || v with v in 27 or 28
+	Cert        string `json:"cert"`        // Cert is prover || provee || derivation || signature
+}
+
+type ChainVector struct {
+	VendorRootPubKey string `json:"vendorRootPubKey"`
+	DeviceKey        string `json:"deviceKey"`
+	DevicePubKey     string `json:"devicePubKey"`
+	DeviceChain      string `json:"deviceChain"` // DeviceChain is device cert || device root cert
+	AppKey           string `json:"appKey"`
+	AppPubKey        string `json:"appPubKey"`
+	AppChain         string `json:"appChain"` // AppChain is device chain || application cert
+}
+
+type SignatureVector struct {
+	Name      string `json:"name"`
+	Key       string `json:"key"`
+	Data      string `json:"data"`
+	Hash      string `json:"hash"` // Hash is keccak256(data)
+	Signature string `json:"signature"`
+}
+
+type KeyFormatVector struct {
+	PubKey     string `json:"pubKey"`
+	Compressed string `json:"compressed"`
+	XOnly      string `json:"xOnly"`
+	Address    string `json:"address"`
+	DidKey     string `json:"didKey"`
+}
+
+type SignableVector struct {
+	Name      string            `json:"name"`
+	Fields    map[string]string `json:"fields"`   // Fields are the inputs and intermediate values of the construction
+	Signable  string            `json:"signable"` // Signable is the exact payload passed to the signer
+	Hash      string            `json:"hash"`     // Hash is keccak256(signable)
+	Signer    string            `json:"signer"`   // Signer is the public key of the signing key
+	Signature string            `json:"signature"`
+}
+
+type VrfVector struct {
+	PubKey string `json:"pubKey"`
+	Alpha  string `json:"alpha"`
+	Proof  string `json:"proof"`
+	Output string `json:"output"`
+}
+
+type NostrVector struct {
+	Key        string      `json:"key"`
+	Serialized string      `json:"serialized"` // Serialized is the NIP-01 serialization, whose sha256 is the event id
+	Event      nostr.Event `json:"event"`
+}
+
+type EatVector struct {
+	Encoding     string `json:"encoding"`
+	Header       string `json:"header"`       // Header is the JOSE header JSON (jwt) or the CBOR protected header (cwt)
+	Payload      string `json:"payload"`      // Payload is the claims JSON (jwt) or the CBOR claims map (cwt)
+	SigningInput string `json:"signingInput"` // SigningInput is the ASCII base64url(header) "." base64url(payload) (jwt) or the CBOR Sig_structure ["Signature1", header, h'', payload] (cwt)
+	Hash         string `json:"hash"`         // Hash is sha256(signingInput), signed with ES256K
+	Signer       string `json:"signer"`       // Signer is the device public key
+	Signature    string `json:"signature"`    // Signature is the 64 bytes r || s signature
+	Token        string `json:"token"`        // Token is the compact JWT, or the tagged COSE_Sign1 CWT
+}
+
+type Vectors struct {
+	Version        int                   `json:"version"`
+	Inputs         Inputs                `json:"inputs"`
+	KeyDerivations []KeyDerivationVector `json:"keyDerivations"`
+	Certs          []CertVector          `json:"certs"`
+	Chains         ChainVector           `json:"chains"`
+	Signatures     []SignatureVector     `json:"signatures"`
+	KeyFormats     []KeyFormatVector     `json:"keyFormats"`
+	Signables      []SignableVector      `json:"signables"`
+	Vrf            VrfVector             `json:"vrf"`
+	Nostr          NostrVector           `json:"nostr"`
+	Eat            []EatVector           `json:"eat"`
+	Enrollments    []enrollment.Vector   `json:"enrollments"` // Enrollments are verification cases, run by backends with enrollment.Vector.Check
+	Values         map[string]string     `json:"values"`      // Values are unsigned constructions, e.g. quote report data and ueid
+}
+
+func h(b []byte) string {
+	return hex.EncodeToString(b)
+}
+
+func mustHex(name string, s string) []byte {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	return b
+}
+
+func keyDerivation(key []byte, derivation []byte) KeyDerivationVector {
+	padded := make([]byte, 64)
+	copy(padded, derivation)
+	preimage := append(append(append([]byte{}, key...), constants.DerivationPrefix...), padded...)
+	derived := encryption.DerivePrivateKey(append([]byte{}, key...), derivation)
+	return KeyDerivationVector{
+		Key:           h(key),
+		Derivation:    h(derivation),
+		PaddedPath:    h(padded),
+		Preimage:      h(preimage),
+		DerivedKey:    h(derived),
+		DerivedPubKey: h(encryption.GetPublicKey(derived)),
+	}
+}
+
+func certVector(name string, proverKey []byte, derivation string, raw []byte) CertVector {
+	cert, _ := encryption.UnpackCert(raw)
+	body := cert.SigningBody()
+	return CertVector{
+		Name:        name,
+		ProverKey:   h(proverKey),
+		Derivation:  derivation,
+		SigningBody: h(body),
+		Hash:        h(crypto.Keccak256(body)),
+		Signature:   h(cert.Signature),
+		Cert:        h(raw),
+	}
+}
+
+func signable(name string, key []byte, fields map[string]string, payload []byte) SignableVector {
+	signature, _ := encryption.Sign(key, payload)
+	return SignableVector{
+		Name:      name,
+		Fields:    fields,
+		Signable:  h(payload),
+		Hash:      h(crypto.Keccak256(payload)),
+		Signer:    h(encryption.GetPublicKey(key)),
+		Signature: h(signature),
+	}
+}
+
+// eatVector splits the token into its signing input and signature, and exits with 1 if the signature does not verify
+func eatVector(encoding string, token []byte, signer []byte) EatVector {
+	v := EatVector{Encoding: encoding, Signer: h(signer), Token: string(token)}
+	var header, payload, signingInput, signature []byte
+	switch encoding {
+	case eat.EncodingJwt:
+		parts := strings.Split(string(token), ".")
+		header, _ = base64.RawURLEncoding.DecodeString(parts[0])
+		payload, _ = base64.RawURLEncoding.DecodeString(parts[1])
+		signingInput = []byte(parts[0] + "." + parts[1])
+		signature, _ = base64.RawURLEncoding.DecodeString(parts[2])
+	case eat.EncodingCwt:
+		handle := &codec.CborHandle{BasicHandle: codec.BasicHandle{EncodeOptions: codec.EncodeOptions{Canonical: true}}}
+		var sign1 codec.RawExt
+		codec.NewDecoderBytes(token, handle).Decode(&sign1)
+		fields, _ := sign1.Value.([]interface{})
+		if len(fields) == 4 {
+			header, _ = fields[0].([]byte)
+			payload, _ = fields[2].([]byte)
+			signature, _ = fields[3].([]byte)
+		}
+		codec.NewEncoderBytes(&signingInput, handle).Encode([]interface{}{"Signature1", header, []byte{}, payload})
+		v.Token = h(token)
+	}
+	if !eat.VerifyEs256k(signer, signingInput, signature) {
+		fmt.Fprintf(os.Stderr, "%s signature does not verify over the signing input\n", encoding)
+		os.Exit(1)
+	}
+	hash := sha256.Sum256(signingInput)
+	v.Header, v.Payload, v.SigningInput, v.Hash, v.Signature = h(header), h(payload), h(signingInput), h(hash[:]), h(signature)
+	return v
+}
+
+func keyFormats(pubKey []byte) KeyFormatVector {
+	compressed, _ := encryption.CompressPublicKey(pubKey)
+	xOnly, _ := encryption.XOnlyPublicKey(pubKey)
+	address, _ := encryption.PublicKeyToAddress(pubKey)
+	didKey, _ := encryption.PublicKeyToDidKey(pubKey)
+	return KeyFormatVector{PubKey: h(pubKey), Compressed: h(compressed), XOnly: h(xOnly), Address: address, DidKey: didKey}
+}
+
+// checkEnrollments runs the enrollment verification cases of a vectors file, and exits with 1 if any of them fails
+func checkEnrollments(name string) {
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	var v Vectors
+	if err := json.Unmarshal(raw, &v); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to decode %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	failed := 0
+	for _, e := range v.Enrollments {
+		if err := e.Check(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed++
+		}
+	}
+	fmt.Printf("%d of %d enrollment cases passed\n", len(v.Enrollments)-failed, len(v.Enrollments))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func main() {
+	vendorRoot := flag.String("vendorRoot", "dbbe0cd0b4c7bc4ab34829c96f35bb0011d06dc3bdf0b900401a71a8f7c4c471", "Vendor root private key in hex")
+	rootKey := flag.String("rootKey", "cd2f10b3d7d306a27199ccf51868c1b0859f824b6fab53710f06a092ae40226f", "Device root key in hex")
+	appName := flag.String("appName", "EmulatorDefault", "Application name")
+	version := flag.String("version", "0.0.1-emulator", "Attestation version")
+	teePlatformVersion := flag.Uint("teePlatformVersion", 1, "Tee platform version")
+	timestamp := flag.Uint64("timestamp", 1700000000, "Attestation timestamp in unix seconds")
+	out := flag.String("o", "", "Output file, default is stdout")
+	check := flag.String("check", "", "Check the enrollment cases of an existing vectors file instead of generating one")
+	enrollments := flag.String("enrollments", "", "Also write the enrollment cases to this file, e.g. service/enrollment/testdata/vectors.json")
+	flag.Parse()
+	if *check != "" {
+		checkEnrollments(*check)
+		return
+	}
+
+	// Everything below derives from the fixed config, not from a config file
+	config.Set(&config.Config{
+		Version:            *version,
+		TeePlatformVersion: uint32(*teePlatformVersion),
+		VendorRoot:         *vendorRoot,
+		RootKey:            *rootKey,
+		AppName:            *appName,
+	})
+	vendorRootKey := mustHex("vendorRoot", *vendorRoot)
+	deviceRootKey := mustHex("rootKey", *rootKey)
+	id := identity.Get()
+
+	// Fixed requester inputs
+	nonce := make([]byte, 64)
+	for i := range nonce {
+		nonce[i] = byte(i + 1)
+	}
+	requesterKey := encryption.DerivePrivateKey(crypto.Keccak256([]byte("requester")), []byte("requester"))
+	requesterPubKey := encryption.GetPublicKey(requesterKey)
+	data := []byte("teerminal test vector")
+
+	v := Vectors{
+		Version: vectorsVersion,
+		Inputs: Inputs{
+			VendorRoot:         h(vendorRootKey),
+			RootKey:            h(deviceRootKey),
+			AppName:            *appName,
+			Version:            *version,
+			TeePlatformVersion: uint32(*teePlatformVersion),
+			Timestamp:          *timestamp,
+			Nonce:              h(nonce),
+			RequesterPubKey:    h(requesterPubKey),
+			Data:               h(data),
+		},
+		Values: map[string]string{},
+	}
+
+	// Key derivation, device root key and app key
+	v.KeyDerivations = []KeyDerivationVector{
+		keyDerivation(deviceRootKey, []byte(constants.DeviceRootKey)),
+		keyDerivation(id.DeviceKey, []byte(*appName)),
+		keyDerivation(id.DeviceKey, []byte{}),
+	}
+
+	// Certs: vendor root -> root key (zero derivation), root key -> device root key, device root key -> app key
+	chain := id.AppChain
+	v.Certs = []CertVector{
+		certVector("device", vendorRootKey, h(make([]byte, 64)), chain[:encryption.CertLength]),
+		certVector("deviceRoot", deviceRootKey, h([]byte(constants.DeviceRootKey)), chain[encryption.CertLength:2*encryption.CertLength]),
+		certVector("application", id.DeviceKey, h([]byte(*appName)), chain[2*encryption.CertLength:]),
+	}
+	v.Chains = ChainVector{
+		VendorRootPubKey: h(id.VendorRootPubKey),
+		DeviceKey:        h(id.DeviceKey),
+		DevicePubKey:     h(id.DevicePubKey),
+		DeviceChain:      h(id.DeviceChain),
+		AppKey:           h(id.AppKey),
+		AppPubKey:        h(id.AppPubKey),
+		AppChain:         h(id.AppChain),
+	}
+
+	// Plain signatures over keccak256(data)
+	for _, s := range []struct {
+		name string
+		key  []byte
+		data []byte
+	}{
+		{"deviceSign", id.DeviceKey, data},
+		{"appSign", id.AppKey, data},
+		{"requesterSign", requesterKey, append(append([]byte{}, nonce...), requesterPubKey...)},
+	} {
+		signature, _ := encryption.Sign(s.key, s.data)
+		v.Signatures = append(v.Signatures, SignatureVector{
+			Name:      s.name,
+			Key:       h(s.key),
+			Data:      h(s.data),
+			Hash:      h(crypto.Keccak256(s.data)),
+			Signature: h(signature),
+		})
+	}
+	v.KeyFormats = []KeyFormatVector{keyFormats(id.DevicePubKey), keyFormats(id.AppPubKey)}
+
+	// Enrollment: "DEPHY_ID_SIGNED_MESSAGE:" || keccak256(data)
+	v.Signables = append(v.Signables, signable("enrollment", id.DeviceKey, map[string]string{
+		"data":     h(data),
+		"dataHash": h(crypto.Keccak256(data)),
+	}, enrollment.Signable(data)))
+
+	// Structured enrollment, canonical and EIP-712, with a fixed deadline
+	owner, _ := encryption.PublicKeyToAddress(requesterPubKey)
+	fields := enrollment.Fields{
+		ChainId:  8453,
+		Registry: common.HexToAddress("0x00000000000000000000000000000000000000aa"),
+		Owner:    common.HexToAddress(owner),
+		Deadline: *timestamp + 3600,
+	}
+	structuredFields := map[string]string{
+		"chainId":  fmt.Sprintf("%d", fields.ChainId),
+		"registry": fields.Registry.Hex(),
+		"owner":    fields.Owner.Hex(),
+		"deadline": fmt.Sprintf("%d", fields.Deadline),
+		"payload":  h(fields.Canonical()),
+	}
+	canonicalSignable, _ := enrollment.StructuredSignable(enrollment.EncodingCanonical, fields)
+	v.Signables = append(v.Signables, signable("structuredEnrollment", id.DeviceKey, structuredFields, canonicalSignable))
+	eip712Fields := map[string]string{
+		"domainSeparator": h(fields.DomainSeparator()),
+		"structHash":      h(fields.StructHash()),
+	}
+	for key, value := range structuredFields {
+		eip712Fields[key] = value
+	}
+	eip712Signable, _ := enrollment.StructuredSignable(enrollment.EncodingEip712, fields)
+	v.Signables = append(v.Signables, signable("eip712Enrollment", id.DeviceKey, eip712Fields, eip712Signable))
+
+	// Enrollment verification cases, accepted and rejected the same way as CertLib and the emulator
+	// The expected outcome of every case is stated here, the cases are checked against Verify by service/enrollment
+	enrollmentSignature, _ := encryption.Sign(id.DeviceKey, enrollment.Signable(data))
+	appSignature, _ := encryption.Sign(id.AppKey, enrollment.Signable(data))
+	lowV := append([]byte{}, enrollmentSignature...)
+	lowV[64] -= 27
+	tamperedChain := append([]byte{}, id.DeviceChain...)
+	tamperedChain[encryption.CertLength+128] ^= 1
+	// A chain signed by the root key like the device chain, but with another derivation than the device root key
+	otherKey := encryption.DerivePrivateKey(deviceRootKey, []byte("other"))
+	otherChain := append(append([]byte{}, id.DeviceChain[:encryption.CertLength]...), encryption.GenerateCert(deviceRootKey, []byte("other"))...)
+	otherSignature, _ := encryption.Sign(otherKey, enrollment.Signable(data))
+	deviceAddress := common.BytesToAddress(crypto.Keccak256(id.DevicePubKey)[12:])
+	v.Enrollments = []enrollment.Vector{
+		enrollment.NewVector("valid", id.DeviceChain, id.VendorRootPubKey, id.DevicePubKey, data, enrollmentSignature, deviceAddress, nil),
+		enrollment.NewVector("validUnclaimedDeviceKey", id.DeviceChain, id.VendorRootPubKey, nil, data, enrollmentSignature, deviceAddress, nil),
+		enrollment.NewVector("validLowV", id.DeviceChain, id.VendorRootPubKey, id.DevicePubKey, data, lowV, deviceAddress, nil),
+		enrollment.NewVector("tamperedPayload", id.DeviceChain, id.VendorRootPubKey, id.DevicePubKey, append([]byte{0}, data...), enrollmentSignature, common.Address{}, constants.ErrorSignatureMismatch),
+		enrollment.NewVector("wrongRoot", id.DeviceChain, requesterPubKey, id.DevicePubKey, data, enrollmentSignature, common.Address{}, constants.ErrorInvalidCertDerivation),
+		enrollment.NewVector("tamperedChain", tamperedChain, id.VendorRootPubKey, id.DevicePubKey, data, enrollmentSignature, common.Address{}, constants.ErrorInvalidCert),
+		enrollment.NewVector("truncatedChain", id.DeviceChain[:len(id.DeviceChain)-1], id.VendorRootPubKey, id.DevicePubKey, data, enrollmentSignature, common.Address{}, constants.ErrorInvalidChainLength),
+		enrollment.NewVector("emptyChain", nil, id.VendorRootPubKey, nil, data, enrollmentSignature, common.Address{}, constants.ErrorInvalidChainLength),
+		enrollment.NewVector("claimedAppKey", id.DeviceChain, id.VendorRootPubKey, id.AppPubKey, data, enrollmentSignature, common.Address{}, constants.ErrorChainLeafMismatch),
+		enrollment.NewVector("appChain", id.AppChain, id.VendorRootPubKey, id.DevicePubKey, data, enrollmentSignature, common.Address{}, constants.ErrorInvalidChainLength),
+		enrollment.NewVector("appChainUnclaimedDeviceKey", id.AppChain, id.VendorRootPubKey, nil, data, enrollmentSignature, common.Address{}, constants.ErrorInvalidChainLength),
+		enrollment.NewVector("appKeySigned", id.AppChain, id.VendorRootPubKey, nil, data, appSignature, common.Address{}, constants.ErrorInvalidChainLength),
+		enrollment.NewVector("appKeySignedDeviceChain", id.DeviceChain, id.VendorRootPubKey, nil, data, appSignature, common.Address{}, constants.ErrorSignatureMismatch),
+		enrollment.NewVector("otherDerivation", otherChain, id.VendorRootPubKey, nil, data, otherSignature, common.Address{}, constants.ErrorNotDeviceChain),
+		enrollment.NewVector("truncatedSignature", id.DeviceChain, id.VendorRootPubKey, id.DevicePubKey, data, enrollmentSignature[:64], common.Address{}, constants.ErrorInvalidSignatureLength),
+	}
+
+	// Version attestation over a fixed event log
+	eventLog := []measurement.Event{
+		{Register: 0, Data: h([]byte("bootloader")), Description: "bootloader"},
+		{Register: 1, Data: h([]byte("kernel")), Description: "kernel"},
+		{Register: 0, Data: "00", Description: "config"},
+	}
+	registers, _ := measurement.Replay(eventLog)
+	eventLogJson, _ := json.Marshal(eventLog)
+	var registersHex []string
+	for _, register := range registers {
+		registersHex = append(registersHex, h(register))
+	}
+	v.Signables = append(v.Signables, signable("versionAttestation", id.DeviceKey, map[string]string{
+		"nonce":              h(nonce),
+		"pubKey":             h(requesterPubKey),
+		"teePlatformVersion": fmt.Sprintf("%d", *teePlatformVersion),
+		"version":            *version,
+		"timestamp":          fmt.Sprintf("%d", *timestamp),
+		"eventLog":           string(eventLogJson),
+		"registers":          strings.Join(registersHex, ","),
+		"registersDigest":    h(measurement.Digest(registers)),
+		"emptyRegisters":     h(measurement.Digest(make([][]byte, 0))),
+	}, web.VersionSignable(nonce, requesterPubKey, uint32(*teePlatformVersion), *version, *timestamp, registers)))
+
+	// Counter: "TEERMINAL_COUNTER:" || keccak256(name) || value [|| nonce]
+	v.Signables = append(v.Signables, signable("counter", id.AppKey, map[string]string{
+		"name":     "vector",
+		"nameHash": h(crypto.Keccak256([]byte("vector"))),
+		"value":    "42",
+	}, web.CounterSignable("vector", 42, nil)))
+	v.Signables = append(v.Signables, signable("counterNonce", id.AppKey, map[string]string{
+		"name":     "vector",
+		"nameHash": h(crypto.Keccak256([]byte("vector"))),
+		"value":    "42",
+		"nonce":    h(nonce),
+	}, web.CounterSignable("vector", 42, nonce)))
+
+	// Sensor reading: "TEERMINAL_READING:" || sequence || timestamp || keccak256(sensor) || keccak256(values)
+	readingValues := map[string]float64{"power": 1000.5, "energy": 0.25}
+	v.Signables = append(v.Signables, signable("sensorReading", id.AppKey, map[string]string{
+		"sequence":   "7",
+		"timestamp":  fmt.Sprintf("%d", *timestamp*1000),
+		"sensor":     "meter",
+		"sensorHash": h(crypto.Keccak256([]byte("meter"))),
+		"values":     `{"energy":0.25,"power":1000.5}`,
+	}, sensor.Signable(7, *timestamp*1000, "meter", readingValues)))
+
+	// Session between this device and a peer device, with ephemeral keys derived from fixed seeds
+	peerDeviceKey := encryption.DerivePrivateKey(crypto.Keccak256([]byte("peer")), []byte(constants.DeviceRootKey))
+	initiatorEphemeral := encryption.DerivePrivateKey(crypto.Keccak256([]byte("ephemeral")), []byte("initiator"))
+	responderEphemeral := encryption.DerivePrivateKey(crypto.Keccak256([]byte("ephemeral")), []byte("responder"))
+	responderNonce := crypto.Keccak256(nonce)
+	transcript := handshake.Transcript(nonce, responderNonce, encryption.GetPublicKey(initiatorEphemeral), encryption.GetPublicKey(responderEphemeral), id.DevicePubKey, encryption.GetPublicKey(peerDeviceKey))
+	sessionKey, _ := handshake.DeriveKey(initiatorEphemeral, encryption.GetPublicKey(responderEphemeral), transcript)
+	v.Signables = append(v.Signables, signable("session", id.DeviceKey, map[string]string{
+		"initiatorNonce":     h(nonce),
+		"responderNonce":     h(responderNonce),
+		"initiatorEphemeral": h(initiatorEphemeral),
+		"responderEphemeral": h(responderEphemeral),
+		"peerDeviceKey":      h(peerDeviceKey),
+		"transcript":         h(transcript),
+		"sessionKey":         h(sessionKey),
+	}, handshake.Signable(transcript, sessionKey)))
+
+	// RA-TLS: "TEERMINAL_RATLS:" || sha256(SubjectPublicKeyInfo), over a fixed SubjectPublicKeyInfo
+	publicKeyInfo := crypto.Keccak256([]byte("subject public key info"))
+	spkiHash := sha256.Sum256(publicKeyInfo)
+	v.Signables = append(v.Signables, signable("raTls", id.AppKey, map[string]string{
+		"publicKeyInfo":     h(publicKeyInfo),
+		"publicKeyInfoHash": h(spkiHash[:]),
+	}, ratls.Signable(publicKeyInfo)))
+
+	// VRF with the app key
+	output, proof, _ := encryption.VrfProve(id.AppKey, data)
+	v.Vrf = VrfVector{PubKey: h(id.AppPubKey), Alpha: h(data), Proof: h(proof), Output: h(output)}
+
+	// Nostr event with the app key, the content covers every escaped character of NIP-01
+	event, _ := nostr.Sign(id.AppKey, nostr.Event{
+		CreatedAt: int64(*timestamp),
+		Kind:      1,
+		Tags:      [][]string{{"t", "teerminal"}, {constants.NostrChainTag, h(id.AppChain)}},
+		Content:   "line\nquote\" backslash\\ cr\r tab\t bs\b ff\f <html> & unicode \u00e9",
+	})
+	v.Nostr = NostrVector{Key: h(id.AppKey), Serialized: string(event.Serialize()), Event: event}
+
+	reportData := web.QuoteReportData(nonce, id.AppPubKey)
+	v.Values["quoteReportData"] = h(reportData[:])
+	v.Values["ueid"] = h(eat.Ueid(id.DevicePubKey))
+
+	// EAT signed by the device root key with ES256K (RFC 6979), as a JWT and as a CWT
+	claims := eat.Claims{
+		Nonce:              nonce,
+		Ueid:               eat.Ueid(id.DevicePubKey),
+		HardwareModel:      eat.HardwareModel,
+		SoftwareName:       *appName,
+		SoftwareVersion:    *version,
+		TeePlatformVersion: uint32(*teePlatformVersion),
+		IssuedAt:           int64(*timestamp),
+		RegistersDigest:    measurement.Digest(registers),
+		EventLog:           eventLog,
+	}
+	for _, encoding := range []string{eat.EncodingJwt, eat.EncodingCwt} {
+		token, err := eat.Build(encoding, claims, id.DeviceKey, id.DevicePubKey, id.DeviceChain)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to build the %s eat: %v\n", encoding, err)
+			os.Exit(1)
+		}
+		v.Eat = append(v.Eat, eatVector(encoding, token, id.DevicePubKey))
+	}
+
+	if *enrollments != "" {
+		encoded, _ := json.MarshalIndent(v.Enrollments, "", "  ")
+		if err := os.WriteFile(*enrollments, append(encoded, '\n'), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *enrollments, err)
+			os.Exit(1)
+		}
+	}
+
+	encoded, _ := json.MarshalIndent(v, "", "  ")
+	encoded = append(encoded, '\n')
+	if *out == "" {
+		os.Stdout.Write(encoded)
+		return
+	}
+	if err := os.WriteFile(*out, encoded, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		os.Exit(1)
+	}
+}
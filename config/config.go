@@ -3,56 +3,200 @@ package config
 import (
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"teerminal/constants"
+	"time"
 )
 
 type Config struct {
-	Port               string `json:"port" mapstructure:"port"`
-	Version            string `json:"version" mapstructure:"version"`
-	TeePlatformVersion uint32 `json:"teePlatformVersion" mapstructure:"teePlatformVersion"`
-	VendorRoot         string `json:"vendorRoot" mapstructure:"vendorRoot"` // VendorRoot is the key for signing device identity
-	RootKey            string `json:"rootKey" mapstructure:"rootKey"`
-	AppName            string `json:"appName" mapstructure:"appName"` // AppName is the name of the application
+	Port               string        `json:"port" mapstructure:"port"`
+	Version            string        `json:"version" mapstructure:"version"`
+	TeePlatformVersion uint32        `json:"teePlatformVersion" mapstructure:"teePlatformVersion"`
+	VendorRoot         string        `json:"vendorRoot" mapstructure:"vendorRoot"` // VendorRoot is the key for signing device identity
+	RootKey            string        `json:"rootKey" mapstructure:"rootKey"`
+	AppName            string        `json:"appName" mapstructure:"appName"`                       // AppName is the name of the application
+	DataDir            string        `json:"dataDir" mapstructure:"dataDir"`                       // DataDir is where persistent state is stored, default is "data"
+	BootMeasurements   []Measurement `json:"bootMeasurements" mapstructure:"bootMeasurements"`     // BootMeasurements are extended into the measurement registers at boot
+	ChallengeTtl       int64         `json:"challengeTtl" mapstructure:"challengeTtl"`             // ChallengeTtl is how long an issued nonce stays valid in seconds, default is 60
+	TrustedVendorRoots []string      `json:"trustedVendorRoots" mapstructure:"trustedVendorRoots"` // TrustedVendorRoots are the vendor root public keys accepted from peer devices, default is this device's vendor root
+	Tls                bool          `json:"tls" mapstructure:"tls"`                               // Tls serves the api over RA-TLS with a self-generated attested certificate
+	AdminToken         string        `json:"adminToken" mapstructure:"adminToken"`                 // AdminToken is the bearer token of the admin api, the admin api is disabled if empty
+	Record             string        `json:"record" mapstructure:"record"`                         // Record is the JSONL file every /api/v1 request and response is appended to
+	Replay             string        `json:"replay" mapstructure:"replay"`                         // Replay is the JSONL file the /api/v1 responses are served from instead of the emulated device
+	ReplayByRoute      bool          `json:"replayByRoute" mapstructure:"replayByRoute"`           // ReplayByRoute serves a recorded response of the same method and path when no request matches exactly
+	Relays             []string      `json:"relays" mapstructure:"relays"`                         // Relays are the nostr relay websocket urls status and telemetry are published to, and commands are received from
+	PublishInterval    int64         `json:"publishInterval" mapstructure:"publishInterval"`       // PublishInterval is the seconds between status and telemetry events, default is 60
+	Sensors            []Sensor      `json:"sensors" mapstructure:"sensors"`                       // Sensors are the simulated sensors producing signed readings
+	GrpcPort           string        `json:"grpcPort" mapstructure:"grpcPort"`                     // GrpcPort is the port of the gRPC services, which are disabled if empty
+	RpcOrigins         []string      `json:"rpcOrigins" mapstructure:"rpcOrigins"`                 // RpcOrigins are the browser origins allowed to open /api/v1/ws besides the device's own
 }
 
-var config *Config
+type Measurement struct {
+	Register    int    `json:"register" mapstructure:"register"`
+	Data        string `json:"data" mapstructure:"data"` // Data is hex encoded, or raw text if it is not valid hex
+	Description string `json:"description" mapstructure:"description"`
+}
+
+// Sensor is a simulated sensor, the reading model depends on the type:
+// temperature: celsius = base + drift * t + noise
+// energy: power in W = base + drift * t + noise, energy in Wh is the integral of power
+// gps: the position moves drift meters per second on a random walk from latitude and longitude, noise is in meters
+// counter: count increases by base + drift * t + noise per reading, rounded and never negative
+type Sensor struct {
+	Name      string  `json:"name" mapstructure:"name"`
+	Type      string  `json:"type" mapstructure:"type"`           // Type is energy, gps, temperature or counter
+	Interval  int64   `json:"interval" mapstructure:"interval"`   // Interval is the milliseconds between readings, default is 1000
+	Base      float64 `json:"base" mapstructure:"base"`           // Base is the value at start
+	Noise     float64 `json:"noise" mapstructure:"noise"`         // Noise is the standard deviation of the gaussian noise of every reading
+	Drift     float64 `json:"drift" mapstructure:"drift"`         // Drift is the change of the base per second
+	Latitude  float64 `json:"latitude" mapstructure:"latitude"`   // Latitude is the start position of gps sensors
+	Longitude float64 `json:"longitude" mapstructure:"longitude"` // Longitude is the start position of gps sensors
+	Seed      int64   `json:"seed" mapstructure:"seed"`           // Seed is the noise seed, default is derived from the name
+}
+
+var config atomic.Pointer[Config]
+
+// generation is bumped every time the config is (re)loaded, so derived state can be invalidated
+var generation atomic.Uint64
+
+// watchers are notified after the config is (re)loaded
+var (
+	watchersMu sync.Mutex
+	watchers   = map[chan struct{}]bool{}
+)
 
 func GetConfig() *Config {
-	return config
+	return config.Load()
+}
+
+func Generation() uint64 {
+	return generation.Load()
 }
 
 func GetVendorRoot() []byte {
-	val, _ := hex.DecodeString(strings.TrimPrefix(config.VendorRoot, "0x"))
+	val, _ := hex.DecodeString(strings.TrimPrefix(GetConfig().VendorRoot, "0x"))
 	return val
 }
 
 func GetRootKey() []byte {
-	val, _ := hex.DecodeString(strings.TrimPrefix(config.RootKey, "0x"))
+	val, _ := hex.DecodeString(strings.TrimPrefix(GetConfig().RootKey, "0x"))
 	return val
 }
 
+func GetDataDir() string {
+	return GetConfig().dataDir()
+}
+
+func (c *Config) dataDir() string {
+	if c.DataDir != "" {
+		return c.DataDir
+	}
+	return "data"
+}
+
+func GetChallengeTtl() time.Duration {
+	if ttl := GetConfig().ChallengeTtl; ttl > 0 {
+		return time.Duration(ttl) * time.Second
+	}
+	return constants.DefaultChallengeTtlSeconds * time.Second
+}
+
+func GetPublishInterval() time.Duration {
+	if interval := GetConfig().PublishInterval; interval > 0 {
+		return time.Duration(interval) * time.Second
+	}
+	return constants.DefaultPublishIntervalSeconds * time.Second
+}
+
+// Set replaces the current config without reading a file, e.g. for tools running with fixed keys
+func Set(c *Config) {
+	config.Store(c)
+	generation.Add(1)
+	notify()
+}
+
 func Load(name string) {
+	if err := Reload(name); err != nil {
+		panic(err)
+	}
+}
+
+// Reload replaces the current config with the content of the config file, the current config is kept on error
+func Reload(name string) error {
 	// Open config file
 	if name == "" {
 		name = "config.json"
 	}
-	config = &Config{}
+	loaded := &Config{}
 	// Load config file
 	f, err := os.OpenFile(name, os.O_RDONLY, 0644)
 	if err != nil {
-		panic(err)
+		return err
 	}
+	defer f.Close()
 	// Read config file
 	fAll, err := io.ReadAll(f)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	// Unmarshal config file
-	err = json.Unmarshal(fAll, config)
+	err = json.Unmarshal(fAll, loaded)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if err := loaded.validate(); err != nil {
+		return err
+	}
+	// The counters and the sensor sequences are kept in the data dir, moving it would restart them from zero
+	if current := GetConfig(); current != nil && loaded.dataDir() != current.dataDir() {
+		return constants.ErrorDataDirChanged
+	}
+	config.Store(loaded)
+	generation.Add(1)
+	notify()
+	return nil
+}
+
+// validate refuses the settings which could only be ignored once loaded
+func (c *Config) validate() error {
+	if len(c.BootMeasurements) > constants.MaxMeasurementEvents {
+		return fmt.Errorf("boot measurements: %w", constants.ErrorMeasurementLogFull)
+	}
+	for i, m := range c.BootMeasurements {
+		if m.Register < 0 || m.Register >= constants.MeasurementRegisterCount {
+			return fmt.Errorf("boot measurement %d: %w", i, constants.ErrorInvalidMeasurementRegister)
+		}
+	}
+	return nil
+}
+
+func notify() {
+	watchersMu.Lock()
+	defer watchersMu.Unlock()
+	for ch := range watchers {
+		// A pending notification already covers this change
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Watch returns a channel notified after the config is (re)loaded, several changes may be coalesced in one notification
+func Watch() (chan struct{}, func()) {
+	watchersMu.Lock()
+	defer watchersMu.Unlock()
+	ch := make(chan struct{}, 1)
+	watchers[ch] = true
+	cancel := func() {
+		watchersMu.Lock()
+		defer watchersMu.Unlock()
+		delete(watchers, ch)
 	}
-	// Todo: config file sanity check
+	return ch, cancel
 }
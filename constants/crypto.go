@@ -3,6 +3,81 @@ package constants
 const DerivationPrefix = "_derive_"
 const DeviceRootKey = "device_root_key_"
 const DeviceEnrollmentKey = "DEPHY_ID_SIGNED_MESSAGE:"
+const StructuredEnrollmentKey = "TEERMINAL_STRUCTURED_ENROLLMENT:" // StructuredEnrollmentKey separates canonical structured enrollments from /api/v1/device/sign
+const CounterSignPrefix = "TEERMINAL_COUNTER:"
+const SessionSignPrefix = "TEERMINAL_SESSION:"
+const SessionKeyPrefix = "TEERMINAL_SESSION_KEY:"
+const RaTlsSignPrefix = "TEERMINAL_RATLS:"
+
+// EIP-712 domain of structured enrollments, the verifying contract is the registry
+const EnrollmentDomainName = "Teerminal"
+const EnrollmentDomainVersion = "1"
+
+const DeviceChainCerts = 2 // DeviceChainCerts is the number of certs from the vendor root to the device key
+const AppChainCerts = 3    // AppChainCerts is the number of certs from the vendor root to an app key
+
+const ProvisionLength = 64 + 65 // ProvisionLength is the kv provision: 64 bytes provisioner public key || 65 bytes signature
+
+const AttestationQueryLength = 64 + 64 + 65 // AttestationQueryLength is the version attestation query: 64 bytes nonce || 64 bytes requester public key || 65 bytes signature
 
 const MaxKvLength = 1024 * 3
 const MaxKvEntries = 256 - 8 // 8 reserved for metadata
+
+const MaxNitroFieldLength = 1024 // Max length of nonce, user data and public key in nitro documents
+
+const MeasurementRegisterCount = 8
+const MaxMeasurementLength = 1024
+const MaxMeasurementEvents = 1024 // Max number of events in the measurement log, boot measurements included
+
+const MaxCounterNameLength = 64
+const MaxCounterNonceLength = 64
+const MaxCounters = 256
+
+const ChallengeLength = 64 // Same as the nonce length of version attestation requests
+const MaxChallenges = 1024 // Max number of outstanding challenges
+const DefaultChallengeTtlSeconds = 60
+
+const MaxSessions = 256
+const PeerRequestTimeoutSeconds = 10
+
+const RaTlsCertValidityDays = 365
+
+const MaxFaultEvents = 1024
+const MaxFaultLatencyMs = 60 * 1000
+
+const MaxRecordingLineLength = 16 * 1024 * 1024
+const MaxRequestBodyLength = 1024 * 1024 // MaxRequestBodyLength is the largest request body the middlewares read into memory
+
+const NostrChainTag = "teerminal_chain" // NostrChainTag is the tag carrying the app cert chain of signed nostr events
+const MaxNostrKind = 65535
+const MaxNostrTags = 64
+const MaxNostrContentLength = 64 * 1024
+
+const NostrStatusKind = 30078             // NostrStatusKind is NIP-78 app data, replaced on relays by the latest status of the app key
+const NostrStatusTag = "teerminal_status" // NostrStatusTag is the d tag of status events
+const NostrTelemetryKind = 1573
+const NostrCommandKind = 1574 // NostrCommandKind events addressed to the device carry a p tag of its x-only app public key
+const DefaultPublishIntervalSeconds = 60
+const RelayDialTimeoutSeconds = 10
+const MaxRelayBackoffSeconds = 60
+const MaxNostrCommands = 1024
+
+const SensorReadingSignPrefix = "TEERMINAL_READING:"
+const DefaultSensorIntervalMs = 1000
+const MinSensorIntervalMs = 10
+const MaxSensors = 64
+const MaxSensorReadings = 4096
+const SensorSequenceBlock = 1024                  // SensorSequenceBlock is the number of sequences reserved at once
+const SensorSequenceFile = "sensor_sequence.json" // SensorSequenceFile in the data directory holds the last reserved sequence
+const SensorSubscriberBuffer = 256                // SensorSubscriberBuffer is the readings buffered per stream, slower streams are closed
+
+const KvSubscriberBuffer = 256 // KvSubscriberBuffer is the changes buffered per subscriber, slower subscribers are closed
+
+// JSON-RPC 2.0 error codes, application errors carry the message of the failed operation
+const (
+	RpcErrorParse          = -32700
+	RpcErrorInvalidRequest = -32600
+	RpcErrorUnknownMethod  = -32601
+	RpcErrorInvalidParams  = -32602
+	RpcErrorApplication    = -32000
+)
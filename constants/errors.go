@@ -12,6 +12,24 @@ const (
 	MsgErrorWrongRemoteAttestationSignature  = "wrong remote attestation signature"
 
 	MsgErrorFailedToBindRequest = "failed to bind request"
+	MsgErrorUnknownKeyFormat    = "unknown key format"
+
+	MsgErrorInvalidSignatureLength = "invalid signature length"
+	MsgErrorInvalidPublicKey       = "invalid public key"
+	MsgErrorInvalidAddress         = "invalid address"
+	MsgErrorMissingVerificationKey = "missing public key or address"
+	MsgErrorSignatureMismatch      = "signature does not match public key or address"
+	MsgErrorInvalidCertLength      = "invalid cert length"
+	MsgErrorInvalidChainLength     = "invalid chain length"
+	MsgErrorInvalidCert            = "invalid cert"
+	MsgErrorInvalidCertDerivation  = "invalid cert derivation"
+	MsgErrorChainLeafMismatch      = "chain leaf does not match signer"
+	MsgErrorNotDeviceChain         = "chain is not a device chain"
+	MsgErrorNotAppChain            = "chain is not an app chain"
+
+	MsgErrorInvalidVrfProof  = "invalid vrf proof"
+	MsgErrorVrfEncodeToCurve = "vrf encode to curve failed"
+	MsgErrorFailedVrfProve   = "failed to compute vrf proof"
 
 	MsgErrorKeyOrValueNotFound          = "key or value not found"
 	MsgErrorFailedProvisionDecoding     = "failed to decode provision"
@@ -20,8 +38,172 @@ const (
 	MsgErrorValueTooLarge               = "value too large"
 	MsgErrorKeyExists                   = "key exists"
 	MsgErrorKeyDoesNotExist             = "key does not exist"
+
+	MsgErrorInvalidCounterName   = "invalid counter name"
+	MsgErrorCounterExists        = "counter exists"
+	MsgErrorCounterDoesNotExist  = "counter does not exist"
+	MsgErrorCounterQuotaReached  = "counter quota reached"
+	MsgErrorCounterOverflow      = "counter overflow"
+	MsgErrorFailedPersistCounter = "failed to persist counter"
+	MsgErrorInvalidCounterNonce  = "invalid counter nonce"
+	MsgErrorDataDirChanged       = "data dir can not change on reload"
+
+	MsgErrorUnknownAttestationFormat = "unknown attestation format"
+	MsgErrorMissingNonce             = "missing nonce"
+	MsgErrorFailedDecodeNonce        = "failed to decode nonce"
+	MsgErrorUnsupportedQuoteVersion  = "unsupported quote version"
+	MsgErrorFailedGenerateQuote      = "failed to generate quote"
+	MsgErrorFailedDecodeUserData     = "failed to decode user data"
+	MsgErrorFailedDecodePublicKey    = "failed to decode public key"
+	MsgErrorAttestationFieldTooLarge = "attestation field too large"
+	MsgErrorFailedGenerateDocument   = "failed to generate attestation document"
+
+	MsgErrorInvalidMeasurementRegister = "invalid measurement register"
+	MsgErrorInvalidMeasurementEvent    = "invalid measurement event"
+	MsgErrorMeasurementTooLarge        = "measurement too large"
+	MsgErrorMeasurementLogFull         = "measurement log full"
+	MsgErrorEventLogMismatch           = "event log replay does not match registers"
+
+	MsgErrorFailedIssueChallenge = "failed to issue challenge"
+	MsgErrorTooManyChallenges    = "too many outstanding challenges"
+	MsgErrorUnknownNonce         = "nonce was not issued by this device"
+	MsgErrorNonceExpired         = "nonce expired"
+	MsgErrorNonceReused          = "nonce already used"
+
+	MsgErrorInvalidPeer                = "invalid peer url"
+	MsgErrorPeerRequestFailed          = "peer request failed"
+	MsgErrorPeerAttestationInvalid     = "peer attestation invalid"
+	MsgErrorUntrustedVendorRoot        = "vendor root is not trusted"
+	MsgErrorInvalidEphemeralKey        = "invalid ephemeral key"
+	MsgErrorFailedGenerateEphemeralKey = "failed to generate ephemeral key"
+	MsgErrorSessionDoesNotExist        = "session does not exist"
+	MsgErrorSessionSignatureMismatch   = "session signature mismatch"
+	MsgErrorSessionAlreadyEstablished  = "session already established"
+
+	MsgErrorInvalidRaTlsCert       = "invalid ra-tls certificate"
+	MsgErrorRaTlsCertExpired       = "ra-tls certificate expired"
+	MsgErrorMissingRaTlsEvidence   = "missing ra-tls evidence"
+	MsgErrorInvalidRaTlsEvidence   = "invalid ra-tls evidence"
+	MsgErrorRaTlsSignatureMismatch = "ra-tls signature mismatch"
+	MsgErrorRaTlsAppKeyMismatch    = "ra-tls app key mismatch"
+
+	MsgErrorUnknownEatEncoding = "unknown eat encoding"
+	MsgErrorFailedGenerateEat  = "failed to generate eat"
+
+	MsgErrorAdminDisabled       = "admin api is disabled"
+	MsgErrorUnauthorized        = "unauthorized"
+	MsgErrorInvalidFaultProfile = "invalid fault profile"
+	MsgErrorInvalidFaultEventId = "invalid fault event id"
+	MsgErrorInjectedFault       = "injected fault"
+
+	MsgErrorInvalidRecording   = "invalid recording"
+	MsgErrorNoRecordedResponse = "no recorded response for request"
+	MsgErrorRequestTooLarge    = "request too large"
+
+	MsgErrorInvalidHexResponse = "invalid hex in response"
+	MsgErrorAttestationInvalid = "attestation invalid"
+	MsgErrorMissingVendorRoot  = "missing vendor root"
+	MsgErrorMissingAdminToken  = "missing admin token"
+
+	MsgErrorUnknownEnrollmentEncoding = "unknown enrollment encoding"
+	MsgErrorEnrollmentDeadlineExpired = "enrollment deadline expired"
+	MsgErrorInvalidChainId            = "invalid chain id"
+
+	MsgErrorInvalidNostrKind     = "invalid nostr kind"
+	MsgErrorInvalidNostrTags     = "invalid nostr tags"
+	MsgErrorNostrContentTooLong  = "nostr content too long"
+	MsgErrorInvalidNostrContent  = "invalid nostr content"
+	MsgErrorNostrEventIdMismatch = "nostr event id mismatch"
+	MsgErrorInvalidNostrPubKey   = "invalid nostr pubkey"
+	MsgErrorInvalidCommandId     = "invalid command id"
+
+	MsgErrorInvalidSensor      = "invalid sensor"
+	MsgErrorUnknownSensorType  = "unknown sensor type"
+	MsgErrorInvalidSequence    = "invalid sequence"
+	MsgErrorSequenceOutOfOrder = "reading sequence out of order"
+
+	MsgErrorInvalidRpcRequest  = "invalid request"
+	MsgErrorUnknownRpcMethod   = "unknown method"
+	MsgErrorInvalidRpcParams   = "invalid params"
+	MsgErrorForbiddenRpcOrigin = "origin not allowed"
+	MsgErrorUnknownTopic       = "unknown topic"
+	MsgErrorSubscriptionEnded  = "subscription ended"
+	MsgErrorInvalidCbor        = "invalid cbor"
 )
 
 var (
-	ErrorFailedDecodePrivateKey = errors.New(MsgErrorFailedDecodePrivateKey)
+	ErrorFailedDecodePrivateKey     = errors.New(MsgErrorFailedDecodePrivateKey)
+	ErrorInvalidSignatureLength     = errors.New(MsgErrorInvalidSignatureLength)
+	ErrorInvalidCertLength          = errors.New(MsgErrorInvalidCertLength)
+	ErrorInvalidChainLength         = errors.New(MsgErrorInvalidChainLength)
+	ErrorInvalidCert                = errors.New(MsgErrorInvalidCert)
+	ErrorInvalidCertDerivation      = errors.New(MsgErrorInvalidCertDerivation)
+	ErrorInvalidVrfProof            = errors.New(MsgErrorInvalidVrfProof)
+	ErrorVrfEncodeToCurve           = errors.New(MsgErrorVrfEncodeToCurve)
+	ErrorCounterExists              = errors.New(MsgErrorCounterExists)
+	ErrorCounterDoesNotExist        = errors.New(MsgErrorCounterDoesNotExist)
+	ErrorCounterQuotaReached        = errors.New(MsgErrorCounterQuotaReached)
+	ErrorCounterOverflow            = errors.New(MsgErrorCounterOverflow)
+	ErrorDataDirChanged             = errors.New(MsgErrorDataDirChanged)
+	ErrorUnsupportedQuoteVersion    = errors.New(MsgErrorUnsupportedQuoteVersion)
+	ErrorInvalidMeasurementRegister = errors.New(MsgErrorInvalidMeasurementRegister)
+	ErrorInvalidMeasurementEvent    = errors.New(MsgErrorInvalidMeasurementEvent)
+	ErrorMeasurementLogFull         = errors.New(MsgErrorMeasurementLogFull)
+	ErrorTooManyChallenges          = errors.New(MsgErrorTooManyChallenges)
+	ErrorUnknownNonce               = errors.New(MsgErrorUnknownNonce)
+	ErrorNonceExpired               = errors.New(MsgErrorNonceExpired)
+	ErrorNonceReused                = errors.New(MsgErrorNonceReused)
+	ErrorPeerRequestFailed          = errors.New(MsgErrorPeerRequestFailed)
+	ErrorPeerAttestationInvalid     = errors.New(MsgErrorPeerAttestationInvalid)
+	ErrorUntrustedVendorRoot        = errors.New(MsgErrorUntrustedVendorRoot)
+	ErrorInvalidEphemeralKey        = errors.New(MsgErrorInvalidEphemeralKey)
+	ErrorSessionDoesNotExist        = errors.New(MsgErrorSessionDoesNotExist)
+	ErrorSessionSignatureMismatch   = errors.New(MsgErrorSessionSignatureMismatch)
+	ErrorSessionAlreadyEstablished  = errors.New(MsgErrorSessionAlreadyEstablished)
+	ErrorInvalidRaTlsCert           = errors.New(MsgErrorInvalidRaTlsCert)
+	ErrorRaTlsCertExpired           = errors.New(MsgErrorRaTlsCertExpired)
+	ErrorMissingRaTlsEvidence       = errors.New(MsgErrorMissingRaTlsEvidence)
+	ErrorInvalidRaTlsEvidence       = errors.New(MsgErrorInvalidRaTlsEvidence)
+	ErrorRaTlsSignatureMismatch     = errors.New(MsgErrorRaTlsSignatureMismatch)
+	ErrorRaTlsAppKeyMismatch        = errors.New(MsgErrorRaTlsAppKeyMismatch)
+	ErrorUnknownEatEncoding         = errors.New(MsgErrorUnknownEatEncoding)
+	ErrorInvalidFaultProfile        = errors.New(MsgErrorInvalidFaultProfile)
+	ErrorInvalidRecording           = errors.New(MsgErrorInvalidRecording)
+	ErrorInvalidHexResponse         = errors.New(MsgErrorInvalidHexResponse)
+	ErrorAttestationInvalid         = errors.New(MsgErrorAttestationInvalid)
+	ErrorMissingVendorRoot          = errors.New(MsgErrorMissingVendorRoot)
+	ErrorMissingAdminToken          = errors.New(MsgErrorMissingAdminToken)
+	ErrorChainLeafMismatch          = errors.New(MsgErrorChainLeafMismatch)
+	ErrorNotDeviceChain             = errors.New(MsgErrorNotDeviceChain)
+	ErrorNotAppChain                = errors.New(MsgErrorNotAppChain)
+	ErrorSignatureMismatch          = errors.New(MsgErrorSignatureMismatch)
+	ErrorInvalidAddress             = errors.New(MsgErrorInvalidAddress)
+	ErrorUnknownEnrollmentEncoding  = errors.New(MsgErrorUnknownEnrollmentEncoding)
+	ErrorEnrollmentDeadlineExpired  = errors.New(MsgErrorEnrollmentDeadlineExpired)
+	ErrorInvalidChainId             = errors.New(MsgErrorInvalidChainId)
+	ErrorInvalidNostrKind           = errors.New(MsgErrorInvalidNostrKind)
+	ErrorInvalidNostrTags           = errors.New(MsgErrorInvalidNostrTags)
+	ErrorNostrContentTooLong        = errors.New(MsgErrorNostrContentTooLong)
+	ErrorInvalidNostrContent        = errors.New(MsgErrorInvalidNostrContent)
+	ErrorNostrEventIdMismatch       = errors.New(MsgErrorNostrEventIdMismatch)
+	ErrorInvalidNostrPubKey         = errors.New(MsgErrorInvalidNostrPubKey)
+	ErrorInvalidSensor              = errors.New(MsgErrorInvalidSensor)
+	ErrorUnknownSensorType          = errors.New(MsgErrorUnknownSensorType)
+	ErrorSequenceOutOfOrder         = errors.New(MsgErrorSequenceOutOfOrder)
+
+	ErrorFailedDecodeMessage              = errors.New(MsgErrorFailedDecodeMessage)
+	ErrorFailedHexDecodeRemoteAttestation = errors.New(MsgErrorFailedHexDecodeRemoteAttestation)
+	ErrorWrongRemoteAttestationLength     = errors.New(MsgErrorWrongRemoteAttestationLength)
+	ErrorWrongRemoteAttestationSignature  = errors.New(MsgErrorWrongRemoteAttestationSignature)
+	ErrorKeyOrValueNotFound               = errors.New(MsgErrorKeyOrValueNotFound)
+	ErrorFailedProvisionDecoding          = errors.New(MsgErrorFailedProvisionDecoding)
+	ErrorInvalidProvisionLength           = errors.New(MsgErrorInvalidProvisionLength)
+	ErrorFailedProvisionVerification      = errors.New(MsgErrorFailedProvisionVerification)
+	ErrorValueTooLarge                    = errors.New(MsgErrorValueTooLarge)
+	ErrorKeyExists                        = errors.New(MsgErrorKeyExists)
+	ErrorKeyDoesNotExist                  = errors.New(MsgErrorKeyDoesNotExist)
+	ErrorInvalidRpcParams                 = errors.New(MsgErrorInvalidRpcParams)
+	ErrorForbiddenRpcOrigin               = errors.New(MsgErrorForbiddenRpcOrigin)
+	ErrorFailedVrfProve                   = errors.New(MsgErrorFailedVrfProve)
+	ErrorFailedIssueChallenge             = errors.New(MsgErrorFailedIssueChallenge)
 )
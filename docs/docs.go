@@ -15,56 +15,80 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/attestation/appkey": {
+        "/api/v1/admin/fault": {
             "get": {
-                "description": "Get app derived key for current (simulated) tee version",
+                "description": "Get the active fault injection profile, requires the admin token",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "tags": [
-                    "attestation"
+                    "admin"
+                ],
+                "summary": "Get the fault injection profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
                 ],
-                "summary": "Get app derived key for current (simulated) tee version",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.ApplicationKey"
+                            "$ref": "#/definitions/fault.Profile"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/attestation/sign": {
-            "post": {
-                "description": "Sign with app derived key for current (simulated) tee version",
+            },
+            "put": {
+                "description": "Replace the active fault injection profile, faults are scoped per endpoint and fire at their configured rates, requires the admin token",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "tags": [
-                    "attestation"
+                    "admin"
                 ],
-                "summary": "Sign with app derived key for current (simulated) tee version",
+                "summary": "Set the fault injection profile",
                 "parameters": [
                     {
-                        "description": "Data to be signed",
-                        "name": "data",
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fault injection profile",
+                        "name": "profile",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/web.SignRequest"
+                            "$ref": "#/definitions/fault.Profile"
                         }
                     }
                 ],
@@ -72,7 +96,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.SignResponse"
+                            "$ref": "#/definitions/fault.Profile"
                         }
                     },
                     "400": {
@@ -80,85 +104,147 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/api/v1/device/key": {
-            "get": {
-                "description": "Get device key for current (simulated) tee version",
+            },
+            "delete": {
+                "description": "Clear the active fault injection profile and the recorded fault events, requires the admin token",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "tags": [
-                    "device"
+                    "admin"
+                ],
+                "summary": "Clear the fault injection profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
                 ],
-                "summary": "Get device key for current (simulated) tee version",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.DeviceKey"
+                            "$ref": "#/definitions/fault.Profile"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/device/sign": {
-            "post": {
-                "description": "Get device enrollment key for current (simulated) tee version\nPlease see also the DePhy evm sdk.",
+        "/api/v1/admin/fault/events": {
+            "get": {
+                "description": "Get the injected faults, every response with injected faults carries their ids in the X-Teerminal-Fault header, requires the admin token",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "tags": [
-                    "device"
+                    "admin"
                 ],
-                "summary": "Get device enrollment key for current (simulated) tee version",
+                "summary": "Get the injected faults",
                 "parameters": [
                     {
-                        "description": "Data to be signed",
-                        "name": "signRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/web.SignRequest"
-                        }
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return events with an id greater than since",
+                        "name": "since",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.Enrollment"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/fault.Event"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/device/version": {
+        "/api/v1/attestation/appkey": {
             "get": {
-                "description": "Get version attestation for current (simulated) tee version",
+                "description": "Get app derived key for current (simulated) tee version",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "tags": [
-                    "device"
+                    "attestation"
                 ],
-                "summary": "Get version attestation for current (simulated) tee version",
+                "summary": "Get app derived key for current (simulated) tee version",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Remote requester's nonce and signature, serialized as hex(64b nonce || 64b pubKey || 65b signature), in which signature is the signature of nonce || pubKey, if signature not provided, omit signature",
-                        "name": "attestation",
+                        "description": "Additional public key formats, comma separated: compressed, address, xonly, did or all",
+                        "name": "formats",
                         "in": "query"
                     }
                 ],
@@ -166,7 +252,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.Attestation"
+                            "$ref": "#/definitions/web.ApplicationKey"
                         }
                     },
                     "400": {
@@ -178,27 +264,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/kv/delete": {
-            "delete": {
-                "description": "Delete a key-value pair",
+        "/api/v1/attestation/nostr": {
+            "post": {
+                "description": "Build a NIP-01 event of kind, tags and content, whose pubkey is the x-only app public key, compute its id and sign it with BIP-340\nThe app cert chain is appended as a [\"teerminal_chain\", \u003cappCert\u003e] tag, so relays and clients can verify the pubkey against the vendor root",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "tags": [
-                    "kv"
+                    "attestation"
                 ],
-                "summary": "Delete a key-value pair",
+                "summary": "Sign a nostr event with app derived key for current (simulated) tee version",
                 "parameters": [
                     {
-                        "description": "Request to delete",
-                        "name": "DeleteRequest",
+                        "description": "Event to sign",
+                        "name": "event",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/web.DeleteKvRequest"
+                            "$ref": "#/definitions/web.NostrEventRequest"
                         }
                     }
                 ],
@@ -206,7 +294,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.WriteKvResponse"
+                            "$ref": "#/definitions/nostr.Event"
                         }
                     },
                     "400": {
@@ -218,21 +306,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/kv/quota": {
-            "get": {
-                "description": "Get the quota of the current application, return the number of keys that can be written",
+        "/api/v1/attestation/sign": {
+            "post": {
+                "description": "Sign with app derived key for current (simulated) tee version",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "tags": [
-                    "kv"
+                    "attestation"
+                ],
+                "summary": "Sign with app derived key for current (simulated) tee version",
+                "parameters": [
+                    {
+                        "description": "Data to be signed",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.SignRequest"
+                        }
+                    }
                 ],
-                "summary": "Get the quota of the current application",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.QuotaResponse"
+                            "$ref": "#/definitions/web.SignResponse"
                         }
                     },
                     "400": {
@@ -244,33 +348,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/kv/read": {
-            "get": {
-                "description": "Read a key-value pair, If the target key is protected, the protector must be provided.",
+        "/api/v1/attestation/vrf": {
+            "post": {
+                "description": "Compute ECVRF-SECP256K1-SHA256-TAI output and proof of alpha with the app derived key, the proof can be verified against the appPubKey, which is the leaf of the appCert chain",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "tags": [
-                    "kv"
+                    "attestation"
                 ],
-                "summary": "Read a key-value pair",
+                "summary": "Compute a verifiable random output with app derived key for current (simulated) tee version",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Key",
-                        "name": "key",
-                        "in": "query",
-                        "required": true
+                        "description": "VRF input",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.VrfRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.ReadKvResponse"
+                            "$ref": "#/definitions/web.VrfResponse"
                         }
                     },
                     "400": {
@@ -282,27 +390,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/kv/write": {
+        "/api/v1/counter/create": {
             "post": {
-                "description": "Write a key-value pair, If Provision is provided, the remote provision information will be added, and only the provisioner can write it, If Protected is provided, the target key will be protected, and only the protector can read it.",
+                "description": "Create a named monotonic counter for the current application starting at 0, the counter survives restarts",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/cbor"
                 ],
                 "tags": [
-                    "kv"
+                    "counter"
                 ],
-                "summary": "Write a key-value pair",
+                "summary": "Create a persistent monotonic counter",
                 "parameters": [
                     {
-                        "description": "Key",
-                        "name": "keyInfo",
+                        "description": "Counter to create",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/web.WriteKvRequest"
+                            "$ref": "#/definitions/web.CounterRequest"
                         }
                     }
                 ],
@@ -310,7 +420,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/web.WriteKvResponse"
+                            "$ref": "#/definitions/web.CounterResponse"
                         }
                     },
                     "400": {
@@ -318,70 +428,1750 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/web.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "web.ApplicationKey": {
-            "type": "object",
-            "properties": {
-                "appCert": {
-                    "type": "string"
-                },
-                "appPubKey": {
-                    "type": "string"
-                }
-            }
-        },
-        "web.Attestation": {
-            "type": "object",
-            "properties": {
-                "attestationVer": {
-                    "type": "string"
-                },
-                "deviceCert": {
-                    "type": "string"
-                },
-                "signature": {
-                    "type": "string"
-                },
-                "teePlatformVer": {
-                    "type": "integer"
-                }
-            }
-        },
-        "web.DeleteKvRequest": {
-            "type": "object",
-            "properties": {
-                "key": {
-                    "type": "string"
-                }
-            }
         },
-        "web.DeviceKey": {
-            "type": "object",
-            "properties": {
-                "deviceCert": {
-                    "type": "string"
-                },
-                "devicePubKey": {
-                    "type": "string"
+        "/api/v1/counter/increment": {
+            "post": {
+                "description": "Increment a named monotonic counter of the current application, the new value is persisted before it is returned",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "counter"
+                ],
+                "summary": "Increment a persistent monotonic counter",
+                "parameters": [
+                    {
+                        "description": "Counter to increment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.CounterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.CounterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "web.Enrollment": {
-            "type": "object",
-            "properties": {
-                "deviceKey": {
-                    "type": "string"
-                },
-                "payload": {
+        "/api/v1/counter/read": {
+            "get": {
+                "description": "Read a named monotonic counter of the current application, the value is signed by the app key",
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "counter"
+                ],
+                "summary": "Read a persistent monotonic counter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Counter name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Caller's nonce of up to 64 bytes in hex, signed along with the value",
+                        "name": "nonce",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.CounterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/device/challenge": {
+            "get": {
+                "description": "Issue a fresh nonce, which must be used as the nonce of /api/v1/device/version before it expires, and can only be used once",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "device"
+                ],
+                "summary": "Get a nonce for version attestation",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Challenge"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/device/enroll": {
+            "post": {
+                "description": "Sign an enrollment bound to a chain id, a registry contract, an owner and a deadline, so it can not be replayed on another chain or registry\nWith encoding=canonical, the signable is \"TEERMINAL_STRUCTURED_ENROLLMENT:\" || keccak256(abi.encode(uint256 chainId, address registry, address owner, uint256 deadline)), whose prefix differs from /api/v1/device/sign\nWith encoding=eip712, the signable is \"\\x19\\x01\" || domainSeparator || hashStruct(Enrollment(address owner,uint256 deadline)), in which the domain is EIP712Domain(name \"Teerminal\", version \"1\", chainId, verifyingContract registry)",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "device"
+                ],
+                "summary": "Sign a structured enrollment with the device root key",
+                "parameters": [
+                    {
+                        "description": "Enrollment fields",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.EnrollRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.StructuredEnrollment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/device/key": {
+            "get": {
+                "description": "Get device key for current (simulated) tee version",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "device"
+                ],
+                "summary": "Get device key for current (simulated) tee version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Additional public key formats, comma separated: compressed, address, xonly, did or all",
+                        "name": "formats",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.DeviceKey"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/device/sign": {
+            "post": {
+                "description": "Get device enrollment key for current (simulated) tee version\nPlease see also the DePhy evm sdk.",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "device"
+                ],
+                "summary": "Get device enrollment key for current (simulated) tee version",
+                "parameters": [
+                    {
+                        "description": "Data to be signed",
+                        "name": "signRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.SignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Enrollment"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/device/version": {
+            "get": {
+                "description": "Get version attestation for current (simulated) tee version\nWith format=dcap, an emulated SGX DCAP quote (web.DcapQuote) is returned instead, whose report data is sha256(nonce) || sha256(app public key)\nWith format=nitro, an emulated Nitro Enclaves attestation document (web.NitroDocument) is returned instead\nWith format=eat, an IETF Entity Attestation Token (web.EatToken) signed by the device root key is returned instead, encoded as a JWT (ES256K) or a CWT, the registers digest and the event log are the claims teerminal_registers_digest and teerminal_event_log (JWT) or -65538 and -65539 (CWT)\nNonces must be issued by /api/v1/device/challenge, and are rejected once expired or used",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "device"
+                ],
+                "summary": "Get version attestation for current (simulated) tee version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Remote requester's nonce and signature, serialized as hex(64b nonce || 64b pubKey || 65b signature), in which nonce is issued by /api/v1/device/challenge and signature is the requester's signature of nonce || pubKey, leave empty for an unsigned attestation without nonce",
+                        "name": "attestation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attestation format: native (default), dcap, nitro or eat",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nonce issued by /api/v1/device/challenge in hex, required by dcap and eat formats, optional for nitro format",
+                        "name": "nonce",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "DCAP quote version, 3 (default) or 4",
+                        "name": "quoteVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User data in hex, embedded by nitro format",
+                        "name": "userData",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Public key in hex, embedded by nitro format",
+                        "name": "publicKey",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "EAT encoding: jwt (default) or cwt",
+                        "name": "encoding",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Attestation in native format, DcapQuote in dcap format, NitroDocument in nitro format, EatToken in eat format",
+                        "schema": {
+                            "$ref": "#/definitions/web.Attestation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/handshake/confirm": {
+            "post": {
+                "description": "Called by the initiating device: record its signature over the session, after which the session is established",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "handshake"
+                ],
+                "summary": "Complete a handshake started by a peer device",
+                "parameters": [
+                    {
+                        "description": "Initiator's session signature",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.ConfirmHandshakeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.ConfirmHandshakeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/handshake/initiate": {
+            "post": {
+                "description": "Attest to the peer and verify the peer's attestation against the trusted vendor roots, then agree on a session key signed by both devices, requires the admin token",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "handshake"
+                ],
+                "summary": "Run a mutual attestation handshake with a peer device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Peer to handshake with",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.InitiateHandshakeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.HandshakeSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/handshake/respond": {
+            "post": {
+                "description": "Called by the initiating device: verify its attestation over our nonce and its ephemeral key, then attest over its nonce and our ephemeral key and sign the session",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "handshake"
+                ],
+                "summary": "Answer a handshake started by a peer device",
+                "parameters": [
+                    {
+                        "description": "Initiator's attestation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.RespondHandshakeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.RespondHandshakeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/handshake/session": {
+            "get": {
+                "description": "Get a session agreed with a peer device, including the session key, for the application running on this device, requires the admin token",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "handshake"
+                ],
+                "summary": "Get a handshake session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.HandshakeSession"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/kv/delete": {
+            "post": {
+                "description": "Delete a key-value pair",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "kv"
+                ],
+                "summary": "Delete a key-value pair",
+                "parameters": [
+                    {
+                        "description": "Request to delete",
+                        "name": "DeleteRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.DeleteKvRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.DeleteKvResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/kv/quota": {
+            "get": {
+                "description": "Get the quota of the current application, return the number of keys that can be written",
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "kv"
+                ],
+                "summary": "Get the quota of the current application",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.QuotaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/kv/read": {
+            "get": {
+                "description": "Read a key-value pair, If the target key is protected, the protector must be provided.",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "kv"
+                ],
+                "summary": "Read a key-value pair",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key",
+                        "name": "key",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.ReadKvResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/kv/write": {
+            "post": {
+                "description": "Write a key-value pair, If Provision is provided, the remote provision information will be added, and only the provisioner can write it, If Protected is provided, the target key will be protected, and only the protector can read it.",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "kv"
+                ],
+                "summary": "Write a key-value pair",
+                "parameters": [
+                    {
+                        "description": "Key",
+                        "name": "keyInfo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.WriteKvRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.WriteKvResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/measurement/extend": {
+            "post": {
+                "description": "Extend a measurement register with data: new = keccak256(old || data), registers can never be reset except by a config reload, the event log holds at most 1024 events, requires the admin token",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "measurement"
+                ],
+                "summary": "Extend a measurement register",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Measurement to extend",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.ExtendMeasurementRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.ExtendMeasurementResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/measurement/registers": {
+            "get": {
+                "description": "Get the current measurement register values and the event log which replays to them",
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "measurement"
+                ],
+                "summary": "Get measurement registers and event log",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.Measurements"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/publisher/commands": {
+            "get": {
+                "description": "Get the verified command events (kind 1574) addressed to the device by a p tag of its x-only app public key",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "publisher"
+                ],
+                "summary": "Get the commands received from the nostr relays",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only return commands with an id greater than since",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/publisher.Command"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/publisher/relays": {
+            "get": {
+                "description": "Get the connection state of every configured relay, status (kind 30078) and telemetry (kind 1573) events signed by the app key are published to the connected relays every publishInterval seconds",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "publisher"
+                ],
+                "summary": "Get the nostr relays the device publishes to",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/publisher.RelayStatus"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensor/list": {
+            "get": {
+                "description": "Get the running simulated sensors and their models",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "Get the simulated sensors",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/config.Sensor"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensor/readings": {
+            "get": {
+                "description": "Get the stored readings in sequence order, every reading is signed by the app key over\n\"TEERMINAL_READING:\" || sequence || timestamp || keccak256(sensor) || keccak256(keccak256(name) || value for every value sorted by name)",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "Get the readings of the simulated sensors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only return readings with a sequence greater than since",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return readings of the sensor",
+                        "name": "sensor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/sensor.Reading"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sensor/stream": {
+            "get": {
+                "description": "Stream the readings as server-sent events named reading, whose id is the sequence, starting with the stored readings after since or Last-Event-ID\nThe stream is closed if the client falls behind, reconnect with Last-Event-ID to resume without gaps",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "sensor"
+                ],
+                "summary": "Stream the readings of the simulated sensors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only stream readings with a sequence greater than since",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only stream readings of the sensor",
+                        "name": "sensor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Sequence of the last received reading, takes precedence over since",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/sensor.Reading"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verify/attestation": {
+            "post": {
+                "description": "Verify the device cert chain against the vendor root, replay the measurement event log, and verify the device signature over nonce || pubKey || teePlatformVersion || version || keccak256(registers)",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "verify"
+                ],
+                "summary": "Verify a version attestation produced by /api/v1/device/version",
+                "parameters": [
+                    {
+                        "description": "Attestation to verify",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.VerifyAttestationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.VerifyAttestationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verify/chain": {
+            "post": {
+                "description": "Verify a cert chain with the same rules as CertLib.verifyCertChain, returns the result of every cert for diagnostics",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "verify"
+                ],
+                "summary": "Verify a cert chain against a vendor root",
+                "parameters": [
+                    {
+                        "description": "Chain to verify",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.VerifyChainRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.ChainDiagnostics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verify/enrollment": {
+            "post": {
+                "description": "Verify the device chain against the vendor root, and the device signature over \"DEPHY_ID_SIGNED_MESSAGE:\" || keccak256(payload) against its leaf",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "verify"
+                ],
+                "summary": "Verify an enrollment produced by /api/v1/device/sign",
+                "parameters": [
+                    {
+                        "description": "Enrollment to verify",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.VerifyEnrollmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.VerifyEnrollmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verify/signature": {
+            "post": {
+                "description": "Verify a 65 bytes signature over keccak256(data), against either the 64 bytes public key or the ethereum address, returns the recovered signer for diagnostics",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "verify"
+                ],
+                "summary": "Verify a signature against a public key or address",
+                "parameters": [
+                    {
+                        "description": "Signature to verify",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.VerifySignatureRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.SignatureDiagnostics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/verify/vrf": {
+            "post": {
+                "description": "Verify an ECVRF-SECP256K1-SHA256-TAI proof of alpha against the public key, and return the VRF output",
+                "consumes": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/cbor"
+                ],
+                "tags": [
+                    "verify"
+                ],
+                "summary": "Verify a VRF proof produced by /api/v1/attestation/vrf",
+                "parameters": [
+                    {
+                        "description": "Proof to verify",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/web.VerifyVrfRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/web.VerifyVrfResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/web.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ws": {
+            "get": {
+                "description": "Upgrades to a WebSocket speaking JSON-RPC 2.0 in text frames, one request {\"jsonrpc\": \"2.0\", \"id\": 1, \"method\": \"kv.read\", \"params\": {\"key\": \"k\"}} per frame, answered by {\"jsonrpc\": \"2.0\", \"id\": 1, \"result\": {...}} or {\"jsonrpc\": \"2.0\", \"id\": 1, \"error\": {\"code\": -32000, \"message\": \"...\"}}\nMethods and their params: device.key {formats}, device.version {attestation}, attestation.appkey {formats}, attestation.sign (web.SignRequest), kv.write (web.WriteKvRequest), kv.read {key}, kv.delete (web.DeleteKvRequest) and kv.quota, results are the responses of the REST endpoints\nRequests without id are notifications: they are run but not answered\nBrowsers may only connect from the device's own host or the rpcOrigins of the config, connections without Origin are accepted\nNotifications are pushed without id: kv.changed (kv.Change) after every kv write or delete, and config.changed (web.ConfigChange) after the config is reloaded, the connection is closed if it falls behind",
+                "tags": [
+                    "rpc"
+                ],
+                "summary": "WebSocket API mirroring the device, attestation and kv endpoints",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/web.RpcResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Origin not allowed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "config.Sensor": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "Base is the value at start",
+                    "type": "number"
+                },
+                "drift": {
+                    "description": "Drift is the change of the base per second",
+                    "type": "number"
+                },
+                "interval": {
+                    "description": "Interval is the milliseconds between readings, default is 1000",
+                    "type": "integer"
+                },
+                "latitude": {
+                    "description": "Latitude is the start position of gps sensors",
+                    "type": "number"
+                },
+                "longitude": {
+                    "description": "Longitude is the start position of gps sensors",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "noise": {
+                    "description": "Noise is the standard deviation of the gaussian noise of every reading",
+                    "type": "number"
+                },
+                "seed": {
+                    "description": "Seed is the noise seed, default is derived from the name",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Type is energy, gps, temperature or counter",
+                    "type": "string"
+                }
+            }
+        },
+        "fault.Event": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "description": "Detail is the affected field, or the injected latency or status",
+                    "type": "string"
+                },
+                "endpoint": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "fault.Profile": {
+            "type": "object",
+            "properties": {
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/fault.Rule"
+                    }
+                }
+            }
+        },
+        "fault.Rule": {
+            "type": "object",
+            "properties": {
+                "corruptCertRate": {
+                    "description": "CorruptCertRate is the rate of corrupting the signature of the last cert of chains",
+                    "type": "number"
+                },
+                "downgradeRate": {
+                    "description": "DowngradeRate is the rate of decrementing teePlatformVer",
+                    "type": "number"
+                },
+                "endpoint": {
+                    "description": "Endpoint is the route path, e.g. /api/v1/device/version, or * for every endpoint without its own rule",
+                    "type": "string"
+                },
+                "errorRate": {
+                    "description": "ErrorRate is the rate of 5xx responses returned instead of calling the endpoint",
+                    "type": "number"
+                },
+                "errorStatus": {
+                    "description": "ErrorStatus is the status of injected errors, default is 500",
+                    "type": "integer"
+                },
+                "flipSignatureRate": {
+                    "description": "FlipSignatureRate is the rate of flipping one bit of signatures",
+                    "type": "number"
+                },
+                "latencyMs": {
+                    "description": "LatencyMs is added to every matching request",
+                    "type": "integer"
+                },
+                "truncateChainRate": {
+                    "description": "TruncateChainRate is the rate of dropping the last cert of chains",
+                    "type": "number"
+                }
+            }
+        },
+        "measurement.Event": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Data is the hex encoded extended data",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "register": {
+                    "type": "integer"
+                }
+            }
+        },
+        "nostr.Event": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "CreatedAt is the unix timestamp in seconds",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "Id is sha256 of the serialized event in hex",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "kind": {
+                    "type": "integer"
+                },
+                "pubkey": {
+                    "description": "PubKey is the 32 bytes x-only public key in hex",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "sig": {
+                    "description": "Sig is the 64 bytes BIP-340 signature of the id in hex",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "publisher.Command": {
+            "type": "object",
+            "properties": {
+                "event": {
+                    "$ref": "#/definitions/nostr.Event"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "receivedAt": {
+                    "type": "string"
+                },
+                "relay": {
+                    "description": "Relay is the url of the first relay the command was received from",
+                    "type": "string"
+                }
+            }
+        },
+        "publisher.RelayStatus": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "description": "Accepted is the number of events the relay acknowledged with OK true",
+                    "type": "integer"
+                },
+                "connected": {
+                    "type": "boolean"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "published": {
+                    "description": "Published is the number of events sent to the relay",
+                    "type": "integer"
+                },
+                "rejected": {
+                    "description": "Rejected is the number of events the relay acknowledged with OK false",
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "sensor.Reading": {
+            "type": "object",
+            "properties": {
+                "appPubKey": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "sensor": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "description": "Sequence increases on every reading of any sensor, and across restarts",
+                    "type": "integer"
+                },
+                "signature": {
+                    "description": "Signature is the app key signature over Signable",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "timestamp": {
+                    "description": "Timestamp is the unix time in milliseconds",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "web.ApplicationKey": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Address is the EIP-55 checksummed ethereum address",
+                    "type": "string"
+                },
+                "appCert": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "appPubKey": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "compressed": {
+                    "description": "Compressed is the 33 bytes SEC1 compressed public key",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "didKey": {
+                    "description": "DidKey is the did:key identifier of the public key",
+                    "type": "string"
+                },
+                "xOnly": {
+                    "description": "XOnly is the 32 bytes BIP-340 x-only public key",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.Attestation": {
+            "type": "object",
+            "properties": {
+                "attestationVer": {
+                    "type": "string"
+                },
+                "deviceCert": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "eventLog": {
+                    "description": "EventLog replays to the register values, starting from zero registers",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/measurement.Event"
+                    }
+                },
+                "registers": {
+                    "description": "Registers are the current register values",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "hex"
+                    }
+                },
+                "signature": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "teePlatformVer": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "description": "Timestamp is the unix time in seconds when the attestation was signed",
+                    "type": "integer"
+                }
+            }
+        },
+        "web.CertDiagnostics": {
+            "type": "object",
+            "properties": {
+                "derivation": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "derivationValid": {
+                    "description": "DerivationValid is true if the prover is the root or the previous provee",
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "provee": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "prover": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "signature": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "signatureValid": {
+                    "description": "SignatureValid is true if the signer is the prover",
+                    "type": "boolean"
+                },
+                "signer": {
+                    "description": "Signer is the public key recovered from the cert signature",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.ChainDiagnostics": {
+            "type": "object",
+            "properties": {
+                "certs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/web.CertDiagnostics"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "leaf": {
+                    "description": "Leaf is the last provee of the chain",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "root": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "web.Challenge": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "description": "ExpiresAt is the unix timestamp in seconds after which the nonce is rejected",
+                    "type": "integer"
+                },
+                "nonce": {
+                    "description": "Nonce is the 64 bytes device-issued nonce in hex",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.ConfirmHandshakeRequest": {
+            "type": "object",
+            "properties": {
+                "sessionId": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "signature": {
+                    "description": "Signature is the initiator's device signature over the session",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.ConfirmHandshakeResponse": {
+            "type": "object",
+            "properties": {
+                "established": {
+                    "type": "boolean"
+                },
+                "sessionId": {
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.CounterRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name is the name of the counter",
+                    "type": "string"
+                },
+                "nonce": {
+                    "description": "Nonce is the caller's nonce of up to 64 bytes, signed along with the value to prove it is fresh, optional",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.CounterResponse": {
+            "type": "object",
+            "properties": {
+                "appPubKey": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nonce": {
+                    "description": "Nonce is the caller's nonce, if any",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "signature": {
+                    "description": "Signature is the app key signature over CounterSignable(name, value, nonce)",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "web.DeleteKvRequest": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "web.DeleteKvResponse": {
+            "type": "object",
+            "properties": {
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "web.DeviceKey": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Address is the EIP-55 checksummed ethereum address",
+                    "type": "string"
+                },
+                "compressed": {
+                    "description": "Compressed is the 33 bytes SEC1 compressed public key",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "deviceCert": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "devicePubKey": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "didKey": {
+                    "description": "DidKey is the did:key identifier of the public key",
+                    "type": "string"
+                },
+                "xOnly": {
+                    "description": "XOnly is the 32 bytes BIP-340 x-only public key",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.EnrollRequest": {
+            "type": "object",
+            "properties": {
+                "chainId": {
+                    "description": "ChainId is the EVM chain id the enrollment is valid on",
+                    "type": "integer"
+                },
+                "deadline": {
+                    "description": "Deadline is the unix timestamp in seconds after which the enrollment is refused, must be in the future",
+                    "type": "integer"
+                },
+                "encoding": {
+                    "description": "Encoding is canonical (default) or eip712",
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the address the device is enrolled to",
+                    "type": "string"
+                },
+                "registry": {
+                    "description": "Registry is the address of the registry contract the enrollment is submitted to",
+                    "type": "string"
+                }
+            }
+        },
+        "web.Enrollment": {
+            "type": "object",
+            "properties": {
+                "deviceKey": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "payload": {
+                    "type": "string",
+                    "format": "hex"
+                },
                 "signature": {
-                    "type": "string"
+                    "type": "string",
+                    "format": "hex"
                 }
             }
         },
@@ -393,6 +2183,150 @@ const docTemplate = `{
                 }
             }
         },
+        "web.ExtendMeasurementRequest": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Data is the hex encoded data to extend with",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "description": {
+                    "description": "Description is recorded in the event log",
+                    "type": "string"
+                },
+                "register": {
+                    "description": "Register is the index of the register to extend",
+                    "type": "integer"
+                }
+            }
+        },
+        "web.ExtendMeasurementResponse": {
+            "type": "object",
+            "properties": {
+                "register": {
+                    "type": "integer"
+                },
+                "value": {
+                    "description": "Value is the new register value, keccak256(old || data)",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.HandshakeSession": {
+            "type": "object",
+            "properties": {
+                "established": {
+                    "type": "boolean"
+                },
+                "initiator": {
+                    "description": "Initiator is true if this device started the handshake",
+                    "type": "boolean"
+                },
+                "localSignature": {
+                    "description": "LocalSignature is this device's signature over \"TEERMINAL_SESSION:\" || sessionId || keccak256(sessionKey)",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "peerDeviceKey": {
+                    "description": "PeerDeviceKey is the leaf of the peer's device cert chain",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "peerSignature": {
+                    "description": "PeerSignature is the peer's signature over the same payload, empty until the peer has signed",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "sessionId": {
+                    "description": "SessionId is the transcript hash",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "sessionKey": {
+                    "description": "SessionKey is the 32 bytes key shared with the peer",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.InitiateHandshakeRequest": {
+            "type": "object",
+            "properties": {
+                "peer": {
+                    "description": "Peer is the base url of the peer device, e.g. http://127.0.0.1:4101",
+                    "type": "string"
+                },
+                "root": {
+                    "description": "Root is the 64 bytes vendor root public key of the peer, leave empty to use the trusted vendor roots",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.MeasurementDiagnostics": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "registers": {
+                    "description": "Registers are the values replayed from the event log",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "hex"
+                    }
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "web.Measurements": {
+            "type": "object",
+            "properties": {
+                "eventLog": {
+                    "description": "EventLog replays to the register values, starting from zero registers",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/measurement.Event"
+                    }
+                },
+                "registers": {
+                    "description": "Registers are the current register values",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "hex"
+                    }
+                }
+            }
+        },
+        "web.NostrEventRequest": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Content is the event content",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "Kind is the NIP-01 event kind, 0 to 65535",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "Tags are the event tags, the chain tag is appended by the device",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "web.QuotaResponse": {
             "type": "object",
             "properties": {
@@ -425,7 +2359,8 @@ const docTemplate = `{
                 },
                 "provisioner": {
                     "description": "Provisioner is the provisioner of the key, if any",
-                    "type": "string"
+                    "type": "string",
+                    "format": "hex"
                 },
                 "value": {
                     "description": "Value is the value of the key",
@@ -433,12 +2368,98 @@ const docTemplate = `{
                 }
             }
         },
+        "web.RespondHandshakeRequest": {
+            "type": "object",
+            "properties": {
+                "attestation": {
+                    "description": "Attestation is the initiator's version attestation over nonce || ephemeralKey",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/web.Attestation"
+                        }
+                    ]
+                },
+                "challenge": {
+                    "description": "Challenge is the nonce issued by the initiator, which the responder attests over",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "ephemeralKey": {
+                    "description": "EphemeralKey is the initiator's 64 bytes ephemeral public key",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "nonce": {
+                    "description": "Nonce is the nonce issued by the responder's /api/v1/device/challenge",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.RespondHandshakeResponse": {
+            "type": "object",
+            "properties": {
+                "attestation": {
+                    "description": "Attestation is the responder's version attestation over challenge || ephemeralKey",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/web.Attestation"
+                        }
+                    ]
+                },
+                "ephemeralKey": {
+                    "description": "EphemeralKey is the responder's 64 bytes ephemeral public key",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "sessionId": {
+                    "description": "SessionId is the transcript hash",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "signature": {
+                    "description": "Signature is the responder's device signature over the session",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.RpcError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Message is the error of the REST endpoint for application errors",
+                    "type": "string"
+                }
+            }
+        },
+        "web.RpcResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/web.RpcError"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "jsonrpc": {
+                    "type": "string"
+                },
+                "result": {
+                    "description": "Result is the response body of the REST endpoint"
+                }
+            }
+        },
         "web.SignRequest": {
             "type": "object",
             "properties": {
                 "data": {
                     "description": "Data is the data to be signed",
-                    "type": "string"
+                    "type": "string",
+                    "format": "hex"
                 }
             }
         },
@@ -446,10 +2467,294 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "pubKey": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "signature": {
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.SignatureDiagnostics": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "expectedAddress": {
+                    "description": "ExpectedAddress is the address the signature was checked against",
+                    "type": "string"
+                },
+                "hash": {
+                    "description": "Hash is keccak256 of the signed data",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "recoveredAddress": {
+                    "description": "RecoveredAddress is the address of the recovered public key",
+                    "type": "string"
+                },
+                "recoveredPubKey": {
+                    "description": "RecoveredPubKey is the 64 bytes public key recovered from the signature",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "recoveryId": {
+                    "description": "RecoveryId is the normalized v (0 or 1), -1 if v is out of range",
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "web.StructuredEnrollment": {
+            "type": "object",
+            "properties": {
+                "chainId": {
+                    "type": "integer"
+                },
+                "deadline": {
+                    "type": "integer"
+                },
+                "deviceKey": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "digest": {
+                    "description": "Digest is keccak256(signable), the hash passed to ecrecover",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "encoding": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "Payload is abi.encode(chainId, registry, owner, deadline)",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "registry": {
+                    "type": "string"
+                },
+                "signable": {
+                    "description": "Signable is the exact payload signed by the device root key, see enrollment.StructuredSignable",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "signature": {
+                    "description": "Signature is the 65 bytes r || s || v signature",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.VerifyAttestationRequest": {
+            "type": "object",
+            "properties": {
+                "attestation": {
+                    "description": "Attestation is the requester's hex(64b nonce || 64b pubKey [|| 65b signature]) sent to /api/v1/device/version",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "response": {
+                    "description": "Response is the attestation returned by /api/v1/device/version",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/web.Attestation"
+                        }
+                    ]
+                },
+                "root": {
+                    "description": "Root is the 64 bytes vendor root public key, leave empty to use this device's vendor root",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.VerifyAttestationResponse": {
+            "type": "object",
+            "properties": {
+                "chain": {
+                    "$ref": "#/definitions/web.ChainDiagnostics"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "measurements": {
+                    "$ref": "#/definitions/web.MeasurementDiagnostics"
+                },
+                "signable": {
+                    "description": "Signable is the reconstructed nonce || pubKey || teePlatformVersion || version || timestamp || keccak256(registers)",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "signature": {
+                    "$ref": "#/definitions/web.SignatureDiagnostics"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "web.VerifyChainRequest": {
+            "type": "object",
+            "properties": {
+                "chain": {
+                    "description": "Chain is the concatenated 257 bytes certs",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "root": {
+                    "description": "Root is the 64 bytes vendor root public key, leave empty to use this device's vendor root",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.VerifyEnrollmentRequest": {
+            "type": "object",
+            "properties": {
+                "chain": {
+                    "description": "Chain is the device cert chain from /api/v1/device/key, an enrollment is only valid with the device chain",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "enrollment": {
+                    "description": "Enrollment is the enrollment returned by /api/v1/device/sign",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/web.Enrollment"
+                        }
+                    ]
+                },
+                "root": {
+                    "description": "Root is the 64 bytes vendor root public key, leave empty to use this device's vendor root",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.VerifyEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "chain": {
+                    "$ref": "#/definitions/web.ChainDiagnostics"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "signable": {
+                    "description": "Signable is the reconstructed \"DEPHY_ID_SIGNED_MESSAGE:\" || keccak256(payload)",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "signature": {
+                    "$ref": "#/definitions/web.SignatureDiagnostics"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "web.VerifySignatureRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Address is the expected ethereum address, either PubKey or Address is required",
                     "type": "string"
                 },
+                "data": {
+                    "description": "Data is the signed data, the signature is over keccak256(data)",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "pubKey": {
+                    "description": "PubKey is the expected 64 bytes public key, either PubKey or Address is required",
+                    "type": "string",
+                    "format": "hex"
+                },
                 "signature": {
+                    "description": "Signature is the 65 bytes r || s || v signature",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.VerifyVrfRequest": {
+            "type": "object",
+            "properties": {
+                "alpha": {
+                    "description": "Alpha is the VRF input",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "proof": {
+                    "description": "Proof is the 81 bytes proof returned by /api/v1/attestation/vrf",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "pubKey": {
+                    "description": "PubKey is the 64 bytes public key, e.g. appPubKey from /api/v1/attestation/appkey",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.VerifyVrfResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "string"
+                },
+                "output": {
+                    "description": "Output is the 32 bytes VRF output derived from the proof",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "web.VrfRequest": {
+            "type": "object",
+            "properties": {
+                "alpha": {
+                    "description": "Alpha is the VRF input",
+                    "type": "string",
+                    "format": "hex"
+                }
+            }
+        },
+        "web.VrfResponse": {
+            "type": "object",
+            "properties": {
+                "alpha": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "appCert": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "appPubKey": {
+                    "type": "string",
+                    "format": "hex"
+                },
+                "output": {
+                    "description": "Output is the 32 bytes VRF output (beta)",
+                    "type": "string",
+                    "format": "hex"
+                },
+                "proof": {
+                    "description": "Proof is the 81 bytes ECVRF-SECP256K1-SHA256-TAI proof (gamma || c || s)",
+                    "type": "string",
+                    "format": "hex"
                 }
             }
         },
@@ -469,8 +2774,9 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "provision": {
-                    "description": "Provision is the provision information, leave empty if not needed",
-                    "type": "string"
+                    "description": "Provision is hex(64b provisioner pubKey || 65b signature over ProvisionSignable), leave empty if not needed",
+                    "type": "string",
+                    "format": "hex"
                 },
                 "value": {
                     "description": "Value is the value to write",
@@ -495,8 +2801,8 @@ var SwaggerInfo = &swag.Spec{
 	Host:             "",
 	BasePath:         "",
 	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
+	Title:            "Teerminal",
+	Description:      "Emulated TEE device API. The /api/v1 endpoints are served in JSON, and in CBOR (RFC 8949) to clients sending Accept: application/cbor, in which certs, keys, signatures and the other hex encoded fields are raw byte strings.\nRequests are accepted in CBOR as well with Content-Type: application/cbor, their byte strings standing for the hex encoded fields of the JSON schemas.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,103 @@
+package grpcapi
+
+import (
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/pb"
+	"teerminal/service/kv"
+	"teerminal/service/sensor"
+	"teerminal/web"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	TopicSensor = "sensor"
+	TopicKv     = "kv"
+	TopicConfig = "config"
+)
+
+type eventsServer struct {
+	pb.UnimplementedEventsServer
+}
+
+func (eventsServer) Subscribe(req *pb.SubscribeRequest, stream grpc.ServerStreamingServer[pb.Event]) error {
+	topics := map[string]bool{}
+	for _, topic := range req.Topics {
+		if topic != TopicSensor && topic != TopicKv && topic != TopicConfig {
+			return status.Error(codes.InvalidArgument, constants.MsgErrorUnknownTopic)
+		}
+		topics[topic] = true
+	}
+	all := len(topics) == 0
+	// Unsubscribed topics keep a nil channel, which never receives
+	var readings chan sensor.Reading
+	var changes chan kv.Change
+	var watch chan struct{}
+	if all || topics[TopicKv] {
+		ch, cancel := kv.Subscribe()
+		defer cancel()
+		changes = ch
+	}
+	if all || topics[TopicConfig] {
+		ch, cancel := config.Watch()
+		defer cancel()
+		watch = ch
+	}
+	if all || topics[TopicSensor] {
+		backlog, ch, cancel := sensor.Subscribe(req.Since)
+		defer cancel()
+		readings = ch
+		for _, r := range backlog {
+			if err := sendReading(stream, req.Sensor, r); err != nil {
+				return err
+			}
+		}
+	}
+	for {
+		var err error
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case r, ok := <-readings:
+			if !ok {
+				// The stream fell behind or the sensors stopped, the client resumes from its last sequence
+				return status.Error(codes.Aborted, constants.MsgErrorSubscriptionEnded)
+			}
+			err = sendReading(stream, req.Sensor, r)
+		case change, ok := <-changes:
+			if !ok {
+				return status.Error(codes.Aborted, constants.MsgErrorSubscriptionEnded)
+			}
+			err = stream.Send(&pb.Event{Event: &pb.Event_KvChange{KvChange: &pb.KvChange{Op: change.Op, Key: change.Key}}})
+		case <-watch:
+			c := web.GetConfigChange()
+			err = stream.Send(&pb.Event{Event: &pb.Event_ConfigChange{ConfigChange: &pb.ConfigChange{
+				Generation:     c.Generation,
+				AttestationVer: c.AttestationVer,
+				TeePlatformVer: c.TeePlatformVer,
+			}}})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sendReading sends the reading unless it belongs to another sensor than the requested one
+func sendReading(stream grpc.ServerStreamingServer[pb.Event], name string, r sensor.Reading) error {
+	if name != "" && r.Sensor != name {
+		return nil
+	}
+	return stream.Send(&pb.Event{Event: &pb.Event_Reading{Reading: &pb.SensorReading{
+		Sequence:  r.Sequence,
+		Sensor:    r.Sensor,
+		Type:      r.Type,
+		Timestamp: r.Timestamp,
+		Values:    r.Values,
+		AppPubKey: toBytes(r.PubKey),
+		Signature: toBytes(r.Signature),
+	}}})
+}
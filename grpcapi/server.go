@@ -0,0 +1,225 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"teerminal/constants"
+	"teerminal/pb"
+	"teerminal/web"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// The services call the operations shared with the HTTP API in web, and only convert the messages:
+// binary fields are raw bytes in the messages and hex in the shared operations
+
+// NewServer returns a gRPC server with the device, attestation, kv and events services, and reflection
+func NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	pb.RegisterDeviceServer(s, deviceServer{})
+	pb.RegisterAttestationServer(s, attestationServer{})
+	pb.RegisterKvServer(s, kvServer{})
+	pb.RegisterEventsServer(s, eventsServer{})
+	reflection.Register(s)
+	return s
+}
+
+// toStatus maps the errors of the shared operations to the status codes matching the HTTP status of the REST endpoints
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, constants.ErrorTooManyChallenges):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, constants.ErrorFailedIssueChallenge):
+		return status.Error(codes.Internal, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
+
+type deviceServer struct {
+	pb.UnimplementedDeviceServer
+}
+
+func (deviceServer) GetKey(_ context.Context, req *pb.KeyRequest) (*pb.DeviceKey, error) {
+	resp, err := web.GetDeviceKey(req.Formats)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeviceKey{DeviceCert: toBytes(resp.Cert), DevicePubKey: toBytes(resp.PubKey), Formats: toKeyFormats(resp.KeyFormats)}, nil
+}
+
+func (deviceServer) GetChallenge(context.Context, *pb.ChallengeRequest) (*pb.Challenge, error) {
+	resp, err := web.IssueChallenge()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Challenge{Nonce: toBytes(resp.Nonce), ExpiresAt: resp.ExpiresAt}, nil
+}
+
+func (deviceServer) GetVersion(_ context.Context, req *pb.VersionRequest) (*pb.VersionAttestation, error) {
+	resp, err := web.GetVersionAttestation(hex.EncodeToString(req.Attestation))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	attestation := &pb.VersionAttestation{
+		DeviceCert:     toBytes(resp.Cert),
+		AttestationVer: resp.AttestationVer,
+		TeePlatformVer: resp.TeePlatformVer,
+		Signature:      toBytes(resp.Signature),
+		Timestamp:      resp.Timestamp,
+	}
+	for _, r := range resp.Registers {
+		attestation.Registers = append(attestation.Registers, toBytes(r))
+	}
+	for _, e := range resp.EventLog {
+		attestation.EventLog = append(attestation.EventLog, &pb.MeasurementEvent{Register: int32(e.Register), Data: toBytes(e.Data), Description: e.Description})
+	}
+	return attestation, nil
+}
+
+func (deviceServer) SignEnrollment(_ context.Context, req *pb.SignRequest) (*pb.Enrollment, error) {
+	resp, err := web.SignEnrollment(web.SignRequest{Data: hex.EncodeToString(req.Data)})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Enrollment{DeviceKey: toBytes(resp.DeviceKey), Payload: toBytes(resp.Payload), Signature: toBytes(resp.Signature)}, nil
+}
+
+func (deviceServer) Enroll(_ context.Context, req *pb.EnrollRequest) (*pb.StructuredEnrollment, error) {
+	resp, err := web.Enroll(web.EnrollRequest{
+		ChainId:  req.ChainId,
+		Registry: req.Registry,
+		Owner:    req.Owner,
+		Deadline: req.Deadline,
+		Encoding: req.Encoding,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.StructuredEnrollment{
+		DeviceKey: toBytes(resp.DeviceKey),
+		Encoding:  resp.Encoding,
+		ChainId:   resp.ChainId,
+		Registry:  resp.Registry,
+		Owner:     resp.Owner,
+		Deadline:  resp.Deadline,
+		Payload:   toBytes(resp.Payload),
+		Signable:  toBytes(resp.Signable),
+		Digest:    toBytes(resp.Digest),
+		Signature: toBytes(resp.Signature),
+	}, nil
+}
+
+type attestationServer struct {
+	pb.UnimplementedAttestationServer
+}
+
+func (attestationServer) GetAppKey(_ context.Context, req *pb.KeyRequest) (*pb.ApplicationKey, error) {
+	resp, err := web.GetAppKey(req.Formats)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ApplicationKey{AppCert: toBytes(resp.Cert), AppPubKey: toBytes(resp.PubKey), Formats: toKeyFormats(resp.KeyFormats)}, nil
+}
+
+func (attestationServer) Sign(_ context.Context, req *pb.SignRequest) (*pb.SignResponse, error) {
+	resp, err := web.SignWithAppKey(web.SignRequest{Data: hex.EncodeToString(req.Data)})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.SignResponse{PubKey: toBytes(resp.PubKey), Signature: toBytes(resp.Signature)}, nil
+}
+
+func (attestationServer) Vrf(_ context.Context, req *pb.VrfRequest) (*pb.VrfResponse, error) {
+	resp, err := web.VrfWithAppKey(web.VrfRequest{Alpha: hex.EncodeToString(req.Alpha)})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.VrfResponse{
+		Alpha:     toBytes(resp.Alpha),
+		Output:    toBytes(resp.Output),
+		Proof:     toBytes(resp.Proof),
+		AppPubKey: toBytes(resp.PubKey),
+		AppCert:   toBytes(resp.Cert),
+	}, nil
+}
+
+func (attestationServer) Nostr(_ context.Context, req *pb.NostrEventRequest) (*pb.NostrEvent, error) {
+	tags := make([][]string, 0, len(req.Tags))
+	for _, tag := range req.Tags {
+		tags = append(tags, tag.Values)
+	}
+	e, err := web.SignNostrEvent(web.NostrEventRequest{Kind: int(req.Kind), Tags: tags, Content: req.Content})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	event := &pb.NostrEvent{Id: toBytes(e.Id), Pubkey: toBytes(e.PubKey), CreatedAt: e.CreatedAt, Kind: int32(e.Kind), Content: e.Content, Sig: toBytes(e.Sig)}
+	for _, tag := range e.Tags {
+		event.Tags = append(event.Tags, &pb.NostrTag{Values: tag})
+	}
+	return event, nil
+}
+
+type kvServer struct {
+	pb.UnimplementedKvServer
+}
+
+func (kvServer) Write(_ context.Context, req *pb.WriteKvRequest) (*pb.WriteKvResponse, error) {
+	resp, err := web.WriteKv(web.WriteKvRequest{
+		Key:       req.Key,
+		Value:     req.Value,
+		Provision: hex.EncodeToString(req.Provision),
+		Protected: req.Protected,
+		Overwrite: req.Overwrite,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.WriteKvResponse{Success: resp.Success}, nil
+}
+
+func (kvServer) Read(_ context.Context, req *pb.ReadKvRequest) (*pb.ReadKvResponse, error) {
+	resp, err := web.ReadKv(req.Key)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ReadKvResponse{
+		Present:     resp.Present,
+		Value:       resp.Value,
+		Provisioned: resp.Provisioned,
+		Protected:   resp.Protected,
+		Provisioner: toBytes(resp.Provisioner),
+		Protector:   resp.Protector,
+	}, nil
+}
+
+func (kvServer) Delete(_ context.Context, req *pb.DeleteKvRequest) (*pb.DeleteKvResponse, error) {
+	resp, err := web.DeleteKv(web.DeleteKvRequest{Key: req.Key})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeleteKvResponse{Success: resp.Success}, nil
+}
+
+func (kvServer) Quota(context.Context, *pb.QuotaRequest) (*pb.QuotaResponse, error) {
+	resp := web.GetQuota()
+	return &pb.QuotaResponse{Used: int64(resp.Used), Quota: int64(resp.Quota)}, nil
+}
+
+func toKeyFormats(f web.KeyFormats) *pb.KeyFormats {
+	return &pb.KeyFormats{Compressed: toBytes(f.Compressed), Address: f.Address, XOnly: toBytes(f.XOnly), DidKey: f.DidKey}
+}
+
+// toBytes decodes a hex field of the shared operations, which only return valid hex, empty stays nil
+func toBytes(s string) []byte {
+	decoded, _ := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if len(decoded) == 0 {
+		return nil
+	}
+	return decoded
+}
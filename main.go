@@ -1,22 +1,89 @@
 package main
 
 import (
+	"crypto/tls"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"teerminal/config"
 	"teerminal/docs"
+	"teerminal/grpcapi"
+	"teerminal/service/publisher"
+	"teerminal/service/ratls"
+	"teerminal/service/sensor"
 	"teerminal/web"
 )
 
+// @title Teerminal
+// @description Emulated TEE device API. The /api/v1 endpoints are served in JSON, and in CBOR (RFC 8949) to clients sending Accept: application/cbor, in which certs, keys, signatures and the other hex encoded fields are raw byte strings.
+// @description Requests are accepted in CBOR as well with Content-Type: application/cbor, their byte strings standing for the hex encoded fields of the JSON schemas.
 func main() {
 	// Load the configuration - todo: add flags to specify the config file
 	config.Load("")
+	// Reload the configuration on SIGHUP, derived keys and certs are refreshed on next use
+	go func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		for range hup {
+			if err := config.Reload(""); err != nil {
+				log.Printf("failed to reload config: %v", err)
+			}
+		}
+	}()
+	// Record or replay device sessions, the files are opened once at startup
+	if err := web.SetupRecording(); err != nil {
+		log.Fatalf("failed to open recording: %v", err)
+	}
+	// Publish status and telemetry to the nostr relays, and receive commands from them
+	if relays := config.GetConfig().Relays; len(relays) > 0 {
+		publisher.Start(relays, config.GetPublishInterval())
+	}
+	// Run the simulated sensors
+	if err := sensor.Start(config.GetConfig().Sensors); err != nil {
+		log.Fatalf("failed to start sensors: %v", err)
+	}
+	// Serve the gRPC services next to the HTTP API, over RA-TLS as well if enabled
+	if port := config.GetConfig().GrpcPort; port != "" {
+		listener, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+		var opts []grpc.ServerOption
+		if config.GetConfig().Tls {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(ratls.ServerConfig())))
+		}
+		go func() {
+			if err := grpcapi.NewServer(opts...).Serve(listener); err != nil {
+				log.Printf("gRPC server stopped: %v", err)
+			}
+		}()
+	}
 	engine := gin.Default()
 	docs.SwaggerInfo.BasePath = "/"
 	web.RegisterRoutes(engine)
 	engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 	// Set Listening Port
-	engine.Run(":" + config.GetConfig().Port)
+	if config.GetConfig().Tls {
+		// RA-TLS: the certificate is self-generated at startup and carries the app chain as evidence,
+		// it is regenerated on the next handshake after a config reload
+		if _, err := ratls.GetCertificate(); err != nil {
+			log.Fatalf("failed to generate ra-tls certificate: %v", err)
+		}
+		listener, err := tls.Listen("tcp", ":"+config.GetConfig().Port, ratls.ServerConfig())
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
+		engine.RunListener(listener)
+	} else {
+		engine.Run(":" + config.GetConfig().Port)
+	}
 	select {}
 }
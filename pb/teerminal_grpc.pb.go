@@ -0,0 +1,820 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: teerminal.proto
+
+// gRPC services mirroring the device, attestation and kv endpoints of the HTTP API, see web/.
+// Messages carry the same fields as the JSON responses, but keys, certs, signatures and other binary values are raw bytes
+// where the JSON carries hex; addresses, formats meant for display and free text stay strings.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Device_GetKey_FullMethodName         = "/teerminal.v1.Device/GetKey"
+	Device_GetChallenge_FullMethodName   = "/teerminal.v1.Device/GetChallenge"
+	Device_GetVersion_FullMethodName     = "/teerminal.v1.Device/GetVersion"
+	Device_SignEnrollment_FullMethodName = "/teerminal.v1.Device/SignEnrollment"
+	Device_Enroll_FullMethodName         = "/teerminal.v1.Device/Enroll"
+)
+
+// DeviceClient is the client API for Device service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DeviceClient interface {
+	GetKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*DeviceKey, error)
+	GetChallenge(ctx context.Context, in *ChallengeRequest, opts ...grpc.CallOption) (*Challenge, error)
+	// GetVersion returns the native version attestation, the dcap, nitro and eat formats are served over HTTP only
+	GetVersion(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionAttestation, error)
+	SignEnrollment(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*Enrollment, error)
+	Enroll(ctx context.Context, in *EnrollRequest, opts ...grpc.CallOption) (*StructuredEnrollment, error)
+}
+
+type deviceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeviceClient(cc grpc.ClientConnInterface) DeviceClient {
+	return &deviceClient{cc}
+}
+
+func (c *deviceClient) GetKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*DeviceKey, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeviceKey)
+	err := c.cc.Invoke(ctx, Device_GetKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceClient) GetChallenge(ctx context.Context, in *ChallengeRequest, opts ...grpc.CallOption) (*Challenge, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Challenge)
+	err := c.cc.Invoke(ctx, Device_GetChallenge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceClient) GetVersion(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionAttestation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VersionAttestation)
+	err := c.cc.Invoke(ctx, Device_GetVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceClient) SignEnrollment(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*Enrollment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Enrollment)
+	err := c.cc.Invoke(ctx, Device_SignEnrollment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceClient) Enroll(ctx context.Context, in *EnrollRequest, opts ...grpc.CallOption) (*StructuredEnrollment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StructuredEnrollment)
+	err := c.cc.Invoke(ctx, Device_Enroll_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeviceServer is the server API for Device service.
+// All implementations must embed UnimplementedDeviceServer
+// for forward compatibility.
+type DeviceServer interface {
+	GetKey(context.Context, *KeyRequest) (*DeviceKey, error)
+	GetChallenge(context.Context, *ChallengeRequest) (*Challenge, error)
+	// GetVersion returns the native version attestation, the dcap, nitro and eat formats are served over HTTP only
+	GetVersion(context.Context, *VersionRequest) (*VersionAttestation, error)
+	SignEnrollment(context.Context, *SignRequest) (*Enrollment, error)
+	Enroll(context.Context, *EnrollRequest) (*StructuredEnrollment, error)
+	mustEmbedUnimplementedDeviceServer()
+}
+
+// UnimplementedDeviceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDeviceServer struct{}
+
+func (UnimplementedDeviceServer) GetKey(context.Context, *KeyRequest) (*DeviceKey, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKey not implemented")
+}
+func (UnimplementedDeviceServer) GetChallenge(context.Context, *ChallengeRequest) (*Challenge, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChallenge not implemented")
+}
+func (UnimplementedDeviceServer) GetVersion(context.Context, *VersionRequest) (*VersionAttestation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
+}
+func (UnimplementedDeviceServer) SignEnrollment(context.Context, *SignRequest) (*Enrollment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignEnrollment not implemented")
+}
+func (UnimplementedDeviceServer) Enroll(context.Context, *EnrollRequest) (*StructuredEnrollment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Enroll not implemented")
+}
+func (UnimplementedDeviceServer) mustEmbedUnimplementedDeviceServer() {}
+func (UnimplementedDeviceServer) testEmbeddedByValue()                {}
+
+// UnsafeDeviceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeviceServer will
+// result in compilation errors.
+type UnsafeDeviceServer interface {
+	mustEmbedUnimplementedDeviceServer()
+}
+
+func RegisterDeviceServer(s grpc.ServiceRegistrar, srv DeviceServer) {
+	// If the following call pancis, it indicates UnimplementedDeviceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Device_ServiceDesc, srv)
+}
+
+func _Device_GetKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServer).GetKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Device_GetKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServer).GetKey(ctx, req.(*KeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Device_GetChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServer).GetChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Device_GetChallenge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServer).GetChallenge(ctx, req.(*ChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Device_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServer).GetVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Device_GetVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServer).GetVersion(ctx, req.(*VersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Device_SignEnrollment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServer).SignEnrollment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Device_SignEnrollment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServer).SignEnrollment(ctx, req.(*SignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Device_Enroll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServer).Enroll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Device_Enroll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServer).Enroll(ctx, req.(*EnrollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Device_ServiceDesc is the grpc.ServiceDesc for Device service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Device_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "teerminal.v1.Device",
+	HandlerType: (*DeviceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetKey",
+			Handler:    _Device_GetKey_Handler,
+		},
+		{
+			MethodName: "GetChallenge",
+			Handler:    _Device_GetChallenge_Handler,
+		},
+		{
+			MethodName: "GetVersion",
+			Handler:    _Device_GetVersion_Handler,
+		},
+		{
+			MethodName: "SignEnrollment",
+			Handler:    _Device_SignEnrollment_Handler,
+		},
+		{
+			MethodName: "Enroll",
+			Handler:    _Device_Enroll_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "teerminal.proto",
+}
+
+const (
+	Attestation_GetAppKey_FullMethodName = "/teerminal.v1.Attestation/GetAppKey"
+	Attestation_Sign_FullMethodName      = "/teerminal.v1.Attestation/Sign"
+	Attestation_Vrf_FullMethodName       = "/teerminal.v1.Attestation/Vrf"
+	Attestation_Nostr_FullMethodName     = "/teerminal.v1.Attestation/Nostr"
+)
+
+// AttestationClient is the client API for Attestation service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AttestationClient interface {
+	GetAppKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*ApplicationKey, error)
+	Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error)
+	Vrf(ctx context.Context, in *VrfRequest, opts ...grpc.CallOption) (*VrfResponse, error)
+	Nostr(ctx context.Context, in *NostrEventRequest, opts ...grpc.CallOption) (*NostrEvent, error)
+}
+
+type attestationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAttestationClient(cc grpc.ClientConnInterface) AttestationClient {
+	return &attestationClient{cc}
+}
+
+func (c *attestationClient) GetAppKey(ctx context.Context, in *KeyRequest, opts ...grpc.CallOption) (*ApplicationKey, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplicationKey)
+	err := c.cc.Invoke(ctx, Attestation_GetAppKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attestationClient) Sign(ctx context.Context, in *SignRequest, opts ...grpc.CallOption) (*SignResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignResponse)
+	err := c.cc.Invoke(ctx, Attestation_Sign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attestationClient) Vrf(ctx context.Context, in *VrfRequest, opts ...grpc.CallOption) (*VrfResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VrfResponse)
+	err := c.cc.Invoke(ctx, Attestation_Vrf_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attestationClient) Nostr(ctx context.Context, in *NostrEventRequest, opts ...grpc.CallOption) (*NostrEvent, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NostrEvent)
+	err := c.cc.Invoke(ctx, Attestation_Nostr_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AttestationServer is the server API for Attestation service.
+// All implementations must embed UnimplementedAttestationServer
+// for forward compatibility.
+type AttestationServer interface {
+	GetAppKey(context.Context, *KeyRequest) (*ApplicationKey, error)
+	Sign(context.Context, *SignRequest) (*SignResponse, error)
+	Vrf(context.Context, *VrfRequest) (*VrfResponse, error)
+	Nostr(context.Context, *NostrEventRequest) (*NostrEvent, error)
+	mustEmbedUnimplementedAttestationServer()
+}
+
+// UnimplementedAttestationServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAttestationServer struct{}
+
+func (UnimplementedAttestationServer) GetAppKey(context.Context, *KeyRequest) (*ApplicationKey, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAppKey not implemented")
+}
+func (UnimplementedAttestationServer) Sign(context.Context, *SignRequest) (*SignResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sign not implemented")
+}
+func (UnimplementedAttestationServer) Vrf(context.Context, *VrfRequest) (*VrfResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Vrf not implemented")
+}
+func (UnimplementedAttestationServer) Nostr(context.Context, *NostrEventRequest) (*NostrEvent, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Nostr not implemented")
+}
+func (UnimplementedAttestationServer) mustEmbedUnimplementedAttestationServer() {}
+func (UnimplementedAttestationServer) testEmbeddedByValue()                     {}
+
+// UnsafeAttestationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AttestationServer will
+// result in compilation errors.
+type UnsafeAttestationServer interface {
+	mustEmbedUnimplementedAttestationServer()
+}
+
+func RegisterAttestationServer(s grpc.ServiceRegistrar, srv AttestationServer) {
+	// If the following call pancis, it indicates UnimplementedAttestationServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Attestation_ServiceDesc, srv)
+}
+
+func _Attestation_GetAppKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttestationServer).GetAppKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Attestation_GetAppKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttestationServer).GetAppKey(ctx, req.(*KeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Attestation_Sign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttestationServer).Sign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Attestation_Sign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttestationServer).Sign(ctx, req.(*SignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Attestation_Vrf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VrfRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttestationServer).Vrf(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Attestation_Vrf_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttestationServer).Vrf(ctx, req.(*VrfRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Attestation_Nostr_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NostrEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttestationServer).Nostr(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Attestation_Nostr_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttestationServer).Nostr(ctx, req.(*NostrEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Attestation_ServiceDesc is the grpc.ServiceDesc for Attestation service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Attestation_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "teerminal.v1.Attestation",
+	HandlerType: (*AttestationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAppKey",
+			Handler:    _Attestation_GetAppKey_Handler,
+		},
+		{
+			MethodName: "Sign",
+			Handler:    _Attestation_Sign_Handler,
+		},
+		{
+			MethodName: "Vrf",
+			Handler:    _Attestation_Vrf_Handler,
+		},
+		{
+			MethodName: "Nostr",
+			Handler:    _Attestation_Nostr_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "teerminal.proto",
+}
+
+const (
+	Kv_Write_FullMethodName  = "/teerminal.v1.Kv/Write"
+	Kv_Read_FullMethodName   = "/teerminal.v1.Kv/Read"
+	Kv_Delete_FullMethodName = "/teerminal.v1.Kv/Delete"
+	Kv_Quota_FullMethodName  = "/teerminal.v1.Kv/Quota"
+)
+
+// KvClient is the client API for Kv service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KvClient interface {
+	Write(ctx context.Context, in *WriteKvRequest, opts ...grpc.CallOption) (*WriteKvResponse, error)
+	Read(ctx context.Context, in *ReadKvRequest, opts ...grpc.CallOption) (*ReadKvResponse, error)
+	Delete(ctx context.Context, in *DeleteKvRequest, opts ...grpc.CallOption) (*DeleteKvResponse, error)
+	Quota(ctx context.Context, in *QuotaRequest, opts ...grpc.CallOption) (*QuotaResponse, error)
+}
+
+type kvClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKvClient(cc grpc.ClientConnInterface) KvClient {
+	return &kvClient{cc}
+}
+
+func (c *kvClient) Write(ctx context.Context, in *WriteKvRequest, opts ...grpc.CallOption) (*WriteKvResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriteKvResponse)
+	err := c.cc.Invoke(ctx, Kv_Write_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kvClient) Read(ctx context.Context, in *ReadKvRequest, opts ...grpc.CallOption) (*ReadKvResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadKvResponse)
+	err := c.cc.Invoke(ctx, Kv_Read_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kvClient) Delete(ctx context.Context, in *DeleteKvRequest, opts ...grpc.CallOption) (*DeleteKvResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteKvResponse)
+	err := c.cc.Invoke(ctx, Kv_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kvClient) Quota(ctx context.Context, in *QuotaRequest, opts ...grpc.CallOption) (*QuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QuotaResponse)
+	err := c.cc.Invoke(ctx, Kv_Quota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// KvServer is the server API for Kv service.
+// All implementations must embed UnimplementedKvServer
+// for forward compatibility.
+type KvServer interface {
+	Write(context.Context, *WriteKvRequest) (*WriteKvResponse, error)
+	Read(context.Context, *ReadKvRequest) (*ReadKvResponse, error)
+	Delete(context.Context, *DeleteKvRequest) (*DeleteKvResponse, error)
+	Quota(context.Context, *QuotaRequest) (*QuotaResponse, error)
+	mustEmbedUnimplementedKvServer()
+}
+
+// UnimplementedKvServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedKvServer struct{}
+
+func (UnimplementedKvServer) Write(context.Context, *WriteKvRequest) (*WriteKvResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Write not implemented")
+}
+func (UnimplementedKvServer) Read(context.Context, *ReadKvRequest) (*ReadKvResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedKvServer) Delete(context.Context, *DeleteKvRequest) (*DeleteKvResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedKvServer) Quota(context.Context, *QuotaRequest) (*QuotaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Quota not implemented")
+}
+func (UnimplementedKvServer) mustEmbedUnimplementedKvServer() {}
+func (UnimplementedKvServer) testEmbeddedByValue()            {}
+
+// UnsafeKvServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KvServer will
+// result in compilation errors.
+type UnsafeKvServer interface {
+	mustEmbedUnimplementedKvServer()
+}
+
+func RegisterKvServer(s grpc.ServiceRegistrar, srv KvServer) {
+	// If the following call pancis, it indicates UnimplementedKvServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Kv_ServiceDesc, srv)
+}
+
+func _Kv_Write_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteKvRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KvServer).Write(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kv_Write_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KvServer).Write(ctx, req.(*WriteKvRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kv_Read_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadKvRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KvServer).Read(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kv_Read_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KvServer).Read(ctx, req.(*ReadKvRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kv_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteKvRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KvServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kv_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KvServer).Delete(ctx, req.(*DeleteKvRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kv_Quota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KvServer).Quota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Kv_Quota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KvServer).Quota(ctx, req.(*QuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Kv_ServiceDesc is the grpc.ServiceDesc for Kv service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Kv_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "teerminal.v1.Kv",
+	HandlerType: (*KvServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Write",
+			Handler:    _Kv_Write_Handler,
+		},
+		{
+			MethodName: "Read",
+			Handler:    _Kv_Read_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Kv_Delete_Handler,
+		},
+		{
+			MethodName: "Quota",
+			Handler:    _Kv_Quota_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "teerminal.proto",
+}
+
+const (
+	Events_Subscribe_FullMethodName = "/teerminal.v1.Events/Subscribe"
+)
+
+// EventsClient is the client API for Events service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EventsClient interface {
+	// Subscribe streams the sensor readings, kv changes and config changes until the client cancels,
+	// the stream is aborted if the client falls behind
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type eventsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEventsClient(cc grpc.ClientConnInterface) EventsClient {
+	return &eventsClient{cc}
+}
+
+func (c *eventsClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Events_ServiceDesc.Streams[0], Events_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Events_SubscribeClient = grpc.ServerStreamingClient[Event]
+
+// EventsServer is the server API for Events service.
+// All implementations must embed UnimplementedEventsServer
+// for forward compatibility.
+type EventsServer interface {
+	// Subscribe streams the sensor readings, kv changes and config changes until the client cancels,
+	// the stream is aborted if the client falls behind
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedEventsServer()
+}
+
+// UnimplementedEventsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEventsServer struct{}
+
+func (UnimplementedEventsServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedEventsServer) mustEmbedUnimplementedEventsServer() {}
+func (UnimplementedEventsServer) testEmbeddedByValue()                {}
+
+// UnsafeEventsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EventsServer will
+// result in compilation errors.
+type UnsafeEventsServer interface {
+	mustEmbedUnimplementedEventsServer()
+}
+
+func RegisterEventsServer(s grpc.ServiceRegistrar, srv EventsServer) {
+	// If the following call pancis, it indicates UnimplementedEventsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Events_ServiceDesc, srv)
+}
+
+func _Events_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventsServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Events_SubscribeServer = grpc.ServerStreamingServer[Event]
+
+// Events_ServiceDesc is the grpc.ServiceDesc for Events service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Events_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "teerminal.v1.Events",
+	HandlerType: (*EventsServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Events_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "teerminal.proto",
+}
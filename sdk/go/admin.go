@@ -0,0 +1,56 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"teerminal/constants"
+	"teerminal/service/fault"
+)
+
+// admin calls an admin endpoint with the admin token
+func (c *Client) admin(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) error {
+	if c.AdminToken == "" {
+		return constants.ErrorMissingAdminToken
+	}
+	header := http.Header{"Authorization": {"Bearer " + c.AdminToken}}
+	return c.do(ctx, method, path, query, body, out, header)
+}
+
+// FaultProfile gets the active fault injection profile
+func (c *Client) FaultProfile(ctx context.Context) (*fault.Profile, error) {
+	var resp fault.Profile
+	if err := c.admin(ctx, http.MethodGet, "/api/v1/admin/fault", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetFaultProfile replaces the active fault injection profile
+func (c *Client) SetFaultProfile(ctx context.Context, profile fault.Profile) (*fault.Profile, error) {
+	var resp fault.Profile
+	if err := c.admin(ctx, http.MethodPut, "/api/v1/admin/fault", nil, profile, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ResetFaultProfile clears the active fault injection profile
+func (c *Client) ResetFaultProfile(ctx context.Context) (*fault.Profile, error) {
+	var resp fault.Profile
+	if err := c.admin(ctx, http.MethodDelete, "/api/v1/admin/fault", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FaultEvents gets the injected fault events with an id greater than since
+func (c *Client) FaultEvents(ctx context.Context, since uint64) ([]fault.Event, error) {
+	var resp []fault.Event
+	query := url.Values{"since": {strconv.FormatUint(since, 10)}}
+	if err := c.admin(ctx, http.MethodGet, "/api/v1/admin/fault/events", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,97 @@
+package sdk
+
+import (
+	"context"
+	"net/url"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/nostr"
+	"teerminal/web"
+)
+
+// AppKey gets the app key with the optional key formats, and verifies the app chain
+func (c *Client) AppKey(ctx context.Context, formats string) (*web.ApplicationKey, *VerifiedKey, error) {
+	var resp web.ApplicationKey
+	query := url.Values{}
+	if formats != "" {
+		query.Set("formats", formats)
+	}
+	if err := c.get(ctx, "/api/v1/attestation/appkey", query, &resp); err != nil {
+		return nil, nil, err
+	}
+	key, err := c.verifyChain(resp.Cert, resp.PubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, key, nil
+}
+
+// Sign gets the app key signature over keccak256(data), and verifies it against the verified app key
+func (c *Client) Sign(ctx context.Context, data []byte) (*web.SignResponse, error) {
+	_, key, err := c.AppKey(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	var resp web.SignResponse
+	if err := c.post(ctx, "/api/v1/attestation/sign", web.SignRequest{Data: encodeHex(data)}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.PubKey != encodeHex(key.PubKey) {
+		return nil, constants.ErrorChainLeafMismatch
+	}
+	if err := verifySignature(key.PubKey, data, resp.Signature); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Nostr gets a NIP-01 event signed by the app key, and verifies its id, its signature, and the chain tag against the
+// vendor root, whose leaf must be the event pubkey
+func (c *Client) Nostr(ctx context.Context, req web.NostrEventRequest) (*nostr.Event, *VerifiedKey, error) {
+	var resp nostr.Event
+	if err := c.post(ctx, "/api/v1/attestation/nostr", req, &resp); err != nil {
+		return nil, nil, err
+	}
+	if err := nostr.Verify(resp); err != nil {
+		return nil, nil, err
+	}
+	var chain string
+	for _, tag := range resp.Tags {
+		if len(tag) == 2 && tag[0] == constants.NostrChainTag {
+			chain = tag[1]
+		}
+	}
+	key, err := c.verifyChain(chain, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	xOnly, err := encryption.XOnlyPublicKey(key.PubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.PubKey != encodeHex(xOnly) {
+		return nil, nil, constants.ErrorChainLeafMismatch
+	}
+	return &resp, key, nil
+}
+
+// Vrf gets the VRF output and proof over alpha, and verifies the proof against the verified app chain
+func (c *Client) Vrf(ctx context.Context, alpha []byte) (*web.VrfResponse, []byte, error) {
+	var resp web.VrfResponse
+	if err := c.post(ctx, "/api/v1/attestation/vrf", web.VrfRequest{Alpha: encodeHex(alpha)}, &resp); err != nil {
+		return nil, nil, err
+	}
+	key, err := c.verifyChain(resp.Cert, resp.PubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := decodeHex(resp.Proof)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err := encryption.VrfVerify(key.PubKey, alpha, proof)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, output, nil
+}
@@ -0,0 +1,103 @@
+// Package sdk is a typed client of the teerminal HTTP API
+// Responses are the web structs, and keys, chains and signatures returned by the device are verified against a pinned vendor root
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"teerminal/constants"
+	"teerminal/web"
+)
+
+// ApiError is an error response of the api
+type ApiError struct {
+	Status  int
+	Message string
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("teerminal: %d %s", e.Status, e.Message)
+}
+
+type Client struct {
+	BaseUrl    string       // BaseUrl is the url of the device, e.g. http://127.0.0.1:4100
+	VendorRoot []byte       // VendorRoot is the pinned 64 bytes vendor root public key, chains are verified against it
+	AdminToken string       // AdminToken is the bearer token of the admin api, only needed for the admin methods
+	HttpClient *http.Client // HttpClient sends the requests, e.g. an RA-TLS client from service/ratls
+}
+
+// NewClient returns a client of the device at baseUrl, pinned to the vendor root public key
+func NewClient(baseUrl string, vendorRoot []byte) *Client {
+	return &Client{
+		BaseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		VendorRoot: vendorRoot,
+		HttpClient: http.DefaultClient,
+	}
+}
+
+// do sends the request, body is sent as json if it is not nil, and the response is decoded into out
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}, header http.Header) error {
+	u := c.BaseUrl + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e web.ErrorResponse
+		json.NewDecoder(resp.Body).Decode(&e)
+		return &ApiError{Status: resp.StatusCode, Message: e.Error}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, out, nil)
+}
+
+func (c *Client) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	return c.do(ctx, http.MethodPost, path, nil, body, out, nil)
+}
+
+func decodeHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, constants.ErrorInvalidHexResponse
+	}
+	return b, nil
+}
+
+func encodeHex(b []byte) string {
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,206 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/fault"
+	"teerminal/web"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testVendorRoot = "dbbe0cd0b4c7bc4ab34829c96f35bb0011d06dc3bdf0b900401a71a8f7c4c471"
+	testRootKey    = "cd2f10b3d7d306a27199ccf51868c1b0859f824b6fab53710f06a092ae40226f"
+	testAdminToken = "admin"
+)
+
+var testServer *httptest.Server
+
+func TestMain(m *testing.M) {
+	dataDir, err := os.MkdirTemp("", "teerminal-sdk")
+	if err != nil {
+		panic(err)
+	}
+	config.Set(&config.Config{
+		Version:            "0.0.1-test",
+		TeePlatformVersion: 1,
+		VendorRoot:         testVendorRoot,
+		RootKey:            testRootKey,
+		AppName:            "EmulatorDefault",
+		DataDir:            dataDir,
+		AdminToken:         testAdminToken,
+	})
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	web.RegisterRoutes(router)
+	testServer = httptest.NewServer(router)
+	code := m.Run()
+	testServer.Close()
+	os.RemoveAll(dataDir)
+	os.Exit(code)
+}
+
+func newTestClient(t *testing.T) *Client {
+	c := NewClient(testServer.URL, encryption.GetPublicKey(config.GetVendorRoot()))
+	c.AdminToken = testAdminToken
+	return c
+}
+
+// withFaults injects the faults of rule into the responses until the test ends
+func withFaults(t *testing.T, c *Client, rule fault.Rule) {
+	if _, err := c.SetFaultProfile(context.Background(), fault.Profile{Rules: []fault.Rule{rule}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		c.ResetFaultProfile(context.Background())
+	})
+}
+
+func TestChainVerification(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+	device, deviceKey, err := c.DeviceKey(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deviceKey.Certs) != constants.DeviceChainCerts || encodeHex(deviceKey.PubKey) != device.PubKey {
+		t.Errorf("unexpected device key %x with %d certs", deviceKey.PubKey, len(deviceKey.Certs))
+	}
+	app, appKey, err := c.AppKey(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(appKey.Certs) != constants.AppChainCerts || encodeHex(appKey.PubKey) != app.PubKey {
+		t.Errorf("unexpected app key %x with %d certs", appKey.PubKey, len(appKey.Certs))
+	}
+
+	t.Run("wrong vendor root", func(t *testing.T) {
+		other := newTestClient(t)
+		other.VendorRoot = encryption.GetPublicKey(config.GetRootKey())
+		if _, _, err := other.DeviceKey(ctx, ""); !errors.Is(err, constants.ErrorInvalidCertDerivation) {
+			t.Errorf("expected %v, got %v", constants.ErrorInvalidCertDerivation, err)
+		}
+	})
+	t.Run("missing vendor root", func(t *testing.T) {
+		other := newTestClient(t)
+		other.VendorRoot = nil
+		if _, _, err := other.AppKey(ctx, ""); !errors.Is(err, constants.ErrorMissingVendorRoot) {
+			t.Errorf("expected %v, got %v", constants.ErrorMissingVendorRoot, err)
+		}
+	})
+	t.Run("corrupted cert", func(t *testing.T) {
+		withFaults(t, c, fault.Rule{Endpoint: "/api/v1/attestation/appkey", CorruptCertRate: 1})
+		if _, _, err := c.AppKey(ctx, ""); !errors.Is(err, constants.ErrorInvalidCert) {
+			t.Errorf("expected %v, got %v", constants.ErrorInvalidCert, err)
+		}
+	})
+	t.Run("truncated chain", func(t *testing.T) {
+		withFaults(t, c, fault.Rule{Endpoint: "/api/v1/device/key", TruncateChainRate: 1})
+		if _, _, err := c.DeviceKey(ctx, ""); err == nil {
+			t.Error("expected truncated chain to fail")
+		}
+	})
+}
+
+func TestVersionAttestation(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+	requesterKey := encryption.DerivePrivateKey([]byte("requester"), []byte("sdk"))
+	attestation, check, err := c.VersionAttestation(ctx, requesterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !check.Valid || attestation.AttestationVer != "0.0.1-test" || attestation.TeePlatformVer != 1 {
+		t.Errorf("unexpected attestation %+v, diagnostics %+v", attestation, check)
+	}
+
+	t.Run("flipped signature", func(t *testing.T) {
+		withFaults(t, c, fault.Rule{Endpoint: "/api/v1/device/version", FlipSignatureRate: 1})
+		_, check, err := c.VersionAttestation(ctx, requesterKey)
+		if err == nil || check == nil || check.Valid {
+			t.Errorf("expected invalid attestation, got %v", err)
+		}
+	})
+	t.Run("downgraded platform", func(t *testing.T) {
+		withFaults(t, c, fault.Rule{Endpoint: "/api/v1/device/version", DowngradeRate: 1})
+		_, check, err := c.VersionAttestation(ctx, requesterKey)
+		if err == nil || check == nil || check.Valid {
+			t.Errorf("expected invalid attestation, got %v", err)
+		}
+	})
+}
+
+func TestProvisionedKv(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+	provisionerKey := encryption.DerivePrivateKey([]byte("provisioner"), []byte("sdk"))
+	resp, err := c.WriteProvisionedKv(ctx, provisionerKey, web.WriteKvRequest{Key: "provisioned", Value: "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success {
+		t.Error("expected the provisioned write to succeed")
+	}
+	read, err := c.ReadKv(ctx, "provisioned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Value != "value" || !read.Provisioned || read.Provisioner != encodeHex(encryption.GetPublicKey(provisionerKey)) {
+		t.Errorf("unexpected read %+v", read)
+	}
+
+	t.Run("provision for another value", func(t *testing.T) {
+		_, appKey, err := c.AppKey(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		provision, err := SignProvision(provisionerKey, appKey.PubKey, "other", "signed value")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.WriteKv(ctx, web.WriteKvRequest{Key: "other", Value: "written value", Provision: provision})
+		var apiErr *ApiError
+		if !errors.As(err, &apiErr) || apiErr.Message != constants.MsgErrorFailedProvisionVerification {
+			t.Errorf("expected %q, got %v", constants.MsgErrorFailedProvisionVerification, err)
+		}
+	})
+	t.Run("provision of the former length", func(t *testing.T) {
+		provision := encodeHex(make([]byte, constants.ProvisionLength-1))
+		_, err := c.WriteKv(ctx, web.WriteKvRequest{Key: "short", Value: "value", Provision: provision})
+		var apiErr *ApiError
+		if !errors.As(err, &apiErr) || apiErr.Message != constants.MsgErrorInvalidProvisionLength {
+			t.Errorf("expected %q, got %v", constants.MsgErrorInvalidProvisionLength, err)
+		}
+	})
+}
+
+func TestCounter(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t)
+	created, err := c.CreateCounter(ctx, "sdk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	incremented, err := c.IncrementCounter(ctx, "sdk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := c.ReadCounter(ctx, "sdk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Value != 0 || incremented.Value != 1 || read.Value != 1 {
+		t.Errorf("unexpected values %d, %d, %d", created.Value, incremented.Value, read.Value)
+	}
+	// Every call signs over its own nonce
+	if read.Nonce == "" || read.Nonce == incremented.Nonce {
+		t.Errorf("expected fresh nonces, got %q and %q", incremented.Nonce, read.Nonce)
+	}
+}
@@ -0,0 +1,52 @@
+package sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"net/url"
+	"teerminal/constants"
+	"teerminal/web"
+)
+
+// counter calls a counter endpoint with a fresh nonce, and verifies the counter signature over it against the verified app key
+func (c *Client) counter(ctx context.Context, method string, path string, name string) (*web.CounterResponse, error) {
+	_, appKey, err := c.AppKey(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	var resp web.CounterResponse
+	if method == "GET" {
+		err = c.get(ctx, path, url.Values{"name": {name}, "nonce": {encodeHex(nonce)}}, &resp)
+	} else {
+		err = c.post(ctx, path, web.CounterRequest{Name: name, Nonce: encodeHex(nonce)}, &resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.PubKey != encodeHex(appKey.PubKey) {
+		return nil, constants.ErrorChainLeafMismatch
+	}
+	if err := verifySignature(appKey.PubKey, web.CounterSignable(resp.Name, resp.Value, nonce), resp.Signature); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateCounter creates a monotonic counter
+func (c *Client) CreateCounter(ctx context.Context, name string) (*web.CounterResponse, error) {
+	return c.counter(ctx, "POST", "/api/v1/counter/create", name)
+}
+
+// IncrementCounter increments a monotonic counter
+func (c *Client) IncrementCounter(ctx context.Context, name string) (*web.CounterResponse, error) {
+	return c.counter(ctx, "POST", "/api/v1/counter/increment", name)
+}
+
+// ReadCounter reads a monotonic counter
+func (c *Client) ReadCounter(ctx context.Context, name string) (*web.CounterResponse, error) {
+	return c.counter(ctx, "GET", "/api/v1/counter/read", name)
+}
@@ -0,0 +1,232 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/enrollment"
+	"teerminal/web"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// VerifiedKey is a public key whose cert chain was verified against the vendor root
+type VerifiedKey struct {
+	PubKey []byte // PubKey is the 64 bytes public key, the leaf of Chain
+	Chain  []byte // Chain is the concatenated 257 bytes certs
+	Certs  []encryption.Cert
+}
+
+// verifyChain verifies the chain against the pinned vendor root, and that its leaf is the public key if it is not empty
+func (c *Client) verifyChain(chainHex string, pubKeyHex string) (*VerifiedKey, error) {
+	if len(c.VendorRoot) == 0 {
+		return nil, constants.ErrorMissingVendorRoot
+	}
+	chain, err := decodeHex(chainHex)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := decodeHex(pubKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := encryption.VerifyCertChain(chain, c.VendorRoot)
+	if err != nil {
+		return nil, err
+	}
+	if pubKeyHex != "" && !bytes.Equal(leaf, pubKey) {
+		return nil, constants.ErrorChainLeafMismatch
+	}
+	key := &VerifiedKey{PubKey: leaf, Chain: chain}
+	for i := 0; i < len(chain); i += encryption.CertLength {
+		cert, err := encryption.UnpackCert(chain[i : i+encryption.CertLength])
+		if err != nil {
+			return nil, err
+		}
+		key.Certs = append(key.Certs, cert)
+	}
+	return key, nil
+}
+
+// verifySignature checks the hex signature over data against the public key
+func verifySignature(pubKey []byte, data []byte, signatureHex string) error {
+	signature, err := decodeHex(signatureHex)
+	if err != nil {
+		return err
+	}
+	if !encryption.VerifySignature(pubKey, data, signature) {
+		return constants.ErrorSignatureMismatch
+	}
+	return nil
+}
+
+// DeviceKey gets the device key with the optional key formats, and verifies the device chain
+func (c *Client) DeviceKey(ctx context.Context, formats string) (*web.DeviceKey, *VerifiedKey, error) {
+	var resp web.DeviceKey
+	query := url.Values{}
+	if formats != "" {
+		query.Set("formats", formats)
+	}
+	if err := c.get(ctx, "/api/v1/device/key", query, &resp); err != nil {
+		return nil, nil, err
+	}
+	key, err := c.verifyChain(resp.Cert, resp.PubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, key, nil
+}
+
+// Challenge gets a single-use nonce for version attestation
+func (c *Client) Challenge(ctx context.Context) (*web.Challenge, []byte, error) {
+	var resp web.Challenge
+	if err := c.get(ctx, "/api/v1/device/challenge", nil, &resp); err != nil {
+		return nil, nil, err
+	}
+	nonce, err := decodeHex(resp.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, nonce, nil
+}
+
+// SignEnrollment gets the device signature over the enrollment data, and verifies it against the verified device chain
+func (c *Client) SignEnrollment(ctx context.Context, data []byte) (*web.Enrollment, *enrollment.Verified, error) {
+	_, key, err := c.DeviceKey(ctx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var resp web.Enrollment
+	if err := c.post(ctx, "/api/v1/device/sign", web.SignRequest{Data: encodeHex(data)}, &resp); err != nil {
+		return nil, nil, err
+	}
+	deviceKey, err := decodeHex(resp.DeviceKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := decodeHex(resp.Signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	verified, err := enrollment.Verify(key.Chain, c.VendorRoot, deviceKey, data, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, &verified, nil
+}
+
+// Enroll gets a structured enrollment signed by the device, and verifies it against the verified device chain
+func (c *Client) Enroll(ctx context.Context, req web.EnrollRequest) (*web.StructuredEnrollment, *enrollment.Verified, error) {
+	_, key, err := c.DeviceKey(ctx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var resp web.StructuredEnrollment
+	if err := c.post(ctx, "/api/v1/device/enroll", req, &resp); err != nil {
+		return nil, nil, err
+	}
+	deviceKey, err := decodeHex(resp.DeviceKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := decodeHex(resp.Signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Verify over the requested fields, not the echoed ones
+	fields := enrollment.Fields{
+		ChainId:  req.ChainId,
+		Registry: common.HexToAddress(req.Registry),
+		Owner:    common.HexToAddress(req.Owner),
+		Deadline: req.Deadline,
+	}
+	verified, err := enrollment.VerifyStructured(key.Chain, c.VendorRoot, deviceKey, req.Encoding, fields, signature, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, &verified, nil
+}
+
+// VersionAttestation requests a version attestation with a fresh nonce, signing the request with the requester key,
+// and verifies the device chain, the measurement event log and the device signature
+func (c *Client) VersionAttestation(ctx context.Context, requesterKey []byte) (*web.Attestation, *web.VerifyAttestationResponse, error) {
+	if len(c.VendorRoot) == 0 {
+		return nil, nil, constants.ErrorMissingVendorRoot
+	}
+	_, nonce, err := c.Challenge(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubKey := encryption.GetPublicKey(requesterKey)
+	request := append(append([]byte{}, nonce...), pubKey...)
+	signature, err := encryption.Sign(requesterKey, request)
+	if err != nil {
+		return nil, nil, err
+	}
+	var resp web.Attestation
+	query := url.Values{"attestation": {encodeHex(append(request, signature...))}}
+	if err := c.get(ctx, "/api/v1/device/version", query, &resp); err != nil {
+		return nil, nil, err
+	}
+	check, err := web.CheckAttestation(nonce, pubKey, resp, c.VendorRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !check.Valid {
+		return &resp, &check, fmt.Errorf("%w: %s", constants.ErrorAttestationInvalid, check.Error)
+	}
+	return &resp, &check, nil
+}
+
+// DcapQuote requests an emulated SGX DCAP quote of version 3 or 4 with a fresh nonce
+func (c *Client) DcapQuote(ctx context.Context, quoteVersion int) (*web.DcapQuote, error) {
+	_, nonce, err := c.Challenge(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var resp web.DcapQuote
+	query := url.Values{"format": {web.AttestationFormatDcap}, "nonce": {encodeHex(nonce)}, "quoteVersion": {strconv.Itoa(quoteVersion)}}
+	if err := c.get(ctx, "/api/v1/device/version", query, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// NitroDocument requests an emulated Nitro Enclaves attestation document with a fresh nonce, userData and publicKey are optional
+func (c *Client) NitroDocument(ctx context.Context, userData []byte, publicKey []byte) (*web.NitroDocument, error) {
+	_, nonce, err := c.Challenge(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{"format": {web.AttestationFormatNitro}, "nonce": {encodeHex(nonce)}}
+	if userData != nil {
+		query.Set("userData", encodeHex(userData))
+	}
+	if publicKey != nil {
+		query.Set("publicKey", encodeHex(publicKey))
+	}
+	var resp web.NitroDocument
+	if err := c.get(ctx, "/api/v1/device/version", query, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Eat requests an Entity Attestation Token with a fresh nonce, encoding is jwt or cwt
+func (c *Client) Eat(ctx context.Context, encoding string) (*web.EatToken, error) {
+	_, nonce, err := c.Challenge(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var resp web.EatToken
+	query := url.Values{"format": {web.AttestationFormatEat}, "nonce": {encodeHex(nonce)}, "encoding": {encoding}}
+	if err := c.get(ctx, "/api/v1/device/version", query, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,49 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"teerminal/web"
+)
+
+// InitiateHandshake asks the device to run a mutual attestation handshake with the peer device at peerUrl,
+// root is the peer's vendor root, nil to use the device's trusted vendor roots, it requires the admin token
+func (c *Client) InitiateHandshake(ctx context.Context, peerUrl string, root []byte) (*web.HandshakeSession, error) {
+	var resp web.HandshakeSession
+	req := web.InitiateHandshakeRequest{Peer: peerUrl}
+	if root != nil {
+		req.Root = encodeHex(root)
+	}
+	if err := c.admin(ctx, http.MethodPost, "/api/v1/handshake/initiate", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RespondHandshake sends the initiator's side of a handshake, it is normally called by a peer device
+func (c *Client) RespondHandshake(ctx context.Context, req web.RespondHandshakeRequest) (*web.RespondHandshakeResponse, error) {
+	var resp web.RespondHandshakeResponse
+	if err := c.post(ctx, "/api/v1/handshake/respond", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ConfirmHandshake completes a handshake, it is normally called by a peer device
+func (c *Client) ConfirmHandshake(ctx context.Context, req web.ConfirmHandshakeRequest) (*web.ConfirmHandshakeResponse, error) {
+	var resp web.ConfirmHandshakeResponse
+	if err := c.post(ctx, "/api/v1/handshake/confirm", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// HandshakeSession gets a handshake session with its session key by its id, it requires the admin token
+func (c *Client) HandshakeSession(ctx context.Context, sessionId string) (*web.HandshakeSession, error) {
+	var resp web.HandshakeSession
+	if err := c.admin(ctx, http.MethodGet, "/api/v1/handshake/session", url.Values{"id": {sessionId}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
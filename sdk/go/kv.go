@@ -0,0 +1,66 @@
+package sdk
+
+import (
+	"context"
+	"net/url"
+	"teerminal/service/encryption"
+	"teerminal/web"
+)
+
+// SignProvision signs the key-value pair with the provisioner key for the device with the app public key,
+// and returns the Provision field of web.WriteKvRequest
+func SignProvision(provisionerKey []byte, appPubKey []byte, key string, value string) (string, error) {
+	signature, err := encryption.Sign(provisionerKey, web.ProvisionSignable(appPubKey, key, value))
+	if err != nil {
+		return "", err
+	}
+	return encodeHex(append(encryption.GetPublicKey(provisionerKey), signature...)), nil
+}
+
+// WriteKv writes a key-value pair
+func (c *Client) WriteKv(ctx context.Context, req web.WriteKvRequest) (*web.WriteKvResponse, error) {
+	var resp web.WriteKvResponse
+	if err := c.post(ctx, "/api/v1/kv/write", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// WriteProvisionedKv writes a key-value pair provisioned by the provisioner key, signed for the verified app key
+func (c *Client) WriteProvisionedKv(ctx context.Context, provisionerKey []byte, req web.WriteKvRequest) (*web.WriteKvResponse, error) {
+	_, appKey, err := c.AppKey(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if req.Provision, err = SignProvision(provisionerKey, appKey.PubKey, req.Key, req.Value); err != nil {
+		return nil, err
+	}
+	return c.WriteKv(ctx, req)
+}
+
+// ReadKv reads a key-value pair
+func (c *Client) ReadKv(ctx context.Context, key string) (*web.ReadKvResponse, error) {
+	var resp web.ReadKvResponse
+	if err := c.get(ctx, "/api/v1/kv/read", url.Values{"key": {key}}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteKv deletes a key-value pair
+func (c *Client) DeleteKv(ctx context.Context, key string) (*web.DeleteKvResponse, error) {
+	var resp web.DeleteKvResponse
+	if err := c.post(ctx, "/api/v1/kv/delete", web.DeleteKvRequest{Key: key}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Quota gets the used and total kv quota
+func (c *Client) Quota(ctx context.Context) (*web.QuotaResponse, error) {
+	var resp web.QuotaResponse
+	if err := c.get(ctx, "/api/v1/kv/quota", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,26 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"teerminal/web"
+)
+
+// ExtendMeasurement extends a measurement register with data, it needs the admin token
+func (c *Client) ExtendMeasurement(ctx context.Context, register int, data []byte, description string) (*web.ExtendMeasurementResponse, error) {
+	var resp web.ExtendMeasurementResponse
+	req := web.ExtendMeasurementRequest{Register: register, Data: encodeHex(data), Description: description}
+	if err := c.admin(ctx, http.MethodPost, "/api/v1/measurement/extend", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Measurements gets the measurement registers and their event log
+func (c *Client) Measurements(ctx context.Context) (*web.Measurements, error) {
+	var resp web.Measurements
+	if err := c.get(ctx, "/api/v1/measurement/registers", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,120 @@
+package sdk
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/sensor"
+	"teerminal/web"
+)
+
+// Sensors gets the running simulated sensors
+func (c *Client) Sensors(ctx context.Context) ([]config.Sensor, error) {
+	var resp []config.Sensor
+	if err := c.get(ctx, "/api/v1/sensor/list", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// verifyReadings checks the signature of every reading against the verified app key, and that the sequences increase
+func verifyReadings(appKey *VerifiedKey, since uint64, readings []sensor.Reading) error {
+	last := since
+	for _, r := range readings {
+		if r.Sequence <= last {
+			return constants.ErrorSequenceOutOfOrder
+		}
+		last = r.Sequence
+		if err := sensor.Verify(r, appKey.PubKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Readings gets the readings with a sequence greater than since, of the sensor if it is not empty,
+// and verifies their signatures against the verified app key and their order
+func (c *Client) Readings(ctx context.Context, since uint64, name string) ([]sensor.Reading, error) {
+	_, appKey, err := c.AppKey(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{"since": {strconv.FormatUint(since, 10)}}
+	if name != "" {
+		query.Set("sensor", name)
+	}
+	var resp []sensor.Reading
+	if err := c.get(ctx, "/api/v1/sensor/readings", query, &resp); err != nil {
+		return nil, err
+	}
+	if err := verifyReadings(appKey, since, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// StreamReadings streams the readings with a sequence greater than since, of the sensor if it is not empty,
+// and calls fn with every reading after verifying its signature and order, until fn returns an error,
+// the context is done or the stream is closed. It returns the sequence of the last reading, to resume from
+func (c *Client) StreamReadings(ctx context.Context, since uint64, name string, fn func(sensor.Reading) error) (uint64, error) {
+	_, appKey, err := c.AppKey(ctx, "")
+	if err != nil {
+		return since, err
+	}
+	query := url.Values{"since": {strconv.FormatUint(since, 10)}}
+	if name != "" {
+		query.Set("sensor", name)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseUrl+"/api/v1/sensor/stream?"+query.Encode(), nil)
+	if err != nil {
+		return since, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return since, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e web.ErrorResponse
+		json.NewDecoder(resp.Body).Decode(&e)
+		return since, &ApiError{Status: resp.StatusCode, Message: e.Error}
+	}
+	last := since
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data:") {
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			continue
+		}
+		// Events are dispatched on a blank line
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+		var r sensor.Reading
+		if err := json.Unmarshal([]byte(data.String()), &r); err != nil {
+			return last, err
+		}
+		data.Reset()
+		if err := verifyReadings(appKey, last, []sensor.Reading{r}); err != nil {
+			return last, err
+		}
+		last = r.Sequence
+		if err := fn(r); err != nil {
+			return last, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return last, err
+	}
+	return last, scanner.Err()
+}
@@ -0,0 +1,51 @@
+package sdk
+
+import (
+	"context"
+	"teerminal/web"
+)
+
+// VerifySignature asks the device to diagnose a signature
+func (c *Client) VerifySignature(ctx context.Context, req web.VerifySignatureRequest) (*web.SignatureDiagnostics, error) {
+	var resp web.SignatureDiagnostics
+	if err := c.post(ctx, "/api/v1/verify/signature", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// VerifyChain asks the device to diagnose a cert chain
+func (c *Client) VerifyChain(ctx context.Context, req web.VerifyChainRequest) (*web.ChainDiagnostics, error) {
+	var resp web.ChainDiagnostics
+	if err := c.post(ctx, "/api/v1/verify/chain", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// VerifyAttestation asks the device to diagnose a version attestation
+func (c *Client) VerifyAttestation(ctx context.Context, req web.VerifyAttestationRequest) (*web.VerifyAttestationResponse, error) {
+	var resp web.VerifyAttestationResponse
+	if err := c.post(ctx, "/api/v1/verify/attestation", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// VerifyEnrollment asks the device to diagnose an enrollment
+func (c *Client) VerifyEnrollment(ctx context.Context, req web.VerifyEnrollmentRequest) (*web.VerifyEnrollmentResponse, error) {
+	var resp web.VerifyEnrollmentResponse
+	if err := c.post(ctx, "/api/v1/verify/enrollment", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// VerifyVrf asks the device to verify a VRF proof
+func (c *Client) VerifyVrf(ctx context.Context, req web.VerifyVrfRequest) (*web.VerifyVrfResponse, error) {
+	var resp web.VerifyVrfResponse
+	if err := c.post(ctx, "/api/v1/verify/vrf", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
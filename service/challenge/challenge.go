@@ -0,0 +1,69 @@
+package challenge
+
+import (
+	"crypto/rand"
+	"sync"
+	"teerminal/config"
+	"teerminal/constants"
+	"time"
+)
+
+// Device-issued nonces for attestation requests, every nonce expires after the configured TTL and can only be consumed once
+// Consumed nonces are kept until they expire, so a reused nonce can be told apart from an unknown one
+
+type entry struct {
+	expiresAt time.Time
+	consumed  bool
+}
+
+var (
+	mu         sync.Mutex
+	challenges = map[string]*entry{}
+)
+
+// prune drops the expired nonces, must hold mu
+func prune(now time.Time) {
+	for nonce, e := range challenges {
+		if now.After(e.expiresAt) {
+			delete(challenges, nonce)
+		}
+	}
+}
+
+// Issue creates a new nonce, and returns it along with its expiry
+func Issue() ([]byte, time.Time, error) {
+	nonce := make([]byte, constants.ChallengeLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, time.Time{}, err
+	}
+	now := time.Now()
+	mu.Lock()
+	defer mu.Unlock()
+	prune(now)
+	if len(challenges) >= constants.MaxChallenges {
+		return nil, time.Time{}, constants.ErrorTooManyChallenges
+	}
+	expiresAt := now.Add(config.GetChallengeTtl())
+	challenges[string(nonce)] = &entry{expiresAt: expiresAt}
+	return nonce, expiresAt, nil
+}
+
+// Consume marks the nonce as used, it fails if the nonce was never issued, has expired or was used before
+func Consume(nonce []byte) error {
+	now := time.Now()
+	mu.Lock()
+	defer mu.Unlock()
+	e, ok := challenges[string(nonce)]
+	if !ok {
+		return constants.ErrorUnknownNonce
+	}
+	if now.After(e.expiresAt) {
+		delete(challenges, string(nonce))
+		return constants.ErrorNonceExpired
+	}
+	if e.consumed {
+		return constants.ErrorNonceReused
+	}
+	e.consumed = true
+	return nil
+}
@@ -0,0 +1,74 @@
+package challenge
+
+import (
+	"errors"
+	"teerminal/config"
+	"teerminal/constants"
+	"testing"
+	"time"
+)
+
+func TestConsume(t *testing.T) {
+	config.Set(&config.Config{ChallengeTtl: 30})
+	nonce, expiresAt, err := Issue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nonce) != constants.ChallengeLength {
+		t.Fatalf("expected a %d bytes nonce, got %d", constants.ChallengeLength, len(nonce))
+	}
+	if ttl := time.Until(expiresAt); ttl <= 29*time.Second || ttl > 30*time.Second {
+		t.Fatalf("expected the configured ttl, got %s", ttl)
+	}
+	if err := Consume(nonce); err != nil {
+		t.Fatal(err)
+	}
+	if err := Consume(nonce); !errors.Is(err, constants.ErrorNonceReused) {
+		t.Fatalf("expected %v, got %v", constants.ErrorNonceReused, err)
+	}
+	if err := Consume(make([]byte, constants.ChallengeLength)); !errors.Is(err, constants.ErrorUnknownNonce) {
+		t.Fatalf("expected %v, got %v", constants.ErrorUnknownNonce, err)
+	}
+}
+
+func TestConsumeExpired(t *testing.T) {
+	config.Set(&config.Config{})
+	nonce, _, err := Issue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	challenges[string(nonce)].expiresAt = time.Now().Add(-time.Second)
+	mu.Unlock()
+	if err := Consume(nonce); !errors.Is(err, constants.ErrorNonceExpired) {
+		t.Fatalf("expected %v, got %v", constants.ErrorNonceExpired, err)
+	}
+	// An expired nonce is forgotten
+	if err := Consume(nonce); !errors.Is(err, constants.ErrorUnknownNonce) {
+		t.Fatalf("expected %v, got %v", constants.ErrorUnknownNonce, err)
+	}
+}
+
+func TestIssueLimit(t *testing.T) {
+	config.Set(&config.Config{})
+	mu.Lock()
+	challenges = map[string]*entry{}
+	mu.Unlock()
+	for i := 0; i < constants.MaxChallenges; i++ {
+		if _, _, err := Issue(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := Issue(); !errors.Is(err, constants.ErrorTooManyChallenges) {
+		t.Fatalf("expected %v, got %v", constants.ErrorTooManyChallenges, err)
+	}
+	// Expired nonces are pruned and make room
+	mu.Lock()
+	for _, e := range challenges {
+		e.expiresAt = time.Now().Add(-time.Second)
+	}
+	mu.Unlock()
+	if _, _, err := Issue(); err != nil {
+		t.Fatal(err)
+	}
+}
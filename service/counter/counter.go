@@ -0,0 +1,155 @@
+package counter
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"teerminal/config"
+	"teerminal/constants"
+)
+
+// Entry is a named monotonic counter of an application
+type Entry struct {
+	App   string `json:"app"`
+	Name  string `json:"name"`
+	Value uint64 `json:"value"`
+}
+
+const fileName = "counters.json"
+
+var (
+	mu       sync.Mutex
+	loadedAt string // loadedAt is the path the counters were loaded from
+	counters = map[string]Entry{}
+)
+
+func id(app string, name string) string {
+	return app + "\x00" + name
+}
+
+// load reads the counters from disk if the data directory changed since the last load, must hold mu
+func load() error {
+	path := filepath.Join(config.GetDataDir(), fileName)
+	if path == loadedAt {
+		return nil
+	}
+	loaded := map[string]Entry{}
+	raw, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		var entries []Entry
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			loaded[id(entry.App, entry.Name)] = entry
+		}
+	}
+	counters = loaded
+	loadedAt = path
+	return nil
+}
+
+// persist writes the counters with the pending entry to disk, must hold mu
+// The file is replaced atomically, so a crash leaves either the old or the new state, never a partial one
+func persist(pending Entry) error {
+	entries := make([]Entry, 0, len(counters)+1)
+	for key, entry := range counters {
+		if key != id(pending.App, pending.Name) {
+			entries = append(entries, entry)
+		}
+	}
+	entries = append(entries, pending)
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(loadedAt)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, fileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), loadedAt); err != nil {
+		return err
+	}
+	// Sync the directory so the rename itself survives a crash
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	// Only expose the new value once it is durable
+	counters[id(pending.App, pending.Name)] = pending
+	return nil
+}
+
+func Create(app string, name string) (Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := load(); err != nil {
+		return Entry{}, err
+	}
+	if _, ok := counters[id(app, name)]; ok {
+		return Entry{}, constants.ErrorCounterExists
+	}
+	if len(counters) >= constants.MaxCounters {
+		return Entry{}, constants.ErrorCounterQuotaReached
+	}
+	entry := Entry{App: app, Name: name}
+	if err := persist(entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+func Increment(app string, name string) (Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := load(); err != nil {
+		return Entry{}, err
+	}
+	entry, ok := counters[id(app, name)]
+	if !ok {
+		return Entry{}, constants.ErrorCounterDoesNotExist
+	}
+	if entry.Value == math.MaxUint64 {
+		return Entry{}, constants.ErrorCounterOverflow
+	}
+	entry.Value++
+	if err := persist(entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+func Load(app string, name string) (Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := load(); err != nil {
+		return Entry{}, err
+	}
+	entry, ok := counters[id(app, name)]
+	if !ok {
+		return Entry{}, constants.ErrorCounterDoesNotExist
+	}
+	return entry, nil
+}
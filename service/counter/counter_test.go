@@ -0,0 +1,61 @@
+package counter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"teerminal/config"
+	"teerminal/constants"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	config.Set(&config.Config{DataDir: t.TempDir()})
+	if _, err := Increment("app", "c"); !errors.Is(err, constants.ErrorCounterDoesNotExist) {
+		t.Fatalf("expected %v, got %v", constants.ErrorCounterDoesNotExist, err)
+	}
+	if _, err := Create("app", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create("app", "c"); !errors.Is(err, constants.ErrorCounterExists) {
+		t.Fatalf("expected %v, got %v", constants.ErrorCounterExists, err)
+	}
+	for want := uint64(1); want <= 3; want++ {
+		entry, err := Increment("app", "c")
+		if err != nil || entry.Value != want {
+			t.Fatalf("expected %d, got %d %v", want, entry.Value, err)
+		}
+	}
+	// Counters of other apps are apart
+	if _, err := Load("other", "c"); !errors.Is(err, constants.ErrorCounterDoesNotExist) {
+		t.Fatalf("expected %v, got %v", constants.ErrorCounterDoesNotExist, err)
+	}
+
+	// The value survives a reload from disk
+	mu.Lock()
+	loadedAt, counters = "", map[string]Entry{}
+	mu.Unlock()
+	if entry, err := Load("app", "c"); err != nil || entry.Value != 3 {
+		t.Fatalf("expected 3 after reload, got %d %v", entry.Value, err)
+	}
+
+	// A counter never wraps around
+	mu.Lock()
+	counters[id("app", "c")] = Entry{App: "app", Name: "c", Value: math.MaxUint64}
+	mu.Unlock()
+	if _, err := Increment("app", "c"); !errors.Is(err, constants.ErrorCounterOverflow) {
+		t.Fatalf("expected %v, got %v", constants.ErrorCounterOverflow, err)
+	}
+}
+
+func TestCounterQuota(t *testing.T) {
+	config.Set(&config.Config{DataDir: t.TempDir()})
+	for i := 0; i < constants.MaxCounters; i++ {
+		if _, err := Create("app", fmt.Sprintf("c%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Create("app", "over"); !errors.Is(err, constants.ErrorCounterQuotaReached) {
+		t.Fatalf("expected %v, got %v", constants.ErrorCounterQuotaReached, err)
+	}
+}
@@ -0,0 +1,205 @@
+package eat
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"teerminal/constants"
+	"teerminal/service/measurement"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ugorji/go/codec"
+)
+
+// IETF Entity Attestation Token (RFC 9711), signed by the device root key with ES256K
+// The token is either a JWT, or a CWT (COSE_Sign1, tag 18), both carry the device cert chain in the protected header
+
+const (
+	EncodingJwt = "jwt"
+	EncodingCwt = "cwt"
+
+	HardwareModel = "Teerminal Emulator"
+
+	ueidTypeRand = 0x01
+
+	coseHeaderAlg  = 1
+	coseHeaderKid  = 4
+	coseAlgEs256k  = -47
+	coseTagSign1   = 18
+	cwtClaimIat    = 6
+	cwtClaimNonce  = 10
+	cwtClaimUeid   = 256
+	cwtClaimHwMod  = 259
+	cwtClaimHwVer  = 260
+	cwtClaimSwName = 270
+	cwtClaimSwVer  = 271
+)
+
+// The device cert chain (concatenated 257 bytes certs) is carried in a private header parameter
+// In a JWT it is "teerminal_chain" (base64url), in a COSE header it is the private label -65537
+const (
+	jwtHeaderChain  = "teerminal_chain"
+	coseHeaderChain = -65537
+)
+
+// The measurement registers are bound by private claims, same as the registers digest of version attestations
+// In a JWT "teerminal_registers_digest" is the base64url digest and "teerminal_event_log" the event log as returned by
+// /api/v1/measurement/registers; in a CWT -65538 is the digest and -65539 the event log as [register, data, description]
+const (
+	jwtClaimRegistersDigest = "teerminal_registers_digest"
+	jwtClaimEventLog        = "teerminal_event_log"
+	cwtClaimRegistersDigest = -65538
+	cwtClaimEventLog        = -65539
+)
+
+type Claims struct {
+	Nonce              []byte
+	Ueid               []byte
+	HardwareModel      string
+	SoftwareName       string
+	SoftwareVersion    string
+	TeePlatformVersion uint32
+	IssuedAt           int64
+	RegistersDigest    []byte              // RegistersDigest is measurement.Digest of the registers
+	EventLog           []measurement.Event // EventLog replays to the registers
+}
+
+// eventLog is the event log of the claims, never nil so that it is encoded as an empty array
+func (c Claims) eventLog() []measurement.Event {
+	if c.EventLog == nil {
+		return []measurement.Event{}
+	}
+	return c.EventLog
+}
+
+// Ueid is the RAND type ueid derived from the device public key: 0x01 || keccak256(device public key)
+func Ueid(devicePubKey []byte) []byte {
+	return append([]byte{ueidTypeRand}, crypto.Keccak256(devicePubKey)...)
+}
+
+// signEs256k signs sha256(data) and returns the 64 bytes r || s signature used by JWS and COSE
+func signEs256k(key []byte, data []byte) []byte {
+	hash := sha256.Sum256(data)
+	// SignCompact returns v || r || s, v is not used by ES256K
+	return ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(key), hash[:], false)[1:]
+}
+
+// VerifyEs256k verifies the 64 bytes r || s signature over sha256(data)
+func VerifyEs256k(pubKey []byte, data []byte, signature []byte) bool {
+	if len(signature) != 64 {
+		return false
+	}
+	public, err := secp256k1.ParsePubKey(append([]byte{0x04}, pubKey...))
+	if err != nil {
+		return false
+	}
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(signature[:32]) || s.SetByteSlice(signature[32:]) {
+		return false
+	}
+	hash := sha256.Sum256(data)
+	return ecdsa.NewSignature(&r, &s).Verify(hash[:], public)
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// BuildJwt encodes the claims as a compact JWT signed with ES256K
+func BuildJwt(claims Claims, key []byte, pubKey []byte, chain []byte) (string, error) {
+	header, err := json.Marshal(map[string]interface{}{
+		"alg":          "ES256K",
+		"typ":          "eat+jwt",
+		"kid":          b64(pubKey),
+		jwtHeaderChain: b64(chain),
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"eat_nonce": b64(claims.Nonce),
+		"ueid":      b64(claims.Ueid),
+		"hwmodel":   b64([]byte(claims.HardwareModel)),
+		"hwversion": []string{strconv.FormatUint(uint64(claims.TeePlatformVersion), 10)},
+		"swname":    claims.SoftwareName,
+		"swversion": []string{claims.SoftwareVersion},
+		"iat":       claims.IssuedAt,
+
+		jwtClaimRegistersDigest: b64(claims.RegistersDigest),
+		jwtClaimEventLog:        claims.eventLog(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := b64(header) + "." + b64(payload)
+	return signingInput + "." + b64(signEs256k(key, []byte(signingInput))), nil
+}
+
+var cborHandle = &codec.CborHandle{BasicHandle: codec.BasicHandle{EncodeOptions: codec.EncodeOptions{Canonical: true}}}
+
+func encodeCbor(v interface{}) ([]byte, error) {
+	var out []byte
+	err := codec.NewEncoderBytes(&out, cborHandle).Encode(v)
+	return out, err
+}
+
+// cwtEventLog encodes the events as [register, data, description], the data is decoded from hex
+func cwtEventLog(events []measurement.Event) []interface{} {
+	encoded := make([]interface{}, 0, len(events))
+	for _, e := range events {
+		data, _ := hex.DecodeString(strings.TrimPrefix(e.Data, "0x"))
+		encoded = append(encoded, []interface{}{e.Register, data, e.Description})
+	}
+	return encoded
+}
+
+// BuildCwt encodes the claims as a CWT in a tagged COSE_Sign1 signed with ES256K
+func BuildCwt(claims Claims, key []byte, pubKey []byte, chain []byte) ([]byte, error) {
+	protected, err := encodeCbor(map[int]interface{}{
+		coseHeaderAlg:   coseAlgEs256k,
+		coseHeaderKid:   pubKey,
+		coseHeaderChain: chain,
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := encodeCbor(map[int]interface{}{
+		cwtClaimNonce:  claims.Nonce,
+		cwtClaimUeid:   claims.Ueid,
+		cwtClaimHwMod:  []byte(claims.HardwareModel),
+		cwtClaimHwVer:  []string{strconv.FormatUint(uint64(claims.TeePlatformVersion), 10)},
+		cwtClaimSwName: claims.SoftwareName,
+		cwtClaimSwVer:  []string{claims.SoftwareVersion},
+		cwtClaimIat:    claims.IssuedAt,
+
+		cwtClaimRegistersDigest: claims.RegistersDigest,
+		cwtClaimEventLog:        cwtEventLog(claims.eventLog()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Sig_structure = ["Signature1", protected, external_aad, payload]
+	sigStructure, err := encodeCbor([]interface{}{"Signature1", protected, []byte{}, payload})
+	if err != nil {
+		return nil, err
+	}
+	signature := signEs256k(key, sigStructure)
+	return encodeCbor(codec.RawExt{Tag: coseTagSign1, Value: []interface{}{protected, map[int]interface{}{}, payload, signature}})
+}
+
+// Build encodes the claims with the given encoding
+func Build(encoding string, claims Claims, key []byte, pubKey []byte, chain []byte) ([]byte, error) {
+	switch encoding {
+	case "", EncodingJwt:
+		token, err := BuildJwt(claims, key, pubKey, chain)
+		return []byte(token), err
+	case EncodingCwt:
+		return BuildCwt(claims, key, pubKey, chain)
+	}
+	return nil, constants.ErrorUnknownEatEncoding
+}
@@ -0,0 +1,176 @@
+package eat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/measurement"
+	"testing"
+
+	"github.com/ugorji/go/codec"
+)
+
+var testEvents = []measurement.Event{
+	{Register: 0, Data: "00ff", Description: "bootloader"},
+	{Register: 3, Data: hex.EncodeToString([]byte("app")), Description: "app"},
+}
+
+func testClaims(t *testing.T, events []measurement.Event) Claims {
+	t.Helper()
+	registers, err := measurement.Replay(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Claims{
+		Nonce:              bytes.Repeat([]byte{0xab}, 64),
+		Ueid:               Ueid(make([]byte, 64)),
+		HardwareModel:      HardwareModel,
+		SoftwareName:       "EmulatorDefault",
+		SoftwareVersion:    "0.0.1-test",
+		TeePlatformVersion: 1,
+		IssuedAt:           1700000000,
+		RegistersDigest:    measurement.Digest(registers),
+		EventLog:           events,
+	}
+}
+
+func testKey() (key []byte, pubKey []byte) {
+	key = make([]byte, 32)
+	key[31] = 1
+	return key, encryption.GetPublicKey(key)
+}
+
+// checkEventLog checks that the event log replays to the registers digest
+func checkEventLog(t *testing.T, events []measurement.Event, digest []byte) {
+	t.Helper()
+	registers, err := measurement.Replay(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(measurement.Digest(registers), digest) {
+		t.Fatal("event log does not replay to the registers digest")
+	}
+}
+
+func TestJwt(t *testing.T) {
+	key, pubKey := testKey()
+	chain := []byte("chain")
+	for name, events := range map[string][]measurement.Event{"measured": testEvents, "empty": nil} {
+		t.Run(name, func(t *testing.T) {
+			claims := testClaims(t, events)
+			token, err := BuildJwt(claims, key, pubKey, chain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parts := strings.Split(token, ".")
+			if len(parts) != 3 {
+				t.Fatalf("expected 3 parts, got %d", len(parts))
+			}
+			signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			if !VerifyEs256k(pubKey, []byte(parts[0]+"."+parts[1]), signature) {
+				t.Fatal("signature does not verify")
+			}
+			if VerifyEs256k(pubKey, []byte(parts[0]+"."+parts[1]+"A"), signature) {
+				t.Fatal("signature verifies over a tampered payload")
+			}
+
+			var header map[string]string
+			raw, _ := base64.RawURLEncoding.DecodeString(parts[0])
+			if err := json.Unmarshal(raw, &header); err != nil {
+				t.Fatal(err)
+			}
+			if header["alg"] != "ES256K" || header["kid"] != b64(pubKey) || header[jwtHeaderChain] != b64(chain) {
+				t.Fatalf("unexpected header %v", header)
+			}
+
+			var payload struct {
+				Nonce           string              `json:"eat_nonce"`
+				Ueid            string              `json:"ueid"`
+				RegistersDigest string              `json:"teerminal_registers_digest"`
+				EventLog        []measurement.Event `json:"teerminal_event_log"`
+			}
+			raw, _ = base64.RawURLEncoding.DecodeString(parts[1])
+			if err := json.Unmarshal(raw, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(raw), `"teerminal_event_log":[`) {
+				t.Fatal("event log is not an array")
+			}
+			if payload.Nonce != b64(claims.Nonce) || payload.Ueid != b64(claims.Ueid) {
+				t.Fatalf("unexpected nonce %s ueid %s", payload.Nonce, payload.Ueid)
+			}
+			digest, _ := base64.RawURLEncoding.DecodeString(payload.RegistersDigest)
+			if !bytes.Equal(digest, claims.RegistersDigest) || len(payload.EventLog) != len(events) {
+				t.Fatalf("unexpected registers digest %x or event log %v", digest, payload.EventLog)
+			}
+			checkEventLog(t, payload.EventLog, digest)
+		})
+	}
+}
+
+func TestCwt(t *testing.T) {
+	key, pubKey := testKey()
+	chain := []byte("chain")
+	claims := testClaims(t, testEvents)
+	token, err := BuildCwt(claims, key, pubKey, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded interface{}
+	if err := codec.NewDecoderBytes(token, cborHandle).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	tagged, ok := decoded.(codec.RawExt)
+	if !ok || tagged.Tag != coseTagSign1 {
+		t.Fatalf("expected a COSE_Sign1 tag, got %v", decoded)
+	}
+	sign1 := tagged.Value.([]interface{})
+	protected, payload, signature := sign1[0].([]byte), sign1[2].([]byte), sign1[3].([]byte)
+	sigStructure, _ := encodeCbor([]interface{}{"Signature1", protected, []byte{}, payload})
+	if !VerifyEs256k(pubKey, sigStructure, signature) {
+		t.Fatal("signature does not verify")
+	}
+
+	var header map[int64]interface{}
+	if err := codec.NewDecoderBytes(protected, cborHandle).Decode(&header); err != nil {
+		t.Fatal(err)
+	}
+	if header[coseHeaderAlg] != int64(coseAlgEs256k) || !bytes.Equal(header[coseHeaderKid].([]byte), pubKey) || !bytes.Equal(header[coseHeaderChain].([]byte), chain) {
+		t.Fatalf("unexpected header %v", header)
+	}
+
+	var cwt map[int64]interface{}
+	if err := codec.NewDecoderBytes(payload, cborHandle).Decode(&cwt); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(cwt[cwtClaimNonce].([]byte), claims.Nonce) || !bytes.Equal(cwt[cwtClaimUeid].([]byte), claims.Ueid) {
+		t.Fatalf("unexpected nonce %x ueid %x", cwt[cwtClaimNonce], cwt[cwtClaimUeid])
+	}
+	digest := cwt[cwtClaimRegistersDigest].([]byte)
+	var events []measurement.Event
+	for _, e := range cwt[cwtClaimEventLog].([]interface{}) {
+		event := e.([]interface{})
+		events = append(events, measurement.Event{
+			Register:    int(event[0].(uint64)),
+			Data:        hex.EncodeToString(event[1].([]byte)),
+			Description: event[2].(string),
+		})
+	}
+	if !reflect.DeepEqual(events, testEvents) {
+		t.Fatalf("expected event log %v, got %v", testEvents, events)
+	}
+	checkEventLog(t, events, digest)
+}
+
+func TestBuildUnknownEncoding(t *testing.T) {
+	key, pubKey := testKey()
+	if _, err := Build("xml", testClaims(t, nil), key, pubKey, nil); !errors.Is(err, constants.ErrorUnknownEatEncoding) {
+		t.Fatalf("expected %v, got %v", constants.ErrorUnknownEatEncoding, err)
+	}
+}
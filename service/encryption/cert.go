@@ -0,0 +1,106 @@
+package encryption
+
+import (
+	"bytes"
+	"teerminal/constants"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CertLength is the size of a single cert: 64b prover || 64b provee || 64b derivation || 65b signature
+const CertLength = 257
+
+type Cert struct {
+	Prover     []byte
+	Provee     []byte
+	Derivation []byte
+	Signature  []byte
+}
+
+// CertCheck is the verification result of a single cert in a chain
+type CertCheck struct {
+	Cert
+	Signer          []byte // Signer is the recovered 64 bytes public key, empty if recovery failed
+	SignatureValid  bool   // SignatureValid is true if the signer is the prover
+	DerivationValid bool   // DerivationValid is true if the prover is the chain root or the previous provee
+	Error           error
+}
+
+func UnpackCert(cert []byte) (Cert, error) {
+	// Same layout as CertLib.unpackCert
+	if len(cert) != CertLength {
+		return Cert{}, constants.ErrorInvalidCertLength
+	}
+	return Cert{
+		Prover:     cert[0:64],
+		Provee:     cert[64:128],
+		Derivation: cert[128:192],
+		Signature:  cert[192:257],
+	}, nil
+}
+
+func (c Cert) Bytes() (cert []byte) {
+	cert = append(cert, c.Prover...)
+	cert = append(cert, c.Provee...)
+	cert = append(cert, c.Derivation...)
+	cert = append(cert, c.Signature...)
+	return
+}
+
+func (c Cert) SigningBody() []byte {
+	// Same signing body as CertLib.verifyCert: derivation || provee
+	return append(append([]byte{}, c.Derivation...), c.Provee...)
+}
+
+// Verify checks the cert by the rules of CertLib.verifyCert, and returns the recovered signer
+func (c Cert) Verify() (signer []byte, valid bool) {
+	// ecrecover only accepts v = 27 or 28
+	if v := c.Signature[64]; v != 27 && v != 28 {
+		return nil, false
+	}
+	rec, err := RecoverPublicKey(c.SigningBody(), c.Signature)
+	if err != nil {
+		return nil, false
+	}
+	signer = rec.SerializeUncompressed()[1:]
+	// Compare by address, same as ecrecover result against keccak256(prover)
+	return signer, bytes.Equal(crypto.Keccak256(signer)[12:], crypto.Keccak256(c.Prover)[12:])
+}
+
+// CheckCertChain verifies every cert in the chain and reports the result for each of them
+// The rules are the same as CertLib.verifyCertChain, checking continues after the first failure
+func CheckCertChain(chain []byte, root []byte) (checks []CertCheck, err error) {
+	if len(chain)%CertLength != 0 {
+		return nil, constants.ErrorInvalidChainLength
+	}
+	prover := root
+	for i := 0; i < len(chain); i += CertLength {
+		c, _ := UnpackCert(chain[i : i+CertLength])
+		check := CertCheck{Cert: c}
+		check.Signer, check.SignatureValid = c.Verify()
+		check.DerivationValid = bytes.Equal(crypto.Keccak256(c.Prover), crypto.Keccak256(prover))
+		if !check.SignatureValid {
+			check.Error = constants.ErrorInvalidCert
+		} else if !check.DerivationValid {
+			check.Error = constants.ErrorInvalidCertDerivation
+		}
+		if check.Error != nil && err == nil {
+			err = check.Error
+		}
+		checks = append(checks, check)
+		prover = c.Provee
+	}
+	return
+}
+
+// VerifyCertChain verifies the chain against the root public key and returns the last provee
+func VerifyCertChain(chain []byte, root []byte) ([]byte, error) {
+	checks, err := CheckCertChain(chain, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(checks) == 0 {
+		return root, nil
+	}
+	return checks[len(checks)-1].Provee, nil
+}
@@ -0,0 +1,38 @@
+package encryption
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+)
+
+// DeriveEcdsaKey derives a NIST curve (P-256, P-384 or P-521) private key from a secp256k1 private key and a label
+// It is used for the emulated attestation formats of other TEEs, which do not use secp256k1
+func DeriveEcdsaKey(curve elliptic.Curve, seed []byte, label string) *ecdsa.PrivateKey {
+	var ecdhCurve ecdh.Curve
+	switch curve {
+	case elliptic.P384():
+		ecdhCurve = ecdh.P384()
+	case elliptic.P521():
+		ecdhCurve = ecdh.P521()
+	default:
+		curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+	}
+	// d = keccak256(seed || "_derive_" || label) mod (n - 1) + 1
+	n1 := new(big.Int).Sub(curve.Params().N, big.NewInt(1))
+	d := new(big.Int).SetBytes(DerivePrivateKey(seed, []byte(label)))
+	d.Mod(d, n1).Add(d, big.NewInt(1))
+	key, _ := ecdhCurve.NewPrivateKey(d.FillBytes(make([]byte, (curve.Params().BitSize+7)/8)))
+	// Uncompressed point: 0x04 || x || y
+	pub := key.PublicKey().Bytes()
+	size := (len(pub) - 1) / 2
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(pub[1 : 1+size]),
+			Y:     new(big.Int).SetBytes(pub[1+size:]),
+		},
+		D: d,
+	}
+}
@@ -0,0 +1,80 @@
+package encryption
+
+import (
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Multicodec prefix for secp256k1-pub (0xe7) as unsigned varint
+var didKeySecp256k1Prefix = []byte{0xe7, 0x01}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func parsePublicKey(pubKey []byte) (*secp256k1.PublicKey, error) {
+	// Public keys are passed around in 64 bytes format, add 0x04 prefix back before parsing
+	pubKeyWithPrefix := append([]byte{0x04}, pubKey...)
+	return secp256k1.ParsePubKey(pubKeyWithPrefix)
+}
+
+func CompressPublicKey(pubKey []byte) ([]byte, error) {
+	// SEC1 compressed format: 0x02 / 0x03 (parity of y) || 32 bytes x
+	public, err := parsePublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return public.SerializeCompressed(), nil
+}
+
+func XOnlyPublicKey(pubKey []byte) ([]byte, error) {
+	// BIP-340 x-only format: 32 bytes x coordinate
+	compressed, err := CompressPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return compressed[1:], nil
+}
+
+func PublicKeyToAddress(pubKey []byte) (string, error) {
+	// Ethereum address: last 20 bytes of keccak256(64 bytes public key), EIP-55 checksummed
+	if _, err := parsePublicKey(pubKey); err != nil {
+		return "", err
+	}
+	return common.BytesToAddress(crypto.Keccak256(pubKey)[12:]).Hex(), nil
+}
+
+func PublicKeyToDidKey(pubKey []byte) (string, error) {
+	// did:key format: "did:key:z" || base58btc(multicodec secp256k1-pub || compressed public key)
+	compressed, err := CompressPublicKey(pubKey)
+	if err != nil {
+		return "", err
+	}
+	payload := append(append([]byte{}, didKeySecp256k1Prefix...), compressed...)
+	return "did:key:z" + base58Encode(payload), nil
+}
+
+func base58Encode(data []byte) string {
+	// Leading zero bytes are encoded as '1' each
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	num := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var encoded []byte
+	for num.Sign() > 0 {
+		num.DivMod(num, radix, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		encoded = append(encoded, base58Alphabet[0])
+	}
+	// Reverse to big endian order
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
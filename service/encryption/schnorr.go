@@ -0,0 +1,35 @@
+package encryption
+
+import (
+	"teerminal/constants"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// SchnorrSign signs the 32 bytes hash with BIP-340, the key is negated if its public key has an odd y, so the signature
+// verifies against the x-only public key. Nonces are derived deterministically (RFC 6979), same as Sign
+func SchnorrSign(key []byte, hash []byte) ([]byte, error) {
+	private, _ := btcec.PrivKeyFromBytes(key)
+	if private == nil {
+		return nil, constants.ErrorFailedDecodePrivateKey
+	}
+	sig, err := schnorr.Sign(private, hash)
+	if err != nil {
+		return nil, err
+	}
+	return sig.Serialize(), nil
+}
+
+// SchnorrVerify verifies the 64 bytes BIP-340 signature of the 32 bytes hash against the 32 bytes x-only public key
+func SchnorrVerify(xOnlyPubKey []byte, hash []byte, signature []byte) bool {
+	public, err := schnorr.ParsePubKey(xOnlyPubKey)
+	if err != nil {
+		return false
+	}
+	sig, err := schnorr.ParseSignature(signature)
+	if err != nil {
+		return false
+	}
+	return sig.Verify(hash, public)
+}
@@ -82,20 +82,13 @@ func GenerateCert(prover []byte, derivation []byte) (cert []byte) {
 
 func VerifySignature(pubKey []byte, data []byte, signature []byte) bool {
 	// Verify the signature
-	hash := crypto.Keccak256(data)
-	// Sub 27 if v is 27 or 28
-	sig := make([]byte, len(signature))
-	copy(sig, signature)
-	if sig[len(sig)-1] >= 27 {
-		sig[len(sig)-1] -= 27
-	}
 	// Add 0x04 prefix to public key
 	pubKeyWithPrefix := append([]byte{0x04}, pubKey...)
 	public, err := secp256k1.ParsePubKey(pubKeyWithPrefix)
 	if err != nil {
 		return false
 	}
-	rec, _, err := ecdsa.RecoverCompact(sig, hash)
+	rec, err := RecoverPublicKey(data, signature)
 	if err != nil {
 		fmt.Printf("encryption.VerifySignature: %v\n", err)
 		return false
@@ -103,6 +96,23 @@ func VerifySignature(pubKey []byte, data []byte, signature []byte) bool {
 	return public.IsEqual(rec)
 }
 
+func RecoverPublicKey(data []byte, signature []byte) (*secp256k1.PublicKey, error) {
+	if len(signature) != 65 {
+		return nil, constants.ErrorInvalidSignatureLength
+	}
+	hash := crypto.Keccak256(data)
+	// Move v from the end (r || s || v) to the front (v || r || s), which is the compact format
+	// Add 27 if v is 0 or 1
+	sig := make([]byte, len(signature))
+	copy(sig[1:], signature[:64])
+	sig[0] = signature[64]
+	if sig[0] < 27 {
+		sig[0] += 27
+	}
+	rec, _, err := ecdsa.RecoverCompact(sig, hash)
+	return rec, err
+}
+
 func GetDeviceRootCert() (cert []byte) {
 	// Root Certificate is generated by the same rules as the child certificate, except the derivation seed is fixed to 0
 	prover := config.GetVendorRoot()
@@ -0,0 +1,127 @@
+#!/usr/bin/env python3
+"""Reference ECVRF-SECP256K1-SHA256-TAI prover, written from RFC 9381 independently of vrf.go.
+
+Prints the known-answer vectors of vrf_test.go as JSON: python3 vrf_reference.py > vrf_vectors.json
+"""
+import hashlib
+import hmac
+import json
+
+P = 2**256 - 2**32 - 977
+N = 0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141
+G = (0x79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798,
+     0x483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8)
+SUITE = b"\xfe"
+
+
+def add(p, q):
+    if p is None:
+        return q
+    if q is None:
+        return p
+    if p[0] == q[0] and (p[1] + q[1]) % P == 0:
+        return None
+    if p == q:
+        m = 3 * p[0] * p[0] * pow(2 * p[1], -1, P) % P
+    else:
+        m = (q[1] - p[1]) * pow(q[0] - p[0], -1, P) % P
+    x = (m * m - p[0] - q[0]) % P
+    return x, (m * (p[0] - x) - p[1]) % P
+
+
+def mul(k, p):
+    r = None
+    while k:
+        if k & 1:
+            r = add(r, p)
+        p = add(p, p)
+        k >>= 1
+    return r
+
+
+def encode(p):
+    return bytes([2 + (p[1] & 1)]) + p[0].to_bytes(32, "big")
+
+
+def decode(b):
+    if len(b) != 33 or b[0] not in (2, 3):
+        return None
+    x = int.from_bytes(b[1:], "big")
+    if x >= P:
+        return None
+    y2 = (pow(x, 3, P) + 7) % P
+    y = pow(y2, (P + 1) // 4, P)
+    if y * y % P != y2:
+        return None
+    if y & 1 != b[0] & 1:
+        y = P - y
+    return x, y
+
+
+def encode_to_curve(pk, alpha):
+    # RFC 9381 5.4.1.1, try and increment
+    for ctr in range(256):
+        h = hashlib.sha256(SUITE + b"\x01" + pk + alpha + bytes([ctr]) + b"\x00").digest()
+        point = decode(b"\x02" + h)
+        if point is not None:
+            return point
+    raise ValueError("no point found")
+
+
+def nonce(x, h1):
+    # RFC 6979 3.2 with SHA-256, h1 is the hash of the point H
+    key = x.to_bytes(32, "big")
+    h = (int.from_bytes(h1, "big") % N).to_bytes(32, "big")
+    v = b"\x01" * 32
+    k = b"\x00" * 32
+    k = hmac.new(k, v + b"\x00" + key + h, hashlib.sha256).digest()
+    v = hmac.new(k, v, hashlib.sha256).digest()
+    k = hmac.new(k, v + b"\x01" + key + h, hashlib.sha256).digest()
+    v = hmac.new(k, v, hashlib.sha256).digest()
+    while True:
+        v = hmac.new(k, v, hashlib.sha256).digest()
+        candidate = int.from_bytes(v, "big")
+        if 1 <= candidate < N:
+            return candidate
+        k = hmac.new(k, v + b"\x00", hashlib.sha256).digest()
+        v = hmac.new(k, v, hashlib.sha256).digest()
+
+
+def challenge(*points):
+    return hashlib.sha256(SUITE + b"\x02" + b"".join(points) + b"\x00").digest()[:16]
+
+
+def prove(x, alpha):
+    y = mul(x, G)
+    pk = encode(y)
+    h = encode_to_curve(pk, alpha)
+    gamma = mul(x, h)
+    k = nonce(x, hashlib.sha256(encode(h)).digest())
+    c = challenge(pk, encode(h), encode(gamma), encode(mul(k, G)), encode(mul(k, h)))
+    s = (k + int.from_bytes(c, "big") * x) % N
+    proof = encode(gamma) + c + s.to_bytes(32, "big")
+    beta = hashlib.sha256(SUITE + b"\x03" + encode(gamma) + b"\x00").digest()
+    return y, proof, beta
+
+
+def main():
+    vectors = []
+    for key, alpha in [
+        (1, b""),
+        (0xC9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721, b"sample"),
+        (0xC9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721, b"test"),
+        (int.from_bytes(hashlib.sha256(b"teerminal").digest(), "big") % N, bytes(range(64))),
+    ]:
+        y, proof, beta = prove(key, alpha)
+        vectors.append({
+            "key": key.to_bytes(32, "big").hex(),
+            "pubKey": (y[0].to_bytes(32, "big") + y[1].to_bytes(32, "big")).hex(),
+            "alpha": alpha.hex(),
+            "proof": proof.hex(),
+            "output": beta.hex(),
+        })
+    print(json.dumps(vectors, indent=2))
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,148 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"teerminal/constants"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// ECVRF-SECP256K1-SHA256-TAI, following RFC 9381 with the try-and-increment encode to curve
+// Proof Format: 33 bytes compressed gamma || 16 bytes c || 32 bytes s
+
+const (
+	vrfSuite       = 0xfe
+	VrfProofLength = 33 + 16 + 32
+)
+
+func isInfinity(p *secp256k1.JacobianPoint) bool {
+	return (p.X.IsZero() && p.Y.IsZero()) || p.Z.IsZero()
+}
+
+func jacobianToBytes(p *secp256k1.JacobianPoint) []byte {
+	p.ToAffine()
+	return secp256k1.NewPublicKey(&p.X, &p.Y).SerializeCompressed()
+}
+
+func vrfEncodeToCurve(pubKey *secp256k1.PublicKey, alpha []byte) (*secp256k1.JacobianPoint, error) {
+	// hash_string = SHA256(suite || 0x01 || PK || alpha || ctr || 0x00), interpreted as 0x02 || hash_string
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha256.New()
+		h.Write([]byte{vrfSuite, 0x01})
+		h.Write(pubKey.SerializeCompressed())
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), 0x00})
+		candidate, err := secp256k1.ParsePubKey(append([]byte{0x02}, h.Sum(nil)...))
+		if err != nil {
+			continue
+		}
+		var point secp256k1.JacobianPoint
+		candidate.AsJacobian(&point)
+		return &point, nil
+	}
+	return nil, constants.ErrorVrfEncodeToCurve
+}
+
+func vrfChallenge(points ...[]byte) []byte {
+	// c = SHA256(suite || 0x02 || Y || H || Gamma || U || V || 0x00), truncated to 16 bytes
+	h := sha256.New()
+	h.Write([]byte{vrfSuite, 0x02})
+	for _, p := range points {
+		h.Write(p)
+	}
+	h.Write([]byte{0x00})
+	return h.Sum(nil)[:16]
+}
+
+func vrfProofToHash(gamma []byte) []byte {
+	// beta = SHA256(suite || 0x03 || Gamma || 0x00), cofactor of secp256k1 is 1
+	h := sha256.New()
+	h.Write([]byte{vrfSuite, 0x03})
+	h.Write(gamma)
+	h.Write([]byte{0x00})
+	return h.Sum(nil)
+}
+
+// VrfProve computes the VRF proof of alpha with the private key, and returns the 32 bytes output along with the proof
+func VrfProve(key []byte, alpha []byte) (output []byte, proof []byte, err error) {
+	private := secp256k1.PrivKeyFromBytes(key)
+	if private == nil || private.Key.IsZero() {
+		return nil, nil, constants.ErrorFailedDecodePrivateKey
+	}
+	public := private.PubKey()
+	h, err := vrfEncodeToCurve(public, alpha)
+	if err != nil {
+		return nil, nil, err
+	}
+	hBytes := jacobianToBytes(h)
+	// Gamma = x * H
+	var gamma secp256k1.JacobianPoint
+	secp256k1.ScalarMultNonConst(&private.Key, h, &gamma)
+	gammaBytes := jacobianToBytes(&gamma)
+	// k = RFC6979 nonce over SHA256(H)
+	hHash := sha256.Sum256(hBytes)
+	k := secp256k1.NonceRFC6979(key, hHash[:], nil, nil, 0)
+	// U = k * B, V = k * H
+	var u, v secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(k, &u)
+	secp256k1.ScalarMultNonConst(k, h, &v)
+	c := vrfChallenge(public.SerializeCompressed(), hBytes, gammaBytes, jacobianToBytes(&u), jacobianToBytes(&v))
+	// s = k + c * x mod n
+	var cScalar, s secp256k1.ModNScalar
+	cScalar.SetByteSlice(c)
+	s.Mul2(&cScalar, &private.Key).Add(k)
+	sBytes := s.Bytes()
+
+	proof = append(proof, gammaBytes...)
+	proof = append(proof, c...)
+	proof = append(proof, sBytes[:]...)
+	return vrfProofToHash(gammaBytes), proof, nil
+}
+
+// VrfVerify checks the proof of alpha against the 64 bytes public key, and returns the 32 bytes output
+func VrfVerify(pubKey []byte, alpha []byte, proof []byte) (output []byte, err error) {
+	public, err := parsePublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(proof) != VrfProofLength {
+		return nil, constants.ErrorInvalidVrfProof
+	}
+	gammaKey, err := secp256k1.ParsePubKey(proof[:33])
+	if err != nil {
+		return nil, constants.ErrorInvalidVrfProof
+	}
+	c := proof[33:49]
+	var cScalar, s secp256k1.ModNScalar
+	cScalar.SetByteSlice(c)
+	if s.SetByteSlice(proof[49:]) {
+		return nil, constants.ErrorInvalidVrfProof
+	}
+	h, err := vrfEncodeToCurve(public, alpha)
+	if err != nil {
+		return nil, err
+	}
+	var y, gamma secp256k1.JacobianPoint
+	public.AsJacobian(&y)
+	gammaKey.AsJacobian(&gamma)
+	cScalar.Negate()
+	// U = s * B - c * Y
+	var sB, cY, u secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&s, &sB)
+	secp256k1.ScalarMultNonConst(&cScalar, &y, &cY)
+	secp256k1.AddNonConst(&sB, &cY, &u)
+	// V = s * H - c * Gamma
+	var sH, cGamma, v secp256k1.JacobianPoint
+	secp256k1.ScalarMultNonConst(&s, h, &sH)
+	secp256k1.ScalarMultNonConst(&cScalar, &gamma, &cGamma)
+	secp256k1.AddNonConst(&sH, &cGamma, &v)
+	if isInfinity(&u) || isInfinity(&v) {
+		return nil, constants.ErrorInvalidVrfProof
+	}
+	expected := vrfChallenge(public.SerializeCompressed(), jacobianToBytes(h), proof[:33], jacobianToBytes(&u), jacobianToBytes(&v))
+	if !bytes.Equal(expected, c) {
+		return nil, constants.ErrorInvalidVrfProof
+	}
+	return vrfProofToHash(proof[:33]), nil
+}
@@ -0,0 +1,110 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"teerminal/constants"
+	"testing"
+)
+
+type vrfVector struct {
+	Key    string `json:"key"`
+	PubKey string `json:"pubKey"`
+	Alpha  string `json:"alpha"`
+	Proof  string `json:"proof"`
+	Output string `json:"output"`
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+// TestVrfKnownAnswers checks against testdata/vrf_vectors.json, generated by testdata/vrf_reference.py,
+// an implementation of RFC 9381 independent of vrf.go
+func TestVrfKnownAnswers(t *testing.T) {
+	raw, err := os.ReadFile("testdata/vrf_vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []vrfVector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+	for _, v := range vectors {
+		key := mustDecodeHex(t, v.Key)
+		alpha := mustDecodeHex(t, v.Alpha)
+		if pubKey := GetPublicKey(key); hex.EncodeToString(pubKey) != v.PubKey {
+			t.Errorf("key %s: expected public key %s, got %x", v.Key, v.PubKey, pubKey)
+		}
+		output, proof, err := VrfProve(key, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(proof) != v.Proof || hex.EncodeToString(output) != v.Output {
+			t.Errorf("key %s alpha %q: expected proof %s output %s, got %x %x", v.Key, v.Alpha, v.Proof, v.Output, proof, output)
+		}
+		verified, err := VrfVerify(mustDecodeHex(t, v.PubKey), alpha, mustDecodeHex(t, v.Proof))
+		if err != nil || hex.EncodeToString(verified) != v.Output {
+			t.Errorf("key %s alpha %q: reference proof does not verify: %v", v.Key, v.Alpha, err)
+		}
+	}
+}
+
+func TestVrfRoundTrip(t *testing.T) {
+	for _, alpha := range [][]byte{nil, []byte("alpha"), bytes.Repeat([]byte{0xff}, 1024)} {
+		key := DerivePrivateKey([]byte("vrf"), alpha)
+		output, proof, err := VrfProve(key, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(proof) != VrfProofLength || len(output) != 32 {
+			t.Fatalf("unexpected proof length %d, output length %d", len(proof), len(output))
+		}
+		verified, err := VrfVerify(GetPublicKey(key), alpha, proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(verified, output) {
+			t.Errorf("expected output %x, got %x", output, verified)
+		}
+	}
+}
+
+func TestVrfTampered(t *testing.T) {
+	key := DerivePrivateKey([]byte("vrf"), []byte("tampered"))
+	pubKey := GetPublicKey(key)
+	alpha := []byte("alpha")
+	_, proof, err := VrfProve(key, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Flip a bit of gamma, c and s
+	for _, i := range []int{1, 32, 33, 48, 49, VrfProofLength - 1} {
+		tampered := append([]byte{}, proof...)
+		tampered[i] ^= 0x01
+		if _, err := VrfVerify(pubKey, alpha, tampered); !errors.Is(err, constants.ErrorInvalidVrfProof) {
+			t.Errorf("byte %d: expected %v, got %v", i, constants.ErrorInvalidVrfProof, err)
+		}
+	}
+	if _, err := VrfVerify(pubKey, []byte("alphb"), proof); !errors.Is(err, constants.ErrorInvalidVrfProof) {
+		t.Errorf("tampered alpha: expected %v, got %v", constants.ErrorInvalidVrfProof, err)
+	}
+	other := GetPublicKey(DerivePrivateKey([]byte("vrf"), []byte("other")))
+	if _, err := VrfVerify(other, alpha, proof); !errors.Is(err, constants.ErrorInvalidVrfProof) {
+		t.Errorf("other public key: expected %v, got %v", constants.ErrorInvalidVrfProof, err)
+	}
+	if _, err := VrfVerify(pubKey, alpha, proof[:VrfProofLength-1]); !errors.Is(err, constants.ErrorInvalidVrfProof) {
+		t.Errorf("truncated proof: expected %v, got %v", constants.ErrorInvalidVrfProof, err)
+	}
+}
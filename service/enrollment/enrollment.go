@@ -0,0 +1,79 @@
+package enrollment
+
+import (
+	"bytes"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Enrollments are signed by the device root key, and verified the same way as an on-chain registry does with CertLib:
+// the device chain is verified against the vendor root, and the signer of the enrollment must be the leaf of the chain.
+// Only the device chain is accepted: the app key signs any data for its callers, so an app chain must never make the
+// app key pass as a device key
+
+// Verified is the result of a successful enrollment verification
+type Verified struct {
+	DeviceKey     []byte         // DeviceKey is the 64 bytes device public key, the leaf of the device chain
+	DeviceAddress common.Address // DeviceAddress is the address of the device key, as recovered by ecrecover
+	Payload       []byte         // Payload is the enrolled data, the canonical encoding of the fields for structured enrollments
+}
+
+// Signable builds the payload signed by the device root key for enrollment:
+// "DEPHY_ID_SIGNED_MESSAGE:" || keccak256(data)
+func Signable(data []byte) []byte {
+	return append([]byte(constants.DeviceEnrollmentKey), crypto.Keccak256(data)...)
+}
+
+// Recover returns the address of the key that signed the enrollment of payload
+// v may be 0, 1, 27 or 28, same as the signatures returned by /api/v1/device/sign
+func Recover(payload []byte, signature []byte) (common.Address, error) {
+	return recoverAddress(Signable(payload), signature)
+}
+
+func recoverAddress(signable []byte, signature []byte) (common.Address, error) {
+	rec, err := encryption.RecoverPublicKey(signable, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.BytesToAddress(crypto.Keccak256(rec.SerializeUncompressed()[1:])[12:]), nil
+}
+
+// Verify verifies the device chain against the vendor root public key, and the enrollment signature against the leaf
+// of the chain, deviceKey is the device key claimed by the enrollment, it is checked against the leaf if not empty.
+// The chain must be the device chain: vendor root -> root key -> device key
+func Verify(chain []byte, root []byte, deviceKey []byte, payload []byte, signature []byte) (Verified, error) {
+	return verifySignable(chain, root, deviceKey, payload, Signable(payload), signature)
+}
+
+func verifySignable(chain []byte, root []byte, deviceKey []byte, payload []byte, signable []byte, signature []byte) (Verified, error) {
+	if len(chain) != constants.DeviceChainCerts*encryption.CertLength {
+		return Verified{}, constants.ErrorInvalidChainLength
+	}
+	leaf, err := encryption.VerifyCertChain(chain, root)
+	if err != nil {
+		return Verified{}, err
+	}
+	// The leaf is the device key only if the root key derived it with the device root derivation
+	last, _ := encryption.UnpackCert(chain[len(chain)-encryption.CertLength:])
+	derivation := make([]byte, 64)
+	copy(derivation, constants.DeviceRootKey)
+	if !bytes.Equal(last.Derivation, derivation) {
+		return Verified{}, constants.ErrorNotDeviceChain
+	}
+	if len(deviceKey) > 0 && !bytes.Equal(deviceKey, leaf) {
+		return Verified{}, constants.ErrorChainLeafMismatch
+	}
+	signer, err := recoverAddress(signable, signature)
+	if err != nil {
+		return Verified{}, err
+	}
+	// Compare by address, same as CertLib.verifyCert
+	address := common.BytesToAddress(crypto.Keccak256(leaf)[12:])
+	if signer != address {
+		return Verified{}, constants.ErrorSignatureMismatch
+	}
+	return Verified{DeviceKey: leaf, DeviceAddress: address, Payload: payload}, nil
+}
@@ -0,0 +1,86 @@
+package enrollment
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/identity"
+	"testing"
+	"time"
+)
+
+func TestVectors(t *testing.T) {
+	raw, err := os.ReadFile("testdata/vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []Vector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, v := range vectors {
+		names[v.Name] = true
+		t.Run(v.Name, func(t *testing.T) {
+			if err := v.Check(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	for _, name := range []string{"valid", "emptyChain", "appChain", "appKeySigned", "otherDerivation"} {
+		if !names[name] {
+			t.Errorf("missing case %s", name)
+		}
+	}
+}
+
+func TestAppKeyIsNotDeviceKey(t *testing.T) {
+	config.Set(&config.Config{
+		VendorRoot: "dbbe0cd0b4c7bc4ab34829c96f35bb0011d06dc3bdf0b900401a71a8f7c4c471",
+		RootKey:    "cd2f10b3d7d306a27199ccf51868c1b0859f824b6fab53710f06a092ae40226f",
+		AppName:    "EmulatorDefault",
+	})
+	id := identity.Get()
+	payload := []byte("enrollment")
+	// /api/v1/attestation/sign signs any data with the app key
+	appSignature, _ := encryption.Sign(id.AppKey, Signable(payload))
+	deviceSignature, _ := encryption.Sign(id.DeviceKey, Signable(payload))
+
+	if _, err := Verify(id.AppChain, id.VendorRootPubKey, nil, payload, appSignature); !errors.Is(err, constants.ErrorInvalidChainLength) {
+		t.Errorf("app chain: expected %v, got %v", constants.ErrorInvalidChainLength, err)
+	}
+	if _, err := Verify(nil, id.VendorRootPubKey, nil, payload, deviceSignature); !errors.Is(err, constants.ErrorInvalidChainLength) {
+		t.Errorf("empty chain: expected %v, got %v", constants.ErrorInvalidChainLength, err)
+	}
+	verified, err := Verify(id.DeviceChain, id.VendorRootPubKey, nil, payload, deviceSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(verified.DeviceKey) != string(id.DevicePubKey) {
+		t.Errorf("expected device key %x, got %x", id.DevicePubKey, verified.DeviceKey)
+	}
+}
+
+func TestStructuredIsNotEnrollment(t *testing.T) {
+	config.Set(&config.Config{
+		VendorRoot: "dbbe0cd0b4c7bc4ab34829c96f35bb0011d06dc3bdf0b900401a71a8f7c4c471",
+		RootKey:    "cd2f10b3d7d306a27199ccf51868c1b0859f824b6fab53710f06a092ae40226f",
+		AppName:    "EmulatorDefault",
+	})
+	id := identity.Get()
+	now := time.Unix(1700000000, 0)
+	fields := Fields{ChainId: 1, Deadline: uint64(now.Unix()) + 60}
+	// /api/v1/device/sign over the canonical encoding
+	enrollmentSignature, _ := encryption.Sign(id.DeviceKey, Signable(fields.Canonical()))
+	if _, err := VerifyStructured(id.DeviceChain, id.VendorRootPubKey, nil, EncodingCanonical, fields, enrollmentSignature, now); !errors.Is(err, constants.ErrorSignatureMismatch) {
+		t.Errorf("expected %v, got %v", constants.ErrorSignatureMismatch, err)
+	}
+	signable, _ := StructuredSignable(EncodingCanonical, fields)
+	signature, _ := encryption.Sign(id.DeviceKey, signable)
+	if _, err := VerifyStructured(id.DeviceChain, id.VendorRootPubKey, nil, EncodingCanonical, fields, signature, now); err != nil {
+		t.Error(err)
+	}
+}
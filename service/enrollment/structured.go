@@ -0,0 +1,111 @@
+package enrollment
+
+import (
+	"encoding/binary"
+	"teerminal/constants"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Structured enrollments bind the device signature to a chain, a registry contract, an owner and a deadline,
+// so that an enrollment for one chain or registry can not be replayed on another
+
+const (
+	EncodingCanonical = "canonical" // EncodingCanonical signs abi.encode(chainId, registry, owner, deadline) as an enrollment payload
+	EncodingEip712    = "eip712"    // EncodingEip712 signs the EIP-712 typed data hash of Enrollment(owner, deadline)
+)
+
+const (
+	DomainType     = "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"
+	EnrollmentType = "Enrollment(address owner,uint256 deadline)"
+)
+
+type Fields struct {
+	ChainId  uint64
+	Registry common.Address // Registry is the contract the enrollment is submitted to, the EIP-712 verifying contract
+	Owner    common.Address
+	Deadline uint64 // Deadline is the unix timestamp in seconds after which the enrollment is refused
+}
+
+func uint256(v uint64) []byte {
+	word := make([]byte, 32)
+	binary.BigEndian.PutUint64(word[24:], v)
+	return word
+}
+
+func address(a common.Address) []byte {
+	return common.LeftPadBytes(a.Bytes(), 32)
+}
+
+// Validate checks the fields, the deadline must be after now
+func (f Fields) Validate(now time.Time) error {
+	if f.ChainId == 0 {
+		return constants.ErrorInvalidChainId
+	}
+	if f.Deadline <= uint64(now.Unix()) {
+		return constants.ErrorEnrollmentDeadlineExpired
+	}
+	return nil
+}
+
+// Canonical encodes the fields as abi.encode(uint256 chainId, address registry, address owner, uint256 deadline)
+func (f Fields) Canonical() (encoded []byte) {
+	encoded = append(encoded, uint256(f.ChainId)...)
+	encoded = append(encoded, address(f.Registry)...)
+	encoded = append(encoded, address(f.Owner)...)
+	encoded = append(encoded, uint256(f.Deadline)...)
+	return
+}
+
+// DomainSeparator is the EIP-712 domain separator:
+// keccak256(typeHash || keccak256(name) || keccak256(version) || chainId || registry)
+func (f Fields) DomainSeparator() []byte {
+	return crypto.Keccak256(
+		crypto.Keccak256([]byte(DomainType)),
+		crypto.Keccak256([]byte(constants.EnrollmentDomainName)),
+		crypto.Keccak256([]byte(constants.EnrollmentDomainVersion)),
+		uint256(f.ChainId),
+		address(f.Registry),
+	)
+}
+
+// StructHash is the EIP-712 hash of the enrollment: keccak256(typeHash || owner || deadline)
+func (f Fields) StructHash() []byte {
+	return crypto.Keccak256(crypto.Keccak256([]byte(EnrollmentType)), address(f.Owner), uint256(f.Deadline))
+}
+
+// TypedData is the EIP-712 payload "\x19\x01" || domainSeparator || structHash, whose keccak256 is the signed digest
+func (f Fields) TypedData() []byte {
+	return append(append([]byte{0x19, 0x01}, f.DomainSeparator()...), f.StructHash()...)
+}
+
+// StructuredSignable builds the payload signed by the device root key for a structured enrollment
+// canonical: "TEERMINAL_STRUCTURED_ENROLLMENT:" || keccak256(abi.encode(chainId, registry, owner, deadline))
+// eip712: "\x19\x01" || domainSeparator || structHash
+// The canonical prefix differs from Signable's, so a signature of /api/v1/device/sign over the same bytes, which skips
+// the deadline check, is never a structured enrollment
+func StructuredSignable(encoding string, f Fields) ([]byte, error) {
+	switch encoding {
+	case "", EncodingCanonical:
+		return append([]byte(constants.StructuredEnrollmentKey), crypto.Keccak256(f.Canonical())...), nil
+	case EncodingEip712:
+		return f.TypedData(), nil
+	default:
+		return nil, constants.ErrorUnknownEnrollmentEncoding
+	}
+}
+
+// VerifyStructured checks the fields against now, then verifies the device chain and the structured enrollment
+// signature the same way as Verify
+func VerifyStructured(chain []byte, root []byte, deviceKey []byte, encoding string, f Fields, signature []byte, now time.Time) (Verified, error) {
+	if err := f.Validate(now); err != nil {
+		return Verified{}, err
+	}
+	signable, err := StructuredSignable(encoding, f)
+	if err != nil {
+		return Verified{}, err
+	}
+	return verifySignable(chain, root, deviceKey, f.Canonical(), signable, signature)
+}
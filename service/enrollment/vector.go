@@ -0,0 +1,66 @@
+package enrollment
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Vector is a shared enrollment verification case, generated by cmd/test_vectors from the emulator's keys
+// Backends run the same cases with Check, so they agree with the emulator and CertLib on what is accepted,
+// the golden cases are in testdata/vectors.json
+type Vector struct {
+	Name          string `json:"name"`
+	Chain         string `json:"chain"`
+	Root          string `json:"root"`
+	DeviceKey     string `json:"deviceKey,omitempty"`
+	Payload       string `json:"payload"`
+	Signature     string `json:"signature"`
+	DeviceAddress string `json:"deviceAddress,omitempty"` // DeviceAddress is the expected verified address, empty if the case must fail
+	Error         string `json:"error,omitempty"`         // Error is the expected error message, empty if the case must pass
+}
+
+// NewVector records a case with its expected outcome, deviceAddress if it must pass or expected if it must fail
+// The outcome is given by the case, not by running Verify, so the vectors check Verify instead of repeating it
+func NewVector(name string, chain []byte, root []byte, deviceKey []byte, payload []byte, signature []byte, deviceAddress common.Address, expected error) Vector {
+	v := Vector{
+		Name:      name,
+		Chain:     hex.EncodeToString(chain),
+		Root:      hex.EncodeToString(root),
+		DeviceKey: hex.EncodeToString(deviceKey),
+		Payload:   hex.EncodeToString(payload),
+		Signature: hex.EncodeToString(signature),
+	}
+	if expected != nil {
+		v.Error = expected.Error()
+	} else {
+		v.DeviceAddress = deviceAddress.Hex()
+	}
+	return v
+}
+
+// Check runs Verify over the vector inputs, and returns an error if the outcome differs from the expected one
+func (v Vector) Check() error {
+	var fields [5][]byte
+	for i, s := range []string{v.Chain, v.Root, v.DeviceKey, v.Payload, v.Signature} {
+		b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return fmt.Errorf("%s: %w", v.Name, err)
+		}
+		fields[i] = b
+	}
+	verified, err := Verify(fields[0], fields[1], fields[2], fields[3], fields[4])
+	switch {
+	case err != nil && v.Error == "":
+		return fmt.Errorf("%s: expected %s, got error %q", v.Name, v.DeviceAddress, err)
+	case err != nil && err.Error() != v.Error:
+		return fmt.Errorf("%s: expected error %q, got error %q", v.Name, v.Error, err)
+	case err == nil && v.Error != "":
+		return fmt.Errorf("%s: expected error %q, got %s", v.Name, v.Error, verified.DeviceAddress.Hex())
+	case err == nil && verified.DeviceAddress.Hex() != v.DeviceAddress:
+		return fmt.Errorf("%s: expected %s, got %s", v.Name, v.DeviceAddress, verified.DeviceAddress.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package fault
+
+import (
+	"log"
+	"math/rand"
+	"sync"
+	"teerminal/constants"
+	"time"
+)
+
+// Fault injection for client robustness testing
+// A profile holds one rule per endpoint, every fault of a rule fires independently at its rate,
+// and every injected fault is recorded as an event, so test assertions can correlate them
+
+const (
+	AnyEndpoint = "*"
+
+	KindLatency       = "latency"
+	KindServerError   = "server_error"
+	KindCorruptCert   = "corrupt_cert"
+	KindTruncateChain = "truncate_chain"
+	KindDowngrade     = "downgrade_platform"
+	KindFlipSignature = "flip_signature"
+)
+
+// Rule is the faults of one endpoint, rates are probabilities between 0 and 1
+type Rule struct {
+	Endpoint          string  `json:"endpoint"`          // Endpoint is the route path, e.g. /api/v1/device/version, or * for every endpoint without its own rule
+	LatencyMs         int     `json:"latencyMs"`         // LatencyMs is added to every matching request
+	ErrorRate         float64 `json:"errorRate"`         // ErrorRate is the rate of 5xx responses returned instead of calling the endpoint
+	ErrorStatus       int     `json:"errorStatus"`       // ErrorStatus is the status of injected errors, default is 500
+	CorruptCertRate   float64 `json:"corruptCertRate"`   // CorruptCertRate is the rate of corrupting the signature of the last cert of chains
+	TruncateChainRate float64 `json:"truncateChainRate"` // TruncateChainRate is the rate of dropping the last cert of chains
+	DowngradeRate     float64 `json:"downgradeRate"`     // DowngradeRate is the rate of decrementing teePlatformVer
+	FlipSignatureRate float64 `json:"flipSignatureRate"` // FlipSignatureRate is the rate of flipping one bit of signatures
+}
+
+type Profile struct {
+	Rules []Rule `json:"rules"`
+}
+
+type Event struct {
+	Id       uint64    `json:"id"`
+	Time     time.Time `json:"time"`
+	Method   string    `json:"method"`
+	Endpoint string    `json:"endpoint"`
+	Kind     string    `json:"kind"`
+	Detail   string    `json:"detail,omitempty"` // Detail is the affected field, or the injected latency or status
+}
+
+var (
+	mu      sync.Mutex
+	profile Profile
+	events  []Event
+	lastId  uint64
+)
+
+func validRate(rate float64) bool {
+	return rate >= 0 && rate <= 1
+}
+
+// Validate checks the rates, latency and status of every rule
+func (p Profile) Validate() error {
+	seen := map[string]bool{}
+	for _, r := range p.Rules {
+		if r.Endpoint == "" || seen[r.Endpoint] {
+			return constants.ErrorInvalidFaultProfile
+		}
+		seen[r.Endpoint] = true
+		if r.LatencyMs < 0 || r.LatencyMs > constants.MaxFaultLatencyMs {
+			return constants.ErrorInvalidFaultProfile
+		}
+		if r.ErrorStatus != 0 && (r.ErrorStatus < 500 || r.ErrorStatus > 599) {
+			return constants.ErrorInvalidFaultProfile
+		}
+		for _, rate := range []float64{r.ErrorRate, r.CorruptCertRate, r.TruncateChainRate, r.DowngradeRate, r.FlipSignatureRate} {
+			if !validRate(rate) {
+				return constants.ErrorInvalidFaultProfile
+			}
+		}
+	}
+	return nil
+}
+
+// Set replaces the active profile
+func Set(p Profile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	profile = Profile{Rules: append([]Rule{}, p.Rules...)}
+	return nil
+}
+
+// Get returns a copy of the active profile
+func Get() Profile {
+	mu.Lock()
+	defer mu.Unlock()
+	return Profile{Rules: append([]Rule{}, profile.Rules...)}
+}
+
+// Match returns the rule of the endpoint, falling back to the * rule
+func Match(endpoint string) (Rule, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	var fallback *Rule
+	for i, r := range profile.Rules {
+		if r.Endpoint == endpoint {
+			return r, true
+		}
+		if r.Endpoint == AnyEndpoint {
+			fallback = &profile.Rules[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return Rule{}, false
+}
+
+// Fire rolls the dice for a fault of the given rate
+func Fire(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
+
+// Record logs an injected fault, and returns its event id
+func Record(method string, endpoint string, kind string, detail string) uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	lastId++
+	events = append(events, Event{Id: lastId, Time: time.Now(), Method: method, Endpoint: endpoint, Kind: kind, Detail: detail})
+	if len(events) > constants.MaxFaultEvents {
+		events = events[len(events)-constants.MaxFaultEvents:]
+	}
+	log.Printf("fault injected: id=%d %s %s kind=%s detail=%s", lastId, method, endpoint, kind, detail)
+	return lastId
+}
+
+// Events returns the recorded events with an id greater than since
+func Events(since uint64) []Event {
+	mu.Lock()
+	defer mu.Unlock()
+	var out []Event
+	for _, e := range events {
+		if e.Id > since {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Reset clears the profile and the recorded events
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	profile = Profile{}
+	events = nil
+}
@@ -0,0 +1,129 @@
+package handshake
+
+import (
+	"bytes"
+	"sync"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Mutual attestation sessions between two devices
+// Both devices attest over the other's nonce and their own ephemeral key, the session key is derived from the
+// ephemeral ECDH secret and the transcript, and each device signs the session with its device root key
+
+// Session is a session key agreed with a peer device, identified by the transcript hash
+type Session struct {
+	Id             []byte
+	Key            []byte
+	Initiator      bool
+	PeerDeviceKey  []byte // PeerDeviceKey is the leaf of the peer's device cert chain
+	LocalSignature []byte
+	PeerSignature  []byte // PeerSignature is nil until the peer has signed the session
+	CreatedAt      time.Time
+}
+
+func (s *Session) Established() bool {
+	return s.PeerSignature != nil
+}
+
+var (
+	mu       sync.Mutex
+	sessions = map[string]*Session{}
+)
+
+// GenerateEphemeralKey returns a fresh private key and its 64 bytes public key
+func GenerateEphemeralKey() ([]byte, []byte, error) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return key.Serialize(), key.PubKey().SerializeUncompressed()[1:], nil
+}
+
+// Transcript is keccak256(initiator nonce || responder nonce || initiator ephemeral key || responder ephemeral key
+// || initiator device key || responder device key), where a device's nonce is the one issued by its peer
+func Transcript(initiatorNonce, responderNonce, initiatorEphemeral, responderEphemeral, initiatorDevice, responderDevice []byte) []byte {
+	return crypto.Keccak256(initiatorNonce, responderNonce, initiatorEphemeral, responderEphemeral, initiatorDevice, responderDevice)
+}
+
+// DeriveKey derives the session key: keccak256("TEERMINAL_SESSION_KEY:" || ECDH x coordinate || transcript)
+func DeriveKey(ephemeralKey []byte, peerEphemeralKey []byte, transcript []byte) ([]byte, error) {
+	peer, err := secp256k1.ParsePubKey(append([]byte{0x04}, peerEphemeralKey...))
+	if err != nil {
+		return nil, constants.ErrorInvalidEphemeralKey
+	}
+	shared := secp256k1.GenerateSharedSecret(secp256k1.PrivKeyFromBytes(ephemeralKey), peer)
+	return crypto.Keccak256([]byte(constants.SessionKeyPrefix), shared, transcript), nil
+}
+
+// Signable is the payload signed by both devices: "TEERMINAL_SESSION:" || transcript || keccak256(session key)
+// Signing the key hash confirms both devices derived the same key
+func Signable(id []byte, key []byte) []byte {
+	signable := append([]byte(constants.SessionSignPrefix), id...)
+	return append(signable, crypto.Keccak256(key)...)
+}
+
+// prune drops the sessions the peer has not signed in time, must hold mu
+func prune(now time.Time) {
+	for id, s := range sessions {
+		if !s.Established() && now.Sub(s.CreatedAt) > config.GetChallengeTtl() {
+			delete(sessions, id)
+		}
+	}
+}
+
+// Put stores a session, evicting the oldest one when the store is full
+func Put(s *Session) {
+	mu.Lock()
+	defer mu.Unlock()
+	prune(time.Now())
+	if len(sessions) >= constants.MaxSessions {
+		var oldest string
+		for id, candidate := range sessions {
+			if oldest == "" || candidate.CreatedAt.Before(sessions[oldest].CreatedAt) {
+				oldest = id
+			}
+		}
+		delete(sessions, oldest)
+	}
+	sessions[string(s.Id)] = s
+}
+
+// Get returns a copy of the session
+func Get(id []byte) (Session, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	prune(time.Now())
+	s, ok := sessions[string(id)]
+	if !ok {
+		return Session{}, constants.ErrorSessionDoesNotExist
+	}
+	return *s, nil
+}
+
+// Confirm records the peer's signature over the session, and returns the established session
+func Confirm(id []byte, signature []byte) (Session, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	prune(time.Now())
+	s, ok := sessions[string(id)]
+	if !ok {
+		return Session{}, constants.ErrorSessionDoesNotExist
+	}
+	if s.Established() {
+		if bytes.Equal(s.PeerSignature, signature) {
+			return *s, nil
+		}
+		return Session{}, constants.ErrorSessionAlreadyEstablished
+	}
+	if !encryption.VerifySignature(s.PeerDeviceKey, Signable(s.Id, s.Key), signature) {
+		return Session{}, constants.ErrorSessionSignatureMismatch
+	}
+	s.PeerSignature = signature
+	return *s, nil
+}
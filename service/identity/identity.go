@@ -0,0 +1,80 @@
+package identity
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+)
+
+// Snapshot holds the keys and cert chains derived from one config generation
+// A snapshot is shared between requests and must not be modified
+type Snapshot struct {
+	Generation       uint64
+	VendorRootPubKey []byte // VendorRootPubKey is the 64 bytes vendor root public key
+	DeviceKey        []byte // DeviceKey is the device root private key, derived from the root key
+	DevicePubKey     []byte
+	DeviceChain      []byte // DeviceChain is device cert || device root cert
+	AppKey           []byte // AppKey is the application private key, derived from the device root key
+	AppPubKey        []byte
+	AppChain         []byte // AppChain is device cert || device root cert || application cert
+}
+
+var snapshot atomic.Pointer[Snapshot]
+var mu sync.Mutex
+
+// Get returns the snapshot of the current config generation, deriving it on first use
+func Get() *Snapshot {
+	gen := config.Generation()
+	if s := snapshot.Load(); s != nil && s.Generation == gen {
+		return s
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if s := snapshot.Load(); s != nil && s.Generation == gen {
+		return s
+	}
+	s := derive(gen)
+	snapshot.Store(s)
+	return s
+}
+
+// deriveAppKey derives the app key from the device root key, the leaf of the app chain
+// It used to be derived from the root key, which did not match the chain, see the CHANGELOG for the migration
+func deriveAppKey(deviceKey []byte, appName []byte) []byte {
+	return encryption.DerivePrivateKey(deviceKey, appName)
+}
+
+func derive(gen uint64) *Snapshot {
+	rootKey := config.GetRootKey()
+	appName := []byte(config.GetConfig().AppName)
+	// Device Cert: vendor root -> root key
+	deviceCert := encryption.GetDeviceRootCert()
+	// Device Root Cert: root key -> device root key
+	deviceKey := encryption.DerivePrivateKey(rootKey, []byte(constants.DeviceRootKey))
+	deviceRootCert := encryption.GenerateCert(rootKey, []byte(constants.DeviceRootKey))
+	// Application Cert: device root key -> application key
+	appKey := deriveAppKey(deviceKey, appName)
+	applicationCert := encryption.GenerateCert(deviceKey, appName)
+
+	var deviceChain []byte
+	deviceChain = append(deviceChain, deviceCert...)
+	deviceChain = append(deviceChain, deviceRootCert...)
+	var appChain []byte
+	appChain = append(appChain, deviceChain...)
+	appChain = append(appChain, applicationCert...)
+
+	// Clip every slice so appending to it always copies instead of writing into the snapshot
+	return &Snapshot{
+		Generation:       gen,
+		VendorRootPubKey: slices.Clip(encryption.GetPublicKey(config.GetVendorRoot())),
+		DeviceKey:        slices.Clip(deviceKey),
+		DevicePubKey:     slices.Clip(encryption.GetPublicKey(deviceKey)),
+		DeviceChain:      slices.Clip(deviceChain),
+		AppKey:           slices.Clip(appKey),
+		AppPubKey:        slices.Clip(encryption.GetPublicKey(appKey)),
+		AppChain:         slices.Clip(appChain),
+	}
+}
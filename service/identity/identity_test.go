@@ -0,0 +1,74 @@
+package identity_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/web"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The endpoints serving derived keys run in parallel to measure their throughput under concurrent load, see
+// BenchmarkSnapshot for the snapshot cache against a derivation on every request:
+// go test -run ^$ -bench . ./service/identity
+
+func newRouter() *gin.Engine {
+	config.Set(&config.Config{
+		Version:            "0.0.1-bench",
+		TeePlatformVersion: 1,
+		VendorRoot:         "dbbe0cd0b4c7bc4ab34829c96f35bb0011d06dc3bdf0b900401a71a8f7c4c471",
+		RootKey:            "cd2f10b3d7d306a27199ccf51868c1b0859f824b6fab53710f06a092ae40226f",
+		AppName:            "EmulatorDefault",
+	})
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	web.RegisterRoutes(router)
+	return router
+}
+
+// benchmarkEndpoint runs the requests built by newRequest in parallel
+func benchmarkEndpoint(b *testing.B, newRequest func() *http.Request) {
+	router := newRouter()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, newRequest())
+			if w.Code != 200 {
+				b.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+			}
+		}
+	})
+}
+
+func BenchmarkDeviceKey(b *testing.B) {
+	benchmarkEndpoint(b, func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/api/v1/device/key", nil)
+	})
+}
+
+// BenchmarkVersionAttestation signs the attestation the way /device/version does once the nonce is consumed, since
+// nonces are single-use and at most constants.MaxChallenges of them are outstanding until they expire
+func BenchmarkVersionAttestation(b *testing.B) {
+	newRouter()
+	nonce := make([]byte, constants.ChallengeLength)
+	requesterPubKey := encryption.GetPublicKey(encryption.DerivePrivateKey([]byte("requester"), []byte("bench")))
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if attestation := web.SignVersionAttestation(nonce, requesterPubKey); attestation.Signature == "" {
+				b.Fatal("missing signature")
+			}
+		}
+	})
+}
+
+func BenchmarkAppKey(b *testing.B) {
+	benchmarkEndpoint(b, func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/api/v1/attestation/appkey", nil)
+	})
+}
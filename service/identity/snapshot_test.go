@@ -0,0 +1,33 @@
+package identity
+
+import (
+	"teerminal/config"
+	"testing"
+)
+
+// BenchmarkSnapshot compares the snapshot cache with deriving the keys and chains on every call, as before the cache,
+// in parallel
+func BenchmarkSnapshot(b *testing.B) {
+	config.Set(&config.Config{
+		VendorRoot: "dbbe0cd0b4c7bc4ab34829c96f35bb0011d06dc3bdf0b900401a71a8f7c4c471",
+		RootKey:    "cd2f10b3d7d306a27199ccf51868c1b0859f824b6fab53710f06a092ae40226f",
+		AppName:    "EmulatorDefault",
+	})
+	for _, mode := range []struct {
+		name string
+		get  func() *Snapshot
+	}{
+		{"cached", Get},
+		{"uncached", func() *Snapshot { return derive(config.Generation()) }},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if s := mode.get(); len(s.AppChain) == 0 {
+						b.Fatal("missing app chain")
+					}
+				}
+			})
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package kv
 
-import "sync"
+import (
+	"sync"
+	"teerminal/constants"
+)
 
 type Entry struct {
 	Key         string `json:"key"`
@@ -9,8 +12,25 @@ type Entry struct {
 	Protector   string `json:"protector"`
 }
 
+const (
+	OpWrite  = "write"
+	OpDelete = "delete"
+)
+
+// Change is sent to the subscribers on every write and delete, it carries no value since the key may be protected
+type Change struct {
+	Op  string `json:"op"`
+	Key string `json:"key"`
+}
+
 var entries = sync.Map{}
 
+// mu serializes the changes, so subscribers see them in the order they are applied
+var (
+	mu          sync.Mutex
+	subscribers = map[chan Change]bool{}
+)
+
 func Exists(key string) bool {
 	_, ok := entries.Load(key)
 	return ok
@@ -24,7 +44,10 @@ func Load(key string) (Entry, bool) {
 }
 
 func Store(entry Entry) {
+	mu.Lock()
+	defer mu.Unlock()
 	entries.Store(entry.Key, entry)
+	notify(Change{Op: OpWrite, Key: entry.Key})
 }
 
 func Length() int {
@@ -37,5 +60,37 @@ func Length() int {
 }
 
 func Delete(key string) {
+	mu.Lock()
+	defer mu.Unlock()
 	entries.Delete(key)
+	notify(Change{Op: OpDelete, Key: key})
+}
+
+func notify(change Change) {
+	for ch := range subscribers {
+		select {
+		case ch <- change:
+		default:
+			// The subscriber fell behind, close it instead of skipping changes
+			delete(subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns a channel of the following changes, which is closed if the subscriber falls behind
+func Subscribe() (chan Change, func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	ch := make(chan Change, constants.KvSubscriberBuffer)
+	subscribers[ch] = true
+	cancel := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if subscribers[ch] {
+			delete(subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
 }
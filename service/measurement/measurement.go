@@ -0,0 +1,133 @@
+package measurement
+
+import (
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+	"teerminal/config"
+	"teerminal/constants"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Simulated measurement registers, every register starts from zero and can only be extended:
+// new = keccak256(old || data)
+// Boot measurements from the config are extended first, and every extension is recorded in the event log
+// A config reload changing the boot measurements resets the registers, the new event log starts with the reset event
+// followed by the new boot measurements, so a reset device never attests the same registers as a freshly booted one
+
+type Event struct {
+	Register    int    `json:"register"`
+	Data        string `json:"data" format:"hex"` // Data is the hex encoded extended data
+	Description string `json:"description,omitempty"`
+}
+
+// The reset event extends register 0 with "reset"
+const (
+	resetData        = "reset"
+	resetDescription = "boot measurements changed"
+)
+
+var (
+	mu         sync.Mutex
+	generation uint64
+	boot       []config.Measurement // boot is the applied boot measurements
+	registers  [][]byte
+	events     []Event
+)
+
+func reset() {
+	registers = make([][]byte, constants.MeasurementRegisterCount)
+	for i := range registers {
+		registers[i] = make([]byte, 32)
+	}
+	events = nil
+}
+
+// refresh resets the registers and replays the boot measurements when the boot measurements changed, must hold mu
+func refresh() {
+	gen := config.Generation()
+	if registers != nil && generation == gen {
+		return
+	}
+	generation = gen
+	measurements := config.GetConfig().BootMeasurements
+	if registers != nil && slices.Equal(boot, measurements) {
+		return
+	}
+	reloaded := registers != nil
+	reset()
+	boot = append([]config.Measurement{}, measurements...)
+	if reloaded {
+		extend(0, []byte(resetData), resetDescription)
+	}
+	for _, m := range measurements {
+		// Boot measurement data is hex, anything else is measured as raw text
+		data, err := hex.DecodeString(strings.TrimPrefix(m.Data, "0x"))
+		if err != nil {
+			data = []byte(m.Data)
+		}
+		extend(m.Register, data, m.Description)
+	}
+}
+
+// extend must hold mu, loaded configs are validated, only a config passed to config.Set can carry an invalid register
+func extend(register int, data []byte, description string) []byte {
+	if register < 0 || register >= len(registers) {
+		return nil
+	}
+	registers[register] = crypto.Keccak256(registers[register], data)
+	events = append(events, Event{Register: register, Data: hex.EncodeToString(data), Description: description})
+	return registers[register]
+}
+
+// Extend extends the register with data, and returns the new register value
+func Extend(register int, data []byte, description string) ([]byte, error) {
+	if register < 0 || register >= constants.MeasurementRegisterCount {
+		return nil, constants.ErrorInvalidMeasurementRegister
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	refresh()
+	if len(events) >= constants.MaxMeasurementEvents {
+		return nil, constants.ErrorMeasurementLogFull
+	}
+	return append([]byte{}, extend(register, data, description)...), nil
+}
+
+// Snapshot returns a copy of the current register values and event log
+func Snapshot() ([][]byte, []Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	refresh()
+	values := make([][]byte, len(registers))
+	for i := range registers {
+		values[i] = append([]byte{}, registers[i]...)
+	}
+	return values, append([]Event{}, events...)
+}
+
+// Replay computes the register values from an event log
+func Replay(log []Event) ([][]byte, error) {
+	values := make([][]byte, constants.MeasurementRegisterCount)
+	for i := range values {
+		values[i] = make([]byte, 32)
+	}
+	for _, event := range log {
+		if event.Register < 0 || event.Register >= len(values) {
+			return nil, constants.ErrorInvalidMeasurementRegister
+		}
+		data, err := hex.DecodeString(strings.TrimPrefix(event.Data, "0x"))
+		if err != nil {
+			return nil, constants.ErrorInvalidMeasurementEvent
+		}
+		values[event.Register] = crypto.Keccak256(values[event.Register], data)
+	}
+	return values, nil
+}
+
+// Digest is keccak256(register 0 || ... || register n), which is bound into attestations
+func Digest(values [][]byte) []byte {
+	return crypto.Keccak256(values...)
+}
@@ -0,0 +1,59 @@
+package measurement
+
+import (
+	"bytes"
+	"teerminal/config"
+	"testing"
+)
+
+func setBoot(appName string, boot ...config.Measurement) {
+	config.Set(&config.Config{AppName: appName, BootMeasurements: boot})
+}
+
+func checkReplay(t *testing.T) ([][]byte, []Event) {
+	t.Helper()
+	values, events := Snapshot()
+	replayed, err := Replay(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(Digest(values), Digest(replayed)) {
+		t.Fatal("event log does not replay to the registers")
+	}
+	return values, events
+}
+
+func TestRefresh(t *testing.T) {
+	bootloader := config.Measurement{Register: 0, Data: "00ff", Description: "bootloader"}
+	kernel := config.Measurement{Register: 1, Data: "kernel", Description: "kernel"}
+
+	setBoot("a", bootloader)
+	if _, err := Extend(2, []byte("app"), "app"); err != nil {
+		t.Fatal(err)
+	}
+	booted, events := checkReplay(t)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	// A reload keeping the boot measurements keeps the registers and the runtime extensions
+	setBoot("b", bootloader)
+	values, events := checkReplay(t)
+	if len(events) != 2 || !bytes.Equal(Digest(values), Digest(booted)) {
+		t.Fatal("registers reset without a boot measurements change")
+	}
+
+	// Changing the boot measurements resets the registers, the reset is the first event
+	setBoot("b", bootloader, kernel)
+	values, events = checkReplay(t)
+	if len(events) != 3 || events[0].Description != resetDescription {
+		t.Fatalf("expected the reset event and 2 boot measurements, got %+v", events)
+	}
+
+	// A reset device does not attest the registers of a freshly booted one
+	registers = nil
+	fresh, _ := checkReplay(t)
+	if bytes.Equal(Digest(values), Digest(fresh)) {
+		t.Fatal("reset registers equal freshly booted registers")
+	}
+}
@@ -0,0 +1,222 @@
+package nitro
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"teerminal/config"
+	"teerminal/service/encryption"
+	"teerminal/service/identity"
+	"time"
+
+	"github.com/ugorji/go/codec"
+)
+
+// Emulated AWS Nitro Enclaves attestation document
+// The document is an untagged COSE_Sign1 [protected, unprotected, payload, signature] signed with ES384,
+// the payload is the CBOR encoded AttestationDocument
+
+const (
+	PcrCount       = 16
+	MeasurementPcr = 16 // MeasurementPcr is the first PCR holding a measurement register
+	coseAlgEs384   = -35
+	coseHeaderAlg  = 1
+)
+
+// AttestationDocument follows the Nitro Enclaves attestation document layout
+type AttestationDocument struct {
+	ModuleId    string         `codec:"module_id"`
+	Digest      string         `codec:"digest"`
+	Timestamp   uint64         `codec:"timestamp"` // Timestamp is UTC milliseconds since epoch
+	Pcrs        map[int][]byte `codec:"pcrs"`
+	Certificate []byte         `codec:"certificate"` // Certificate is the DER encoded signing cert
+	Cabundle    [][]byte       `codec:"cabundle"`    // Cabundle is root cert || intermediate certs, DER encoded
+	PublicKey   []byte         `codec:"public_key"`
+	UserData    []byte         `codec:"user_data"`
+	Nonce       []byte         `codec:"nonce"`
+}
+
+// Certs is the mock Nitro cert chain and the document signing key
+type Certs struct {
+	Generation  uint64
+	SigningKey  *ecdsa.PrivateKey
+	Certificate []byte
+	Cabundle    [][]byte
+}
+
+var certs atomic.Pointer[Certs]
+var mu sync.Mutex
+
+var certNotBefore = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+var certNotAfter = time.Date(2049, 12, 31, 23, 59, 59, 0, time.UTC)
+
+var cborHandle = &codec.CborHandle{BasicHandle: codec.BasicHandle{EncodeOptions: codec.EncodeOptions{Canonical: true}}}
+
+func encodeCbor(v interface{}) ([]byte, error) {
+	var out []byte
+	err := codec.NewEncoderBytes(&out, cborHandle).Encode(v)
+	return out, err
+}
+
+// ModuleId is the simulated enclave id, derived from the device public key
+func ModuleId() string {
+	h := sha256.Sum256(identity.Get().DevicePubKey)
+	return "i-" + hex.EncodeToString(h[:8]) + "-enc" + hex.EncodeToString(h[8:16])
+}
+
+// Pcrs are the simulated platform configuration registers
+// PCR0: sha384(AppName), PCR1: sha384(version), PCR2: sha384(AppName || version),
+// PCR4: sha384(device public key), PCR8: sha384(vendor root public key), others are zero
+func Pcrs() map[int][]byte {
+	cfg := config.GetConfig()
+	id := identity.Get()
+	pcrs := map[int][]byte{}
+	for i := 0; i < PcrCount; i++ {
+		pcrs[i] = make([]byte, sha512.Size384)
+	}
+	set := func(i int, data []byte) {
+		h := sha512.Sum384(data)
+		pcrs[i] = h[:]
+	}
+	set(0, []byte(cfg.AppName))
+	set(1, []byte(cfg.Version))
+	set(2, []byte(cfg.AppName+cfg.Version))
+	set(4, id.DevicePubKey)
+	set(8, id.VendorRootPubKey)
+	return pcrs
+}
+
+// GetCerts returns the mock cert chain of the current config generation, generating it on first use
+func GetCerts() (*Certs, error) {
+	gen := config.Generation()
+	if c := certs.Load(); c != nil && c.Generation == gen {
+		return c, nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if c := certs.Load(); c != nil && c.Generation == gen {
+		return c, nil
+	}
+	c, err := generateCerts(gen)
+	if err != nil {
+		return nil, err
+	}
+	certs.Store(c)
+	return c, nil
+}
+
+func generateCerts(gen uint64) (*Certs, error) {
+	// Root CA (vendor root) -> Zonal CA (vendor root) -> Enclave signing cert (device root key)
+	vendorRoot := config.GetVendorRoot()
+	id := identity.Get()
+	rootKey := encryption.DeriveEcdsaKey(elliptic.P384(), vendorRoot, "nitro_root_ca")
+	zonalKey := encryption.DeriveEcdsaKey(elliptic.P384(), vendorRoot, "nitro_zonal_ca")
+	signingKey := encryption.DeriveEcdsaKey(elliptic.P384(), id.DeviceKey, "nitro_enclave")
+
+	org := []string{"Teerminal Emulator"}
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "teerminal.nitro-enclaves", Organization: org},
+		NotBefore:             certNotBefore,
+		NotAfter:              certNotAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            1,
+	}
+	rootDer, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		return nil, err
+	}
+	zonalTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "zonal.teerminal.nitro-enclaves", Organization: org},
+		NotBefore:             certNotBefore,
+		NotAfter:              certNotAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	zonalDer, err := x509.CreateCertificate(rand.Reader, zonalTemplate, rootTemplate, &zonalKey.PublicKey, rootKey)
+	if err != nil {
+		return nil, err
+	}
+	zonalCert, err := x509.ParseCertificate(zonalDer)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(id.DevicePubKey)
+	signingTemplate := &x509.Certificate{
+		SerialNumber: new(big.Int).SetBytes(h[:16]),
+		Subject:      pkix.Name{CommonName: ModuleId() + ".teerminal.nitro-enclaves", Organization: org},
+		NotBefore:    certNotBefore,
+		NotAfter:     certNotAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	signingDer, err := x509.CreateCertificate(rand.Reader, signingTemplate, zonalCert, &signingKey.PublicKey, zonalKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Certs{
+		Generation:  gen,
+		SigningKey:  signingKey,
+		Certificate: signingDer,
+		Cabundle:    [][]byte{rootDer, zonalDer},
+	}, nil
+}
+
+// BuildDocument builds and signs the attestation document, the optional fields are encoded as null when nil
+// The measurement registers are reported as PCR16 onwards, zero padded to 48 bytes
+func BuildDocument(nonce []byte, userData []byte, publicKey []byte, registers [][]byte) ([]byte, error) {
+	c, err := GetCerts()
+	if err != nil {
+		return nil, err
+	}
+	pcrs := Pcrs()
+	for i, register := range registers {
+		pcr := make([]byte, sha512.Size384)
+		copy(pcr, register)
+		pcrs[MeasurementPcr+i] = pcr
+	}
+	payload, err := encodeCbor(AttestationDocument{
+		ModuleId:    ModuleId(),
+		Digest:      "SHA384",
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Pcrs:        pcrs,
+		Certificate: c.Certificate,
+		Cabundle:    c.Cabundle,
+		PublicKey:   publicKey,
+		UserData:    userData,
+		Nonce:       nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+	protected, err := encodeCbor(map[int]int{coseHeaderAlg: coseAlgEs384})
+	if err != nil {
+		return nil, err
+	}
+	// Sig_structure = ["Signature1", protected, external_aad, payload]
+	sigStructure, err := encodeCbor([]interface{}{"Signature1", protected, []byte{}, payload})
+	if err != nil {
+		return nil, err
+	}
+	hash := sha512.Sum384(sigStructure)
+	r, s, err := ecdsa.Sign(rand.Reader, c.SigningKey, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	signature := make([]byte, 96)
+	r.FillBytes(signature[:48])
+	s.FillBytes(signature[48:])
+	return encodeCbor([]interface{}{protected, map[int]interface{}{}, payload, signature})
+}
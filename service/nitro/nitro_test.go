@@ -0,0 +1,209 @@
+package nitro
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"strconv"
+	"teerminal/config"
+	"testing"
+	"time"
+
+	"github.com/ugorji/go/codec"
+)
+
+type documentVector struct {
+	Name       string            `json:"name"`
+	VendorRoot string            `json:"vendorRoot"`
+	RootKey    string            `json:"rootKey"`
+	AppName    string            `json:"appName"`
+	Version    string            `json:"version"`
+	Nonce      string            `json:"nonce"`
+	UserData   string            `json:"userData"`
+	PublicKey  string            `json:"publicKey"`
+	Registers  []string          `json:"registers"`
+	ModuleId   string            `json:"moduleId"`
+	Pcrs       map[string]string `json:"pcrs"`
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	if s == "" {
+		return nil
+	}
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+// cborBstr is the CBOR header of a byte string of length n followed by the bytes
+func cborBstr(b []byte) []byte {
+	var out []byte
+	switch n := len(b); {
+	case n < 24:
+		out = []byte{0x40 | byte(n)}
+	case n < 1<<8:
+		out = []byte{0x58, byte(n)}
+	case n < 1<<16:
+		out = binary.BigEndian.AppendUint16([]byte{0x59}, uint16(n))
+	default:
+		out = binary.BigEndian.AppendUint32([]byte{0x5a}, uint32(n))
+	}
+	return append(out, b...)
+}
+
+// sigStructure is the COSE Sig_structure ["Signature1", protected, external_aad, payload], encoded by hand
+func sigStructure(protected []byte, payload []byte) []byte {
+	out := []byte{0x84, 0x6a}
+	out = append(out, "Signature1"...)
+	out = append(out, cborBstr(protected)...)
+	out = append(out, cborBstr(nil)...)
+	return append(out, cborBstr(payload)...)
+}
+
+// decodeSign1 splits the untagged COSE_Sign1 document and verifies its ES384 signature
+func decodeSign1(t *testing.T, document []byte) (payload []byte) {
+	t.Helper()
+	var sign1 []interface{}
+	if err := codec.NewDecoderBytes(document, cborHandle).Decode(&sign1); err != nil {
+		t.Fatal(err)
+	}
+	if len(sign1) != 4 {
+		t.Fatalf("expected a 4 elements COSE_Sign1, got %d", len(sign1))
+	}
+	protected, _ := sign1[0].([]byte)
+	payload, _ = sign1[2].([]byte)
+	signature, _ := sign1[3].([]byte)
+	if !bytes.Equal(protected, []byte{0xa1, 0x01, 0x38, 0x22}) {
+		t.Fatalf("expected protected header {1: -35}, got %x", protected)
+	}
+	if unprotected, ok := sign1[1].(map[interface{}]interface{}); !ok || len(unprotected) != 0 {
+		t.Fatalf("expected an empty unprotected header, got %v", sign1[1])
+	}
+	if len(signature) != 96 {
+		t.Fatalf("expected a 96 bytes signature, got %d", len(signature))
+	}
+	var doc AttestationDocument
+	if err := codec.NewDecoderBytes(payload, cborHandle).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(doc.Certificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := cert.PublicKey.(*ecdsa.PublicKey)
+	hash := sha512.Sum384(sigStructure(protected, payload))
+	if !ecdsa.Verify(key, hash[:], new(big.Int).SetBytes(signature[:48]), new(big.Int).SetBytes(signature[48:])) {
+		t.Fatal("document signature does not verify against the certificate")
+	}
+	tampered := append([]byte{}, payload...)
+	tampered[len(tampered)-1] ^= 0x01
+	hash = sha512.Sum384(sigStructure(protected, tampered))
+	if ecdsa.Verify(key, hash[:], new(big.Int).SetBytes(signature[:48]), new(big.Int).SetBytes(signature[48:])) {
+		t.Fatal("document signature verifies over a tampered payload")
+	}
+	return payload
+}
+
+// verifyCabundle checks the certificate against the cabundle, whose first cert is the root
+func verifyCabundle(t *testing.T, doc AttestationDocument) {
+	t.Helper()
+	roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+	for i, der := range doc.Cabundle {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			roots.AddCert(cert)
+		} else {
+			intermediates.AddCert(cert)
+		}
+	}
+	cert, _ := x509.ParseCertificate(doc.Certificate)
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDocumentVectors checks against testdata/document_vectors.json, generated by testdata/document_reference.py,
+// an implementation of the document fields independent of nitro.go
+func TestDocumentVectors(t *testing.T) {
+	raw, err := os.ReadFile("testdata/document_vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []documentVector
+	if err := json.Unmarshal(raw, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no vectors")
+	}
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			config.Set(&config.Config{VendorRoot: v.VendorRoot, RootKey: v.RootKey, AppName: v.AppName, Version: v.Version})
+			nonce, userData, publicKey := mustDecodeHex(t, v.Nonce), mustDecodeHex(t, v.UserData), mustDecodeHex(t, v.PublicKey)
+			var registers [][]byte
+			for _, register := range v.Registers {
+				registers = append(registers, mustDecodeHex(t, register))
+			}
+			before := uint64(time.Now().UnixMilli())
+			document, err := BuildDocument(nonce, userData, publicKey, registers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			payload := decodeSign1(t, document)
+			var doc AttestationDocument
+			if err := codec.NewDecoderBytes(payload, cborHandle).Decode(&doc); err != nil {
+				t.Fatal(err)
+			}
+			verifyCabundle(t, doc)
+
+			if doc.ModuleId != v.ModuleId || doc.Digest != "SHA384" {
+				t.Errorf("expected module id %s digest SHA384, got %s %s", v.ModuleId, doc.ModuleId, doc.Digest)
+			}
+			if doc.Timestamp < before || doc.Timestamp > uint64(time.Now().UnixMilli()) {
+				t.Errorf("timestamp %d is not the time of the build", doc.Timestamp)
+			}
+			if len(doc.Pcrs) != len(v.Pcrs) {
+				t.Errorf("expected %d pcrs, got %d", len(v.Pcrs), len(doc.Pcrs))
+			}
+			for i, pcr := range doc.Pcrs {
+				if hex.EncodeToString(pcr) != v.Pcrs[strconv.Itoa(i)] {
+					t.Errorf("pcr %d: expected %s, got %x", i, v.Pcrs[strconv.Itoa(i)], pcr)
+				}
+			}
+			if !bytes.Equal(doc.Nonce, nonce) || !bytes.Equal(doc.UserData, userData) || !bytes.Equal(doc.PublicKey, publicKey) {
+				t.Errorf("expected nonce %x user data %x public key %x, got %x %x %x", nonce, userData, publicKey, doc.Nonce, doc.UserData, doc.PublicKey)
+			}
+			// Absent optional fields are present as null
+			var fields map[string]interface{}
+			if err := codec.NewDecoderBytes(payload, cborHandle).Decode(&fields); err != nil {
+				t.Fatal(err)
+			}
+			for name, value := range map[string][]byte{"nonce": nonce, "user_data": userData, "public_key": publicKey} {
+				field, ok := fields[name]
+				if !ok || (value == nil) != (field == nil) {
+					t.Errorf("%s: expected %x, got %v", name, value, field)
+				}
+			}
+			// The payload is canonical CBOR, decoding and encoding it again gives the same bytes
+			encoded, err := encodeCbor(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(encoded, payload) {
+				t.Error("payload does not round trip")
+			}
+		})
+	}
+}
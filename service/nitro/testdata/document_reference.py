@@ -0,0 +1,62 @@
+#!/usr/bin/env python3
+"""Reference Nitro attestation document fields, written from the attestation document layout independently of nitro.go.
+
+The device and vendor root public keys are inputs, identity derives them from the root keys. Prints the vectors of
+nitro_test.go as JSON: python3 document_reference.py > document_vectors.json
+"""
+import hashlib
+import json
+
+VENDOR_ROOT = "dbbe0cd0b4c7bc4ab34829c96f35bb0011d06dc3bdf0b900401a71a8f7c4c471"
+ROOT_KEY = "cd2f10b3d7d306a27199ccf51868c1b0859f824b6fab53710f06a092ae40226f"
+VENDOR_ROOT_PUB = ("ec250d04047eb40de9ab27cd4a983b09817bdff7e58050d7725bf45fffb6702a"
+                   "e1be201a59714a9b04d776aa4a984cd71e0d0e8573ab9516495d1a133dc49c7e")
+DEVICE_PUB = ("6e3f2aea1db21430f5975d3ef74ad45a60db33c2f5435af5e22b7418067a7ba9"
+              "52e7ed219ea0ab631fc7dd67bcf592916302a2d5fa58edabe9f406ae8ca9876f")
+PCR_COUNT = 16
+MEASUREMENT_PCR = 16
+
+CASES = [
+    {
+        "name": "empty",
+        "appName": "EmulatorDefault",
+        "version": "0.0.1-emulator",
+        "nonce": "",
+        "userData": "",
+        "publicKey": "",
+        "registers": ["00" * 32] * 8,
+    },
+    {
+        "name": "bound",
+        "appName": "vector app",
+        "version": "1.2.3",
+        "nonce": bytes(range(64)).hex(),
+        "userData": b"user data".hex(),
+        "publicKey": "04" + "11" * 64,
+        "registers": [hashlib.sha256(bytes([i])).hexdigest() for i in range(8)],
+    },
+]
+
+
+def sha384(data):
+    return hashlib.sha384(data).hexdigest()
+
+
+def vector(case):
+    device_hash = hashlib.sha256(bytes.fromhex(DEVICE_PUB)).hexdigest()
+    pcrs = {str(i): "00" * 48 for i in range(PCR_COUNT)}
+    pcrs["0"] = sha384(case["appName"].encode())
+    pcrs["1"] = sha384(case["version"].encode())
+    pcrs["2"] = sha384((case["appName"] + case["version"]).encode())
+    pcrs["4"] = sha384(bytes.fromhex(DEVICE_PUB))
+    pcrs["8"] = sha384(bytes.fromhex(VENDOR_ROOT_PUB))
+    for i, register in enumerate(case["registers"]):
+        pcrs[str(MEASUREMENT_PCR + i)] = register.ljust(96, "0")
+    return dict(case,
+                vendorRoot=VENDOR_ROOT,
+                rootKey=ROOT_KEY,
+                moduleId="i-" + device_hash[:16] + "-enc" + device_hash[16:32],
+                pcrs=pcrs)
+
+
+print(json.dumps([vector(case) for case in CASES], indent=2))
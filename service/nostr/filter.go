@@ -0,0 +1,49 @@
+package nostr
+
+// Filter is a NIP-01 subscription filter, only the fields used by the device are supported
+type Filter struct {
+	Kinds   []int    `json:"kinds,omitempty"`
+	Authors []string `json:"authors,omitempty"`
+	PTags   []string `json:"#p,omitempty"` // PTags are the pubkeys the events are addressed to
+	Since   int64    `json:"since,omitempty"`
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Match checks the event against every set field of the filter
+func (f Filter) Match(e Event) bool {
+	if len(f.Kinds) > 0 && !containsInt(f.Kinds, e.Kind) {
+		return false
+	}
+	if len(f.Authors) > 0 && !containsString(f.Authors, e.PubKey) {
+		return false
+	}
+	if f.Since > 0 && e.CreatedAt < f.Since {
+		return false
+	}
+	if len(f.PTags) > 0 {
+		for _, tag := range e.Tags {
+			if len(tag) >= 2 && tag[0] == "p" && containsString(f.PTags, tag[1]) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package nostr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"unicode/utf8"
+)
+
+// Event is a NIP-01 nostr event
+type Event struct {
+	Id        string     `json:"id" format:"hex"`     // Id is sha256 of the serialized event in hex
+	PubKey    string     `json:"pubkey" format:"hex"` // PubKey is the 32 bytes x-only public key in hex
+	CreatedAt int64      `json:"created_at"`          // CreatedAt is the unix timestamp in seconds
+	Kind      int        `json:"kind"`
+	Tags      [][]string `json:"tags"`
+	Content   string     `json:"content"`
+	Sig       string     `json:"sig" format:"hex"` // Sig is the 64 bytes BIP-340 signature of the id in hex
+}
+
+// ChainTag is the tag carrying the app cert chain, so the pubkey can be verified against the vendor root
+func ChainTag(chain []byte) []string {
+	return []string{constants.NostrChainTag, hex.EncodeToString(chain)}
+}
+
+// Validate checks the kind, tags and content against the limits
+// Strings must be valid UTF-8, JSON encoders would replace invalid bytes and change the serialized event
+func (e Event) Validate() error {
+	if e.Kind < 0 || e.Kind > constants.MaxNostrKind {
+		return constants.ErrorInvalidNostrKind
+	}
+	if len(e.Tags) > constants.MaxNostrTags {
+		return constants.ErrorInvalidNostrTags
+	}
+	for _, tag := range e.Tags {
+		if len(tag) == 0 {
+			return constants.ErrorInvalidNostrTags
+		}
+		for _, value := range tag {
+			if !utf8.ValidString(value) {
+				return constants.ErrorInvalidNostrTags
+			}
+		}
+	}
+	if len(e.Content) > constants.MaxNostrContentLength {
+		return constants.ErrorNostrContentTooLong
+	}
+	if !utf8.ValidString(e.Content) {
+		return constants.ErrorInvalidNostrContent
+	}
+	return nil
+}
+
+// escape encodes a string as a JSON string with the NIP-01 rules: only line feed, double quote, backslash,
+// carriage return, tab, backspace and form feed are escaped, everything else is written as is
+func escape(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\n':
+			b.WriteString(`\n`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+}
+
+// Serialize builds the NIP-01 serialization of the event: [0,<pubkey>,<created_at>,<kind>,<tags>,<content>]
+// without whitespace, which is the preimage of the event id
+func (e Event) Serialize() []byte {
+	var b strings.Builder
+	b.WriteString("[0,")
+	escape(&b, e.PubKey)
+	b.WriteByte(',')
+	b.WriteString(strconv.FormatInt(e.CreatedAt, 10))
+	b.WriteByte(',')
+	b.WriteString(strconv.Itoa(e.Kind))
+	b.WriteString(",[")
+	for i, tag := range e.Tags {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('[')
+		for j, value := range tag {
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			escape(&b, value)
+		}
+		b.WriteByte(']')
+	}
+	b.WriteString("],")
+	escape(&b, e.Content)
+	b.WriteByte(']')
+	return []byte(b.String())
+}
+
+// Hash is the event id: sha256 of the serialized event
+func (e Event) Hash() []byte {
+	hash := sha256.Sum256(e.Serialize())
+	return hash[:]
+}
+
+// Sign sets the pubkey to the x-only public key of the key, then computes the id and signs it with BIP-340
+func Sign(key []byte, e Event) (Event, error) {
+	if err := e.Validate(); err != nil {
+		return Event{}, err
+	}
+	if e.Tags == nil {
+		e.Tags = [][]string{}
+	}
+	xOnly, err := encryption.XOnlyPublicKey(encryption.GetPublicKey(key))
+	if err != nil {
+		return Event{}, err
+	}
+	e.PubKey = hex.EncodeToString(xOnly)
+	hash := e.Hash()
+	sig, err := encryption.SchnorrSign(key, hash)
+	if err != nil {
+		return Event{}, err
+	}
+	e.Id = hex.EncodeToString(hash)
+	e.Sig = hex.EncodeToString(sig)
+	return e, nil
+}
+
+// Verify checks that the id is the hash of the event, and the signature of the id is valid for the pubkey
+func Verify(e Event) error {
+	hash := e.Hash()
+	if e.Id != hex.EncodeToString(hash) {
+		return constants.ErrorNostrEventIdMismatch
+	}
+	pubKey, err := hex.DecodeString(e.PubKey)
+	if err != nil || len(pubKey) != 32 {
+		return constants.ErrorInvalidNostrPubKey
+	}
+	sig, err := hex.DecodeString(e.Sig)
+	if err != nil || !encryption.SchnorrVerify(pubKey, hash, sig) {
+		return constants.ErrorSignatureMismatch
+	}
+	return nil
+}
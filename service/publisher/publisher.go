@@ -0,0 +1,222 @@
+package publisher
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/identity"
+	"teerminal/service/measurement"
+	"teerminal/service/nostr"
+	"time"
+)
+
+// The publisher makes the emulator behave as a live device on nostr relays:
+// status and telemetry events signed by the app key are published on a schedule to every connected relay,
+// and command events addressed to the device are received from them
+
+// RelayStatus is the connection state of a relay
+type RelayStatus struct {
+	Url       string `json:"url"`
+	Connected bool   `json:"connected"`
+	Published uint64 `json:"published"` // Published is the number of events sent to the relay
+	Accepted  uint64 `json:"accepted"`  // Accepted is the number of events the relay acknowledged with OK true
+	Rejected  uint64 `json:"rejected"`  // Rejected is the number of events the relay acknowledged with OK false
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Command is a verified command event addressed to the device
+type Command struct {
+	Id         uint64      `json:"id"`
+	Relay      string      `json:"relay"` // Relay is the url of the first relay the command was received from
+	ReceivedAt time.Time   `json:"receivedAt"`
+	Event      nostr.Event `json:"event"`
+}
+
+type Status struct {
+	AppName            string `json:"appName"`
+	Version            string `json:"version"`
+	TeePlatformVersion uint32 `json:"teePlatformVersion"`
+	DevicePubKey       string `json:"devicePubKey"`
+	AppPubKey          string `json:"appPubKey"`
+	RegistersDigest    string `json:"registersDigest"` // RegistersDigest is keccak256 of the measurement registers, same as in version attestations
+}
+
+type Telemetry struct {
+	Uptime   int64 `json:"uptime"`   // Uptime is the seconds since the publisher started
+	Relays   int   `json:"relays"`   // Relays is the number of connected relays
+	Commands int   `json:"commands"` // Commands is the number of commands received
+}
+
+var (
+	mu        sync.Mutex
+	relays    []*relay
+	commands  []Command
+	seen      = map[string]bool{}
+	lastId    uint64
+	startedAt time.Time
+	stop      chan struct{}
+)
+
+// Start connects to the relays and publishes on the interval until Stop, it does nothing if already started
+func Start(urls []string, interval time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if stop != nil {
+		return
+	}
+	stop = make(chan struct{})
+	startedAt = time.Now()
+	relays = nil
+	for _, url := range urls {
+		r := &relay{url: url}
+		relays = append(relays, r)
+		go r.run(stop)
+	}
+	go publishLoop(stop, interval)
+}
+
+// Stop disconnects from every relay
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	stop = nil
+	for _, r := range relays {
+		r.close()
+	}
+}
+
+// Relays returns the connection state of every relay
+func Relays() []RelayStatus {
+	mu.Lock()
+	current := append([]*relay{}, relays...)
+	mu.Unlock()
+	result := make([]RelayStatus, 0, len(current))
+	for _, r := range current {
+		result = append(result, r.status())
+	}
+	return result
+}
+
+// Commands returns the received commands with an id greater than since
+func Commands(since uint64) []Command {
+	mu.Lock()
+	defer mu.Unlock()
+	result := make([]Command, 0)
+	for _, c := range commands {
+		if c.Id > since {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// addCommand records a command once, whichever relay it is received from first
+func addCommand(url string, e nostr.Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	if seen[e.Id] {
+		return
+	}
+	seen[e.Id] = true
+	lastId++
+	commands = append(commands, Command{Id: lastId, Relay: url, ReceivedAt: time.Now(), Event: e})
+	if len(commands) > constants.MaxNostrCommands {
+		delete(seen, commands[0].Event.Id)
+		commands = commands[1:]
+	}
+}
+
+// PubKey is the x-only app public key, which commands are addressed to
+func PubKey() string {
+	xOnly, _ := encryption.XOnlyPublicKey(identity.Get().AppPubKey)
+	return hex.EncodeToString(xOnly)
+}
+
+// CommandFilter is the subscription filter of the commands addressed to the device since the publisher started
+func CommandFilter() nostr.Filter {
+	mu.Lock()
+	since := startedAt.Unix()
+	mu.Unlock()
+	return nostr.Filter{Kinds: []int{constants.NostrCommandKind}, PTags: []string{PubKey()}, Since: since}
+}
+
+func sign(kind int, tags [][]string, content interface{}) (nostr.Event, error) {
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return nostr.Event{}, err
+	}
+	id := identity.Get()
+	tags = append(tags, nostr.ChainTag(id.AppChain))
+	return nostr.Sign(id.AppKey, nostr.Event{Kind: kind, Tags: tags, Content: string(raw), CreatedAt: time.Now().Unix()})
+}
+
+// StatusEvent builds the status event of the device, it is replaceable by its d tag
+func StatusEvent() (nostr.Event, error) {
+	cfg := config.GetConfig()
+	id := identity.Get()
+	registers, _ := measurement.Snapshot()
+	return sign(constants.NostrStatusKind, [][]string{{"d", constants.NostrStatusTag}}, Status{
+		AppName:            cfg.AppName,
+		Version:            cfg.Version,
+		TeePlatformVersion: cfg.TeePlatformVersion,
+		DevicePubKey:       hex.EncodeToString(id.DevicePubKey),
+		AppPubKey:          hex.EncodeToString(id.AppPubKey),
+		RegistersDigest:    hex.EncodeToString(measurement.Digest(registers)),
+	})
+}
+
+// TelemetryEvent builds the telemetry event of the publisher
+func TelemetryEvent() (nostr.Event, error) {
+	connected := 0
+	for _, r := range Relays() {
+		if r.Connected {
+			connected++
+		}
+	}
+	mu.Lock()
+	telemetry := Telemetry{Uptime: int64(time.Since(startedAt).Seconds()), Relays: connected, Commands: len(commands)}
+	mu.Unlock()
+	return sign(constants.NostrTelemetryKind, nil, telemetry)
+}
+
+// publish sends the status and telemetry events to every connected relay
+func publish() {
+	var events []nostr.Event
+	for _, build := range []func() (nostr.Event, error){StatusEvent, TelemetryEvent} {
+		e, err := build()
+		if err != nil {
+			log.Printf("publisher: failed to sign event: %v", err)
+			continue
+		}
+		events = append(events, e)
+	}
+	mu.Lock()
+	current := append([]*relay{}, relays...)
+	mu.Unlock()
+	for _, r := range current {
+		for _, e := range events {
+			r.publish(e)
+		}
+	}
+}
+
+func publishLoop(stop chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			publish()
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package publisher
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/identity"
+	"teerminal/service/nostr"
+	"testing"
+	"time"
+)
+
+// waitFor polls until done returns true, failing the test after a few seconds
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func eventsOfKind(stub *Stub, kind int) []nostr.Event {
+	var result []nostr.Event
+	for _, e := range stub.Events() {
+		if e.Kind == kind {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func TestPublisher(t *testing.T) {
+	config.Set(&config.Config{
+		Version:            "0.0.1-test",
+		TeePlatformVersion: 1,
+		VendorRoot:         "dbbe0cd0b4c7bc4ab34829c96f35bb0011d06dc3bdf0b900401a71a8f7c4c471",
+		RootKey:            "cd2f10b3d7d306a27199ccf51868c1b0859f824b6fab53710f06a092ae40226f",
+		AppName:            "EmulatorDefault",
+	})
+	// Commands are kept across restarts of the publisher, start from none
+	mu.Lock()
+	commands, seen, lastId = nil, map[string]bool{}, 0
+	mu.Unlock()
+	stub := NewStub()
+	server := httptest.NewServer(stub)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	Start([]string{url}, 50*time.Millisecond)
+	defer Stop()
+
+	id := identity.Get()
+	waitFor(t, "status and telemetry events", func() bool {
+		return len(eventsOfKind(stub, constants.NostrStatusKind)) > 0 && len(eventsOfKind(stub, constants.NostrTelemetryKind)) > 0
+	})
+	for _, kind := range []int{constants.NostrStatusKind, constants.NostrTelemetryKind} {
+		e := eventsOfKind(stub, kind)[0]
+		if err := nostr.Verify(e); err != nil {
+			t.Errorf("kind %d: %v", kind, err)
+		}
+		if e.PubKey != PubKey() {
+			t.Errorf("kind %d: expected pubkey %s, got %s", kind, PubKey(), e.PubKey)
+		}
+		// The chain tag attests the pubkey against the vendor root
+		var chain []byte
+		for _, tag := range e.Tags {
+			if len(tag) == 2 && tag[0] == constants.NostrChainTag {
+				chain, _ = hex.DecodeString(tag[1])
+			}
+		}
+		leaf, err := encryption.VerifyCertChain(chain, id.VendorRootPubKey)
+		if err != nil || hex.EncodeToString(leaf) != hex.EncodeToString(id.AppPubKey) {
+			t.Errorf("kind %d: chain tag does not attest the app key: %v", kind, err)
+		}
+	}
+	var status Status
+	if err := json.Unmarshal([]byte(eventsOfKind(stub, constants.NostrStatusKind)[0].Content), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.AppName != "EmulatorDefault" || status.AppPubKey != hex.EncodeToString(id.AppPubKey) {
+		t.Errorf("unexpected status %+v", status)
+	}
+	waitFor(t, "accepted events", func() bool {
+		relays := Relays()
+		return len(relays) == 1 && relays[0].Connected && relays[0].Accepted > 0
+	})
+
+	// A command is delivered once the relay forwards it on the subscription, other events are ignored
+	senderKey := encryption.DerivePrivateKey([]byte("sender"), []byte("publisher"))
+	unaddressed, err := nostr.Sign(senderKey, nostr.Event{Kind: constants.NostrCommandKind, Content: "unaddressed", CreatedAt: time.Now().Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	command, err := nostr.Sign(senderKey, nostr.Event{
+		Kind:      constants.NostrCommandKind,
+		Tags:      [][]string{{"p", PubKey()}},
+		Content:   "reboot",
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.Publish(unaddressed)
+	stub.Publish(command)
+	waitFor(t, "the command", func() bool {
+		return len(Commands(0)) > 0
+	})
+	received := Commands(0)
+	if len(received) != 1 || received[0].Event.Id != command.Id || received[0].Relay != url {
+		t.Errorf("expected only the addressed command, got %+v", received)
+	}
+	if since := Commands(received[0].Id); len(since) != 0 {
+		t.Errorf("expected no command after %d, got %+v", received[0].Id, since)
+	}
+}
@@ -0,0 +1,176 @@
+package publisher
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+	"teerminal/constants"
+	"teerminal/service/nostr"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const subscriptionId = "teerminal_commands"
+
+// relay is the connection to one relay, it reconnects with exponential backoff until stopped
+type relay struct {
+	url string
+
+	mu        sync.Mutex
+	conn      *websocket.Conn
+	published uint64
+	accepted  uint64
+	rejected  uint64
+	lastError string
+}
+
+func dial(url string) (*websocket.Conn, error) {
+	cfg, err := websocket.NewConfig(url, "http://localhost/")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Dialer = &net.Dialer{Timeout: constants.RelayDialTimeoutSeconds * time.Second}
+	return websocket.DialConfig(cfg)
+}
+
+func (r *relay) run(stop chan struct{}) {
+	backoff := time.Second
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		conn, err := dial(r.url)
+		if err != nil {
+			r.setError(err.Error())
+			select {
+			case <-stop:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, constants.MaxRelayBackoffSeconds*time.Second)
+			continue
+		}
+		backoff = time.Second
+		r.mu.Lock()
+		r.conn = conn
+		r.mu.Unlock()
+		// Stop may have run while dialing
+		select {
+		case <-stop:
+			r.close()
+			return
+		default:
+		}
+		r.send([]interface{}{"REQ", subscriptionId, CommandFilter()})
+		if status, err := StatusEvent(); err == nil {
+			r.publish(status)
+		}
+		err = r.read(conn)
+		r.mu.Lock()
+		r.conn = nil
+		r.lastError = err.Error()
+		r.mu.Unlock()
+		conn.Close()
+	}
+}
+
+// read handles the relay messages until the connection fails
+func (r *relay) read(conn *websocket.Conn) error {
+	for {
+		var msg []json.RawMessage
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			return err
+		}
+		var label string
+		if len(msg) == 0 || json.Unmarshal(msg[0], &label) != nil {
+			continue
+		}
+		switch label {
+		case "EVENT":
+			// ["EVENT", <subscription id>, <event>]
+			var e nostr.Event
+			if len(msg) < 3 || json.Unmarshal(msg[2], &e) != nil {
+				continue
+			}
+			if nostr.Verify(e) != nil || !CommandFilter().Match(e) {
+				continue
+			}
+			addCommand(r.url, e)
+		case "OK":
+			// ["OK", <event id>, <accepted>, <message>]
+			var accepted bool
+			var message string
+			if len(msg) < 3 || json.Unmarshal(msg[2], &accepted) != nil {
+				continue
+			}
+			if len(msg) > 3 {
+				json.Unmarshal(msg[3], &message)
+			}
+			r.mu.Lock()
+			if accepted {
+				r.accepted++
+			} else {
+				r.rejected++
+				r.lastError = message
+			}
+			r.mu.Unlock()
+		case "NOTICE", "CLOSED":
+			// ["NOTICE", <message>] and ["CLOSED", <subscription id>, <message>]
+			var message string
+			json.Unmarshal(msg[len(msg)-1], &message)
+			r.setError(message)
+		}
+	}
+}
+
+// send writes a message if connected, websocket writes are serialized by the relay lock
+func (r *relay) send(msg []interface{}) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn == nil {
+		return false
+	}
+	if err := websocket.JSON.Send(r.conn, msg); err != nil {
+		r.lastError = err.Error()
+		return false
+	}
+	return true
+}
+
+func (r *relay) publish(e nostr.Event) {
+	if r.send([]interface{}{"EVENT", e}) {
+		r.mu.Lock()
+		r.published++
+		r.mu.Unlock()
+	}
+}
+
+func (r *relay) setError(message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastError = message
+}
+
+func (r *relay) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn != nil {
+		r.conn.Close()
+	}
+}
+
+func (r *relay) status() RelayStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return RelayStatus{
+		Url:       r.url,
+		Connected: r.conn != nil,
+		Published: r.published,
+		Accepted:  r.accepted,
+		Rejected:  r.rejected,
+		LastError: r.lastError,
+	}
+}
@@ -0,0 +1,113 @@
+package publisher
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"teerminal/service/nostr"
+
+	"golang.org/x/net/websocket"
+)
+
+// Stub is an in-process nostr relay for exercising the publisher, e.g. served by httptest.NewServer(stub):
+// it verifies and stores published events, answers REQ with the stored events and EOSE, and forwards new events
+// to the matching subscriptions
+type Stub struct {
+	mu     sync.Mutex
+	events []nostr.Event
+	subs   map[*websocket.Conn]map[string]nostr.Filter
+}
+
+func NewStub() *Stub {
+	return &Stub{subs: map[*websocket.Conn]map[string]nostr.Filter{}}
+}
+
+func (s *Stub) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	websocket.Handler(s.serve).ServeHTTP(w, req)
+}
+
+// Events returns the events stored by the stub
+func (s *Stub) Events() []nostr.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]nostr.Event{}, s.events...)
+}
+
+// Publish stores the event and forwards it to the matching subscriptions, as if another client had sent it
+func (s *Stub) Publish(e nostr.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	for conn, filters := range s.subs {
+		for subId, filter := range filters {
+			if filter.Match(e) {
+				websocket.JSON.Send(conn, []interface{}{"EVENT", subId, e})
+			}
+		}
+	}
+}
+
+func (s *Stub) serve(conn *websocket.Conn) {
+	s.mu.Lock()
+	s.subs[conn] = map[string]nostr.Filter{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	for {
+		var msg []json.RawMessage
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			return
+		}
+		var label string
+		if len(msg) == 0 || json.Unmarshal(msg[0], &label) != nil {
+			continue
+		}
+		switch label {
+		case "EVENT":
+			var e nostr.Event
+			if len(msg) < 2 || json.Unmarshal(msg[1], &e) != nil {
+				continue
+			}
+			if err := nostr.Verify(e); err != nil {
+				s.send(conn, []interface{}{"OK", e.Id, false, "invalid: " + err.Error()})
+				continue
+			}
+			s.send(conn, []interface{}{"OK", e.Id, true, ""})
+			s.Publish(e)
+		case "REQ":
+			var subId string
+			var filter nostr.Filter
+			if len(msg) < 3 || json.Unmarshal(msg[1], &subId) != nil || json.Unmarshal(msg[2], &filter) != nil {
+				continue
+			}
+			s.mu.Lock()
+			s.subs[conn][subId] = filter
+			for _, e := range s.events {
+				if filter.Match(e) {
+					websocket.JSON.Send(conn, []interface{}{"EVENT", subId, e})
+				}
+			}
+			websocket.JSON.Send(conn, []interface{}{"EOSE", subId})
+			s.mu.Unlock()
+		case "CLOSE":
+			var subId string
+			if len(msg) < 2 || json.Unmarshal(msg[1], &subId) != nil {
+				continue
+			}
+			s.mu.Lock()
+			delete(s.subs[conn], subId)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// send writes to the connection, serialized with the writes of Publish
+func (s *Stub) send(conn *websocket.Conn, msg []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	websocket.JSON.Send(conn, msg)
+}
@@ -0,0 +1,103 @@
+package ratls
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"net"
+	"net/http"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"time"
+)
+
+// VerifyCertificate checks the RA-TLS server certificate against the pinned vendor root, and returns the app public key
+// If appPubKey is not nil, the certificate must also attest to exactly that app key
+func VerifyCertificate(cert *x509.Certificate, root []byte, appPubKey []byte) ([]byte, error) {
+	// The certificate must be self-signed by the key it carries
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		return nil, constants.ErrorInvalidRaTlsCert
+	}
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, constants.ErrorRaTlsCertExpired
+	}
+	var raw []byte
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(OidEvidence) {
+			raw = ext.Value
+		}
+	}
+	if raw == nil {
+		return nil, constants.ErrorMissingRaTlsEvidence
+	}
+	var evidence Evidence
+	if rest, err := asn1.Unmarshal(raw, &evidence); err != nil || len(rest) != 0 || evidence.Version != evidenceVersion {
+		return nil, constants.ErrorInvalidRaTlsEvidence
+	}
+	// The evidence must carry an app chain, a device chain would let the device key pass as the app key
+	if len(evidence.AppChain) < constants.AppChainCerts*encryption.CertLength {
+		return nil, constants.ErrorInvalidChainLength
+	}
+	leaf, err := encryption.VerifyCertChain(evidence.AppChain, root)
+	if err != nil {
+		return nil, err
+	}
+	// The leaf is an app key only if it is derived below the device key
+	deviceRootCert, _ := encryption.UnpackCert(evidence.AppChain[(constants.DeviceChainCerts-1)*encryption.CertLength : constants.DeviceChainCerts*encryption.CertLength])
+	derivation := make([]byte, 64)
+	copy(derivation, constants.DeviceRootKey)
+	if !bytes.Equal(deviceRootCert.Derivation, derivation) {
+		return nil, constants.ErrorNotAppChain
+	}
+	if appPubKey != nil && !bytes.Equal(leaf, appPubKey) {
+		return nil, constants.ErrorRaTlsAppKeyMismatch
+	}
+	if !encryption.VerifySignature(leaf, Signable(cert.RawSubjectPublicKeyInfo), evidence.Signature) {
+		return nil, constants.ErrorRaTlsSignatureMismatch
+	}
+	return leaf, nil
+}
+
+// ClientConfig returns a tls config which accepts only RA-TLS servers whose app chain starts from one of the vendor roots
+// If appPubKey is not nil, the server must also hold exactly that app key
+func ClientConfig(roots [][]byte, appPubKey []byte) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The certificate is self-signed, trust comes from the evidence checked below instead of a CA
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return constants.ErrorMissingRaTlsEvidence
+			}
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return constants.ErrorInvalidRaTlsCert
+			}
+			for _, root := range roots {
+				if _, err = VerifyCertificate(cert, root, appPubKey); err == nil {
+					break
+				}
+			}
+			return err
+		},
+	}
+}
+
+// Dial connects to an RA-TLS server, failing the handshake unless the server attests to the vendor root,
+// and to the app key if appPubKey is not nil
+func Dial(network string, addr string, root []byte, appPubKey []byte) (*tls.Conn, error) {
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: 10 * time.Second}, Config: ClientConfig([][]byte{root}, appPubKey)}
+	conn, err := dialer.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*tls.Conn), nil
+}
+
+// NewHttpClient returns an http client which only talks to RA-TLS servers attesting to the vendor root,
+// and to the app key if appPubKey is not nil
+func NewHttpClient(root []byte, appPubKey []byte) *http.Client {
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: ClientConfig([][]byte{root}, appPubKey)}}
+}
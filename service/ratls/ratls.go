@@ -0,0 +1,122 @@
+package ratls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/identity"
+	"time"
+)
+
+// Remote attestation TLS: the server certificate is self-signed by a TLS key generated at startup,
+// and carries an extension binding the TLS key to the app key:
+// Evidence ::= SEQUENCE { version INTEGER, appChain OCTET STRING, signature OCTET STRING }
+// where signature is the app key signature over "TEERMINAL_RATLS:" || sha256(SubjectPublicKeyInfo)
+
+// OidEvidence is in the IANA experimental arc, it is only meaningful to teerminal
+var OidEvidence = asn1.ObjectIdentifier{1, 3, 6, 1, 3, 8337, 1}
+
+const evidenceVersion = 1
+
+type Evidence struct {
+	Version   int
+	AppChain  []byte // AppChain is device cert || device root cert || application cert
+	Signature []byte
+}
+
+type serverCert struct {
+	generation  uint64
+	certificate *tls.Certificate
+}
+
+var current atomic.Pointer[serverCert]
+var mu sync.Mutex
+
+// Signable is "TEERMINAL_RATLS:" || sha256(SubjectPublicKeyInfo DER of the TLS key)
+func Signable(publicKeyInfo []byte) []byte {
+	h := sha256.Sum256(publicKeyInfo)
+	return append([]byte(constants.RaTlsSignPrefix), h[:]...)
+}
+
+// GetCertificate returns the server certificate of the current config generation, generating it on first use
+func GetCertificate() (*tls.Certificate, error) {
+	gen := config.Generation()
+	if c := current.Load(); c != nil && c.generation == gen {
+		return c.certificate, nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if c := current.Load(); c != nil && c.generation == gen {
+		return c.certificate, nil
+	}
+	certificate, err := generateCertificate()
+	if err != nil {
+		return nil, err
+	}
+	current.Store(&serverCert{generation: gen, certificate: certificate})
+	return certificate, nil
+}
+
+func generateCertificate() (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	publicKeyInfo, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	id := identity.Get()
+	signature, err := encryption.Sign(id.AppKey, Signable(publicKeyInfo))
+	if err != nil {
+		return nil, err
+	}
+	evidence, err := asn1.Marshal(Evidence{Version: evidenceVersion, AppChain: id.AppChain, Signature: signature})
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:    serial,
+		Subject:         pkix.Name{CommonName: config.GetConfig().AppName, Organization: []string{"Teerminal Emulator"}},
+		NotBefore:       now.Add(-time.Hour),
+		NotAfter:        now.Add(constants.RaTlsCertValidityDays * 24 * time.Hour),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		ExtraExtensions: []pkix.Extension{{Id: OidEvidence, Value: evidence}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
+
+// ServerConfig is the tls config of the attested listener
+func ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return GetCertificate()
+		},
+	}
+}
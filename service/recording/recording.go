@@ -0,0 +1,145 @@
+package recording
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"teerminal/constants"
+	"time"
+)
+
+// Recording of device sessions as JSONL, one request/response pair per line,
+// and replay of the recorded responses keyed by request
+
+type Entry struct {
+	Time        time.Time `json:"time"`
+	Method      string    `json:"method"`
+	Uri         string    `json:"uri"`  // Uri is the path with the raw query
+	Body        string    `json:"body"` // Body is the request body
+	Status      int       `json:"status"`
+	ContentType string    `json:"contentType"`
+	Response    string    `json:"response"`       // Response is the response body
+	Type        string    `json:"type,omitempty"` // Type is the Go type of the response, whose bytes fields are byte strings in CBOR
+}
+
+// Key identifies a request: sha256(method || 0x00 || uri || 0x00 || body)
+func Key(method string, uri string, body string) string {
+	h := sha256.Sum256([]byte(method + "\x00" + uri + "\x00" + body))
+	return fmt.Sprintf("%x", h)
+}
+
+// Route identifies a request without its query and body, used when no request matches exactly if enabled
+func Route(method string, path string) string {
+	return method + " " + path
+}
+
+// Path strips the query from the uri
+func Path(uri string) string {
+	if u, err := url.ParseRequestURI(uri); err == nil {
+		return u.Path
+	}
+	return uri
+}
+
+type Recorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecorder appends to the JSONL file, creating it if it does not exist
+func NewRecorder(name string) (*Recorder, error) {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{file: f}, nil
+}
+
+func (r *Recorder) Record(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.file.Write(append(line, '\n'))
+	return err
+}
+
+// queue serves the recorded responses of one request in order, repeating the last one once exhausted
+type queue struct {
+	entries []Entry
+	next    int
+}
+
+func (q *queue) pop() Entry {
+	entry := q.entries[q.next]
+	if q.next < len(q.entries)-1 {
+		q.next++
+	}
+	return entry
+}
+
+type Replayer struct {
+	mu      sync.Mutex
+	byKey   map[string]*queue
+	byRoute map[string]*queue // byRoute is nil unless falling back to the same method and path
+}
+
+// NewReplayer loads the recorded entries from the JSONL file, byRoute enables the fallback of Lookup
+func NewReplayer(name string, byRoute bool) (*Replayer, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := &Replayer{byKey: map[string]*queue{}}
+	if byRoute {
+		r.byRoute = map[string]*queue{}
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, constants.MaxRecordingLineLength)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, constants.ErrorInvalidRecording
+		}
+		add(r.byKey, Key(entry.Method, entry.Uri, entry.Body), entry)
+		if r.byRoute != nil {
+			add(r.byRoute, Route(entry.Method, Path(entry.Uri)), entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func add(m map[string]*queue, key string, entry Entry) {
+	q, ok := m[key]
+	if !ok {
+		q = &queue{}
+		m[key] = q
+	}
+	q.entries = append(q.entries, entry)
+}
+
+// Lookup returns the next recorded response of the request, falling back to the same method and path if enabled
+func (r *Replayer) Lookup(method string, uri string, body string) (Entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if q, ok := r.byKey[Key(method, uri, body)]; ok {
+		return q.pop(), true
+	}
+	if q, ok := r.byRoute[Route(method, Path(uri))]; ok {
+		return q.pop(), true
+	}
+	return Entry{}, false
+}
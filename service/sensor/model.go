@@ -0,0 +1,109 @@
+package sensor
+
+import (
+	"math"
+	"math/rand"
+	"teerminal/config"
+	"teerminal/constants"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	TypeEnergy      = "energy"
+	TypeGps         = "gps"
+	TypeTemperature = "temperature"
+	TypeCounter     = "counter"
+)
+
+const metersPerDegree = 111320.0
+
+// model produces the values of a sensor at t seconds since start
+type model interface {
+	next(t float64) map[string]float64
+}
+
+// base is the drifting value with gaussian noise shared by the scalar models
+type base struct {
+	cfg config.Sensor
+	rng *rand.Rand
+}
+
+func (b *base) value(t float64) float64 {
+	return b.cfg.Base + b.cfg.Drift*t + b.rng.NormFloat64()*b.cfg.Noise
+}
+
+type temperature struct {
+	base
+}
+
+func (m *temperature) next(t float64) map[string]float64 {
+	return map[string]float64{"celsius": m.value(t)}
+}
+
+type energy struct {
+	base
+	last   float64 // last is the time of the previous reading
+	energy float64
+}
+
+func (m *energy) next(t float64) map[string]float64 {
+	power := math.Max(0, m.value(t))
+	m.energy += power * (t - m.last) / 3600
+	m.last = t
+	return map[string]float64{"power": power, "energy": m.energy}
+}
+
+type counter struct {
+	base
+	count float64
+}
+
+func (m *counter) next(t float64) map[string]float64 {
+	m.count += math.Max(0, math.Round(m.value(t)))
+	return map[string]float64{"count": m.count}
+}
+
+type gps struct {
+	base
+	last      float64
+	latitude  float64
+	longitude float64
+	heading   float64 // heading is the direction of travel in radians, it turns a little on every reading
+}
+
+func (m *gps) next(t float64) map[string]float64 {
+	distance := m.cfg.Drift * (t - m.last)
+	m.last = t
+	m.heading += m.rng.NormFloat64() * 0.2
+	m.latitude += distance * math.Cos(m.heading) / metersPerDegree
+	m.longitude += distance * math.Sin(m.heading) / (metersPerDegree * math.Cos(m.latitude*math.Pi/180))
+	// Noise is measurement error, it does not move the position
+	noiseLat := m.rng.NormFloat64() * m.cfg.Noise / metersPerDegree
+	noiseLon := m.rng.NormFloat64() * m.cfg.Noise / (metersPerDegree * math.Cos(m.latitude*math.Pi/180))
+	return map[string]float64{"latitude": m.latitude + noiseLat, "longitude": m.longitude + noiseLon}
+}
+
+// newModel returns the model of the sensor type, seeded from the seed or the name
+func newModel(cfg config.Sensor) (model, error) {
+	seed := cfg.Seed
+	if seed == 0 {
+		hash := crypto.Keccak256([]byte(cfg.Name))
+		for _, b := range hash[:8] {
+			seed = seed<<8 | int64(b)
+		}
+	}
+	b := base{cfg: cfg, rng: rand.New(rand.NewSource(seed))}
+	switch cfg.Type {
+	case TypeTemperature:
+		return &temperature{base: b}, nil
+	case TypeEnergy:
+		return &energy{base: b}, nil
+	case TypeCounter:
+		return &counter{base: b}, nil
+	case TypeGps:
+		return &gps{base: b, latitude: cfg.Latitude, longitude: cfg.Longitude, heading: b.rng.Float64() * 2 * math.Pi}, nil
+	default:
+		return nil, constants.ErrorUnknownSensorType
+	}
+}
@@ -0,0 +1,248 @@
+package sensor
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/identity"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Simulated sensors producing readings signed by the app key
+// Every reading gets the next sequence number across all sensors, so consumers can detect gaps and reordering
+// Sequences are reserved in blocks persisted in their own file of the data directory, so they keep increasing across restarts,
+// the unused rest of the last block before a restart is skipped
+
+type Reading struct {
+	Sequence  uint64             `json:"sequence"` // Sequence increases on every reading of any sensor, and across restarts
+	Sensor    string             `json:"sensor"`
+	Type      string             `json:"type"`
+	Timestamp uint64             `json:"timestamp"` // Timestamp is the unix time in milliseconds
+	Values    map[string]float64 `json:"values"`
+	PubKey    string             `json:"appPubKey" format:"hex"`
+	Signature string             `json:"signature" format:"hex"` // Signature is the app key signature over Signable
+}
+
+var (
+	mu          sync.Mutex
+	sensors     []config.Sensor
+	readings    []Reading
+	lastSeq     uint64
+	reservedSeq uint64 // reservedSeq is the last sequence of the reserved block
+	subscribers = map[chan Reading]bool{}
+	stop        chan struct{}
+)
+
+// Signable builds the payload signed by the app key for a reading:
+// "TEERMINAL_READING:" || sequence (8 bytes big endian) || timestamp (8 bytes big endian) || keccak256(sensor)
+// || keccak256(keccak256(name) || value (IEEE 754 binary64, 8 bytes big endian) for every value sorted by name)
+func Signable(sequence uint64, timestamp uint64, sensor string, values map[string]float64) (signable []byte) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var encoded []byte
+	for _, name := range names {
+		encoded = append(encoded, crypto.Keccak256([]byte(name))...)
+		encoded = binary.BigEndian.AppendUint64(encoded, math.Float64bits(values[name]))
+	}
+	signable = append(signable, []byte(constants.SensorReadingSignPrefix)...)
+	signable = binary.BigEndian.AppendUint64(signable, sequence)
+	signable = binary.BigEndian.AppendUint64(signable, timestamp)
+	signable = append(signable, crypto.Keccak256([]byte(sensor))...)
+	signable = append(signable, crypto.Keccak256(encoded)...)
+	return
+}
+
+// Verify checks the reading signature against the app public key
+func Verify(r Reading, appPubKey []byte) error {
+	signature, err := hex.DecodeString(strings.TrimPrefix(r.Signature, "0x"))
+	if err != nil || !encryption.VerifySignature(appPubKey, Signable(r.Sequence, r.Timestamp, r.Sensor, r.Values), signature) {
+		return constants.ErrorSignatureMismatch
+	}
+	return nil
+}
+
+// Validate checks the names, types and intervals of the sensors
+func Validate(list []config.Sensor) error {
+	if len(list) > constants.MaxSensors {
+		return constants.ErrorInvalidSensor
+	}
+	seen := map[string]bool{}
+	for _, s := range list {
+		if s.Name == "" || seen[s.Name] || s.Interval < 0 || (s.Interval > 0 && s.Interval < constants.MinSensorIntervalMs) || s.Noise < 0 {
+			return constants.ErrorInvalidSensor
+		}
+		seen[s.Name] = true
+		if _, err := newModel(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start runs every sensor until Stop, it does nothing if already started
+func Start(list []config.Sensor) error {
+	if err := Validate(list); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if stop != nil {
+		return nil
+	}
+	if len(list) > 0 {
+		if err := reserve(); err != nil {
+			return err
+		}
+	}
+	stop = make(chan struct{})
+	sensors = append([]config.Sensor{}, list...)
+	for _, s := range sensors {
+		m, _ := newModel(s)
+		go run(s, m, stop)
+	}
+	return nil
+}
+
+// reserve persists the next block of sequences and moves past the previous ones, must hold mu
+func reserve() error {
+	reserved, err := loadReserved()
+	if err != nil {
+		return err
+	}
+	if reserved > math.MaxUint64-constants.SensorSequenceBlock {
+		return constants.ErrorCounterOverflow
+	}
+	if err := persistReserved(reserved + constants.SensorSequenceBlock); err != nil {
+		return err
+	}
+	reservedSeq = reserved + constants.SensorSequenceBlock
+	lastSeq = max(lastSeq, reserved)
+	return nil
+}
+
+// Stop stops every sensor and closes the subscriptions
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	stop = nil
+	for ch := range subscribers {
+		delete(subscribers, ch)
+		close(ch)
+	}
+}
+
+// Sensors returns the running sensors
+func Sensors() []config.Sensor {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]config.Sensor{}, sensors...)
+}
+
+func run(s config.Sensor, m model, stop chan struct{}) {
+	interval := time.Duration(s.Interval) * time.Millisecond
+	if interval == 0 {
+		interval = constants.DefaultSensorIntervalMs * time.Millisecond
+	}
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			add(s, m.next(now.Sub(start).Seconds()))
+		}
+	}
+}
+
+// add signs the values with the next sequence number, stores the reading and sends it to the subscribers
+func add(s config.Sensor, values map[string]float64) {
+	id := identity.Get()
+	mu.Lock()
+	defer mu.Unlock()
+	if lastSeq == reservedSeq {
+		// A sequence is never signed twice, the reading is dropped until a block can be reserved
+		if err := reserve(); err != nil {
+			log.Printf("sensor: failed to reserve sequences: %v", err)
+			return
+		}
+	}
+	lastSeq++
+	r := Reading{
+		Sequence:  lastSeq,
+		Sensor:    s.Name,
+		Type:      s.Type,
+		Timestamp: uint64(time.Now().UnixMilli()),
+		Values:    values,
+		PubKey:    hex.EncodeToString(id.AppPubKey),
+	}
+	signature, _ := encryption.Sign(id.AppKey, Signable(r.Sequence, r.Timestamp, r.Sensor, r.Values))
+	r.Signature = hex.EncodeToString(signature)
+	readings = append(readings, r)
+	if len(readings) > constants.MaxSensorReadings {
+		readings = readings[1:]
+	}
+	for ch := range subscribers {
+		select {
+		case ch <- r:
+		default:
+			// The subscriber fell behind, close it instead of skipping readings
+			delete(subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Readings returns the stored readings with a sequence greater than since, of the sensor if it is not empty
+func Readings(since uint64, sensor string) []Reading {
+	mu.Lock()
+	defer mu.Unlock()
+	result := make([]Reading, 0)
+	for _, r := range readings {
+		if r.Sequence > since && (sensor == "" || r.Sensor == sensor) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// Subscribe returns the stored readings with a sequence greater than since, and a channel of the following readings,
+// which is closed if the subscriber falls behind or the sensors stop
+func Subscribe(since uint64) ([]Reading, chan Reading, func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	backlog := make([]Reading, 0)
+	for _, r := range readings {
+		if r.Sequence > since {
+			backlog = append(backlog, r)
+		}
+	}
+	ch := make(chan Reading, constants.SensorSubscriberBuffer)
+	subscribers[ch] = true
+	cancel := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if subscribers[ch] {
+			delete(subscribers, ch)
+			close(ch)
+		}
+	}
+	return backlog, ch, cancel
+}
@@ -0,0 +1,76 @@
+package sensor
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"teerminal/config"
+	"teerminal/constants"
+)
+
+// The sequence store is a file of its own, apart from the application counters,
+// so it takes none of their quota and no application counter can move it
+
+type sequenceState struct {
+	Reserved uint64 `json:"reserved"` // Reserved is the last reserved sequence
+}
+
+func sequencePath() string {
+	return filepath.Join(config.GetDataDir(), constants.SensorSequenceFile)
+}
+
+// loadReserved reads the last reserved sequence, zero if none was reserved yet
+func loadReserved() (uint64, error) {
+	raw, err := os.ReadFile(sequencePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var state sequenceState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return 0, err
+	}
+	return state.Reserved, nil
+}
+
+// persistReserved writes the last reserved sequence
+// The file is replaced atomically, so a crash leaves either the old or the new reservation, never a partial one
+func persistReserved(reserved uint64) error {
+	raw, err := json.Marshal(sequenceState{Reserved: reserved})
+	if err != nil {
+		return err
+	}
+	path := sequencePath()
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, constants.SensorSequenceFile+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	// Sync the directory so the rename itself survives a crash
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
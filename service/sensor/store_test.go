@@ -0,0 +1,49 @@
+package sensor
+
+import (
+	"teerminal/config"
+	"teerminal/constants"
+	counters "teerminal/service/counter"
+	"testing"
+)
+
+func TestReserve(t *testing.T) {
+	// An empty app name is the namespace the sequence used to share with the counters
+	config.Set(&config.Config{DataDir: t.TempDir()})
+	mu.Lock()
+	defer mu.Unlock()
+	lastSeq, reservedSeq = 0, 0
+
+	if err := reserve(); err != nil {
+		t.Fatal(err)
+	}
+	if lastSeq != 0 || reservedSeq != constants.SensorSequenceBlock {
+		t.Fatalf("first block: last %d reserved %d", lastSeq, reservedSeq)
+	}
+
+	// A restart skips the rest of the reserved block
+	lastSeq, reservedSeq = 0, 0
+	if err := reserve(); err != nil {
+		t.Fatal(err)
+	}
+	if lastSeq != constants.SensorSequenceBlock || reservedSeq != 2*constants.SensorSequenceBlock {
+		t.Fatalf("after restart: last %d reserved %d", lastSeq, reservedSeq)
+	}
+
+	// The sequence takes no counter, and a counter of the same name does not move it
+	if _, err := counters.Load("", "sensor_sequence"); err != constants.ErrorCounterDoesNotExist {
+		t.Fatalf("expected no counter, got %v", err)
+	}
+	if _, err := counters.Create("", "sensor_sequence"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := counters.Increment("", "sensor_sequence"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reserve(); err != nil {
+		t.Fatal(err)
+	}
+	if reservedSeq != 3*constants.SensorSequenceBlock {
+		t.Fatalf("counter moved the sequence: reserved %d", reservedSeq)
+	}
+}
@@ -0,0 +1,206 @@
+package sgx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"teerminal/config"
+	"teerminal/service/encryption"
+	"teerminal/service/identity"
+	"time"
+)
+
+// Mock PCK cert chain: Root CA (vendor root) -> Platform CA (vendor root) -> PCK Certificate (device root key)
+// All keys are P-256 keys derived from the secp256k1 keys, so the keys are stable for one device
+
+var (
+	oidSgxExtension = asn1.ObjectIdentifier{1, 2, 840, 113741, 1, 13, 1}
+	oidSgxPpid      = append(oidSgxExtension, 1)
+	oidSgxTcb       = append(oidSgxExtension, 2)
+	oidSgxPceId     = append(oidSgxExtension, 3)
+	oidSgxFmspc     = append(oidSgxExtension, 4)
+	oidSgxType      = append(oidSgxExtension, 5)
+)
+
+var certNotBefore = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+var certNotAfter = time.Date(2049, 12, 31, 23, 59, 59, 0, time.UTC)
+
+// PckChain is the mock PCK cert chain and the keys used to sign quotes
+type PckChain struct {
+	Generation     uint64
+	PckKey         *ecdsa.PrivateKey // PckKey signs the QE report
+	AttestationKey *ecdsa.PrivateKey // AttestationKey signs the ISV enclave report
+	Pem            []byte            // Pem is PCK cert || Platform CA cert || Root CA cert, PEM encoded
+	RootCert       []byte            // RootCert is the DER encoded mock root CA
+	Fmspc          []byte
+	Ppid           []byte
+}
+
+var chain atomic.Pointer[PckChain]
+var mu sync.Mutex
+
+// GetPckChain returns the mock PCK cert chain of the current config generation, generating it on first use
+func GetPckChain() (*PckChain, error) {
+	gen := config.Generation()
+	if c := chain.Load(); c != nil && c.Generation == gen {
+		return c, nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if c := chain.Load(); c != nil && c.Generation == gen {
+		return c, nil
+	}
+	c, err := generatePckChain(gen)
+	if err != nil {
+		return nil, err
+	}
+	chain.Store(c)
+	return c, nil
+}
+
+func serial(label string) *big.Int {
+	h := sha256.Sum256([]byte(label))
+	return new(big.Int).SetBytes(h[:16])
+}
+
+type sgxExtensionItem struct {
+	Id    asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+func sgxItem(id asn1.ObjectIdentifier, value interface{}) (sgxExtensionItem, error) {
+	raw, err := asn1.Marshal(value)
+	if err != nil {
+		return sgxExtensionItem{}, err
+	}
+	return sgxExtensionItem{Id: id, Value: asn1.RawValue{FullBytes: raw}}, nil
+}
+
+func sgxExtension(ppid []byte, fmspc []byte, svn uint16, cpuSvn []byte) (pkix.Extension, error) {
+	// TCB: 16 component svn, pce svn and cpu svn
+	var tcb []sgxExtensionItem
+	for i := 1; i <= 16; i++ {
+		item, err := sgxItem(append(append(asn1.ObjectIdentifier{}, oidSgxTcb...), i), int(cpuSvn[i-1]))
+		if err != nil {
+			return pkix.Extension{}, err
+		}
+		tcb = append(tcb, item)
+	}
+	pceSvn, err := sgxItem(append(append(asn1.ObjectIdentifier{}, oidSgxTcb...), 17), int(svn))
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	cpuSvnItem, err := sgxItem(append(append(asn1.ObjectIdentifier{}, oidSgxTcb...), 18), cpuSvn)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	tcb = append(tcb, pceSvn, cpuSvnItem)
+
+	var items []sgxExtensionItem
+	for _, v := range []struct {
+		id    asn1.ObjectIdentifier
+		value interface{}
+	}{
+		{oidSgxPpid, ppid},
+		{oidSgxTcb, tcb},
+		{oidSgxPceId, []byte{0, 0}},
+		{oidSgxFmspc, fmspc},
+		{oidSgxType, asn1.Enumerated(0)},
+	} {
+		item, err := sgxItem(v.id, v.value)
+		if err != nil {
+			return pkix.Extension{}, err
+		}
+		items = append(items, item)
+	}
+	value, err := asn1.Marshal(items)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+	return pkix.Extension{Id: oidSgxExtension, Value: value}, nil
+}
+
+func generatePckChain(gen uint64) (*PckChain, error) {
+	vendorRoot := config.GetVendorRoot()
+	id := identity.Get()
+	rootKey := encryption.DeriveEcdsaKey(elliptic.P256(), vendorRoot, "sgx_root_ca")
+	platformKey := encryption.DeriveEcdsaKey(elliptic.P256(), vendorRoot, "sgx_platform_ca")
+	pckKey := encryption.DeriveEcdsaKey(elliptic.P256(), id.DeviceKey, "sgx_pck")
+	attestationKey := encryption.DeriveEcdsaKey(elliptic.P256(), id.DeviceKey, "sgx_attestation_key")
+
+	org := []string{"Teerminal Emulator"}
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          serial("sgx_root_ca"),
+		Subject:               pkix.Name{CommonName: "Teerminal Mock SGX Root CA", Organization: org},
+		NotBefore:             certNotBefore,
+		NotAfter:              certNotAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLen:            1,
+	}
+	rootDer, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		return nil, err
+	}
+	platformTemplate := &x509.Certificate{
+		SerialNumber:          serial("sgx_platform_ca"),
+		Subject:               pkix.Name{CommonName: "Teerminal Mock SGX PCK Platform CA", Organization: org},
+		NotBefore:             certNotBefore,
+		NotAfter:              certNotAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	platformDer, err := x509.CreateCertificate(rand.Reader, platformTemplate, rootTemplate, &platformKey.PublicKey, rootKey)
+	if err != nil {
+		return nil, err
+	}
+	// PPID and FMSPC are derived from the device public key
+	devHash := sha256.Sum256(id.DevicePubKey)
+	ppid := devHash[:16]
+	fmspc := devHash[16:22]
+	extension, err := sgxExtension(ppid, fmspc, PceSvn(), CpuSvn())
+	if err != nil {
+		return nil, err
+	}
+	pckTemplate := &x509.Certificate{
+		SerialNumber:    new(big.Int).SetBytes(devHash[:16]),
+		Subject:         pkix.Name{CommonName: "Teerminal Mock SGX PCK Certificate", Organization: org},
+		NotBefore:       certNotBefore,
+		NotAfter:        certNotAfter,
+		KeyUsage:        x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+		ExtraExtensions: []pkix.Extension{extension},
+	}
+	platformCert, err := x509.ParseCertificate(platformDer)
+	if err != nil {
+		return nil, err
+	}
+	pckDer, err := x509.CreateCertificate(rand.Reader, pckTemplate, platformCert, &pckKey.PublicKey, platformKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var chainPem []byte
+	for _, der := range [][]byte{pckDer, platformDer, rootDer} {
+		chainPem = append(chainPem, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	return &PckChain{
+		Generation:     gen,
+		PckKey:         pckKey,
+		AttestationKey: attestationKey,
+		Pem:            chainPem,
+		RootCert:       rootDer,
+		Fmspc:          fmspc,
+		Ppid:           ppid,
+	}, nil
+}
@@ -0,0 +1,197 @@
+package sgx
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/identity"
+)
+
+// Emulated Intel SGX ECDSA (DCAP) quote, version 3 or 4
+// Layout: 48 bytes header || 384 bytes ISV enclave report body || 4 bytes signature data length || signature data
+
+const (
+	QuoteVersion3      = 3
+	QuoteVersion4      = 4
+	HeaderLength       = 48
+	ReportBodyLength   = 384
+	attestationKeyType = 2 // ECDSA-256-with-P-256 curve
+	teeTypeSgx         = 0x00000000
+	certDataTypePck    = 5 // Concatenated PCK cert chain, PEM encoded
+	certDataTypeQe     = 6 // QE report certification data, quote v4 only
+)
+
+// QeVendorId is the Intel QE vendor id
+var QeVendorId = []byte{0x93, 0x9a, 0x72, 0x33, 0xf7, 0x9c, 0x4c, 0xa9, 0x94, 0x0a, 0x0d, 0xb3, 0x95, 0x7f, 0x06, 0x07}
+
+// ReportBody is the SGX report body, shared by the ISV enclave report and the QE report
+type ReportBody struct {
+	CpuSvn       [16]byte
+	MiscSelect   uint32
+	IsvExtProdId [16]byte
+	Attributes   [16]byte
+	MrEnclave    [32]byte
+	MrSigner     [32]byte
+	ConfigId     [64]byte
+	IsvProdId    uint16
+	IsvSvn       uint16
+	ConfigSvn    uint16
+	IsvFamilyId  [16]byte
+	ReportData   [64]byte
+}
+
+func (r ReportBody) Bytes() []byte {
+	b := make([]byte, 0, ReportBodyLength)
+	b = append(b, r.CpuSvn[:]...)
+	b = binary.LittleEndian.AppendUint32(b, r.MiscSelect)
+	b = append(b, make([]byte, 12)...) // reserved
+	b = append(b, r.IsvExtProdId[:]...)
+	b = append(b, r.Attributes[:]...)
+	b = append(b, r.MrEnclave[:]...)
+	b = append(b, make([]byte, 32)...) // reserved
+	b = append(b, r.MrSigner[:]...)
+	b = append(b, make([]byte, 32)...) // reserved
+	b = append(b, r.ConfigId[:]...)
+	b = binary.LittleEndian.AppendUint16(b, r.IsvProdId)
+	b = binary.LittleEndian.AppendUint16(b, r.IsvSvn)
+	b = binary.LittleEndian.AppendUint16(b, r.ConfigSvn)
+	b = append(b, make([]byte, 42)...) // reserved
+	b = append(b, r.IsvFamilyId[:]...)
+	b = append(b, r.ReportData[:]...)
+	return b
+}
+
+// MrEnclave is the simulated enclave measurement: sha256("MRENCLAVE" || AppName)
+func MrEnclave() (m [32]byte) {
+	return sha256.Sum256(append([]byte("MRENCLAVE"), []byte(config.GetConfig().AppName)...))
+}
+
+// MrSigner is the simulated enclave signer: sha256("MRSIGNER" || vendor root public key)
+func MrSigner() (m [32]byte) {
+	return sha256.Sum256(append([]byte("MRSIGNER"), identity.Get().VendorRootPubKey...))
+}
+
+func PceSvn() uint16 {
+	return uint16(config.GetConfig().TeePlatformVersion)
+}
+
+// CpuSvn is the simulated cpu svn, every component is the tee platform version
+func CpuSvn() []byte {
+	cpuSvn := make([]byte, 16)
+	for i := range cpuSvn {
+		cpuSvn[i] = byte(config.GetConfig().TeePlatformVersion)
+	}
+	return cpuSvn
+}
+
+func signP256(key *ecdsa.PrivateKey, data []byte) ([]byte, error) {
+	// Quote signatures are raw r || s, 32 bytes each
+	hash := sha256.Sum256(data)
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return sig, nil
+}
+
+func rawPublicKey(key *ecdsa.PrivateKey) []byte {
+	pub := make([]byte, 64)
+	key.X.FillBytes(pub[:32])
+	key.Y.FillBytes(pub[32:])
+	return pub
+}
+
+// EnclaveReport is the simulated ISV enclave report body with the given report data and config id
+func EnclaveReport(reportData [64]byte, configId [64]byte) ReportBody {
+	report := ReportBody{
+		MrEnclave:  MrEnclave(),
+		ConfigId:   configId,
+		MrSigner:   MrSigner(),
+		IsvProdId:  1,
+		IsvSvn:     uint16(config.GetConfig().TeePlatformVersion),
+		ReportData: reportData,
+	}
+	copy(report.CpuSvn[:], CpuSvn())
+	// Attributes: INIT | MODE64BIT, XFRM: x87 | SSE
+	report.Attributes[0] = 0x05
+	report.Attributes[8] = 0x03
+	return report
+}
+
+// BuildQuote builds and signs a quote of the given version over the ISV enclave report with the report data and config id
+func BuildQuote(version uint16, reportData [64]byte, configId [64]byte) ([]byte, error) {
+	if version != QuoteVersion3 && version != QuoteVersion4 {
+		return nil, constants.ErrorUnsupportedQuoteVersion
+	}
+	pck, err := GetPckChain()
+	if err != nil {
+		return nil, err
+	}
+	// Header
+	header := make([]byte, 0, HeaderLength)
+	header = binary.LittleEndian.AppendUint16(header, version)
+	header = binary.LittleEndian.AppendUint16(header, attestationKeyType)
+	header = binary.LittleEndian.AppendUint32(header, teeTypeSgx) // reserved in v3, tee type in v4
+	header = binary.LittleEndian.AppendUint16(header, PceSvn())   // qe svn
+	header = binary.LittleEndian.AppendUint16(header, PceSvn())   // pce svn
+	header = append(header, QeVendorId...)
+	userData := make([]byte, 20)
+	copy(userData, pck.Fmspc)
+	header = append(header, userData...)
+	// ISV enclave report, signed by the attestation key
+	body := EnclaveReport(reportData, configId).Bytes()
+	signed := append(append([]byte{}, header...), body...)
+	isvSignature, err := signP256(pck.AttestationKey, signed)
+	if err != nil {
+		return nil, err
+	}
+	attestationKey := rawPublicKey(pck.AttestationKey)
+	// QE report binds the attestation key: report data = sha256(attestation key || qe auth data)
+	qeAuthData := make([]byte, 32)
+	qeReport := ReportBody{
+		MrEnclave: sha256.Sum256(append([]byte("MRENCLAVE_QE"), identity.Get().VendorRootPubKey...)),
+		MrSigner:  MrSigner(),
+		IsvProdId: 1,
+		IsvSvn:    PceSvn(),
+	}
+	copy(qeReport.CpuSvn[:], CpuSvn())
+	qeReport.Attributes[0] = 0x05
+	qeReport.Attributes[8] = 0x03
+	binding := sha256.Sum256(append(append([]byte{}, attestationKey...), qeAuthData...))
+	copy(qeReport.ReportData[:32], binding[:])
+	qeReportBytes := qeReport.Bytes()
+	qeSignature, err := signP256(pck.PckKey, qeReportBytes)
+	if err != nil {
+		return nil, err
+	}
+	// QE report certification data: qe report || qe report signature || qe auth data || pck cert data
+	var qeCertification []byte
+	qeCertification = append(qeCertification, qeReportBytes...)
+	qeCertification = append(qeCertification, qeSignature...)
+	qeCertification = binary.LittleEndian.AppendUint16(qeCertification, uint16(len(qeAuthData)))
+	qeCertification = append(qeCertification, qeAuthData...)
+	qeCertification = binary.LittleEndian.AppendUint16(qeCertification, certDataTypePck)
+	qeCertification = binary.LittleEndian.AppendUint32(qeCertification, uint32(len(pck.Pem)))
+	qeCertification = append(qeCertification, pck.Pem...)
+
+	var signature []byte
+	signature = append(signature, isvSignature...)
+	signature = append(signature, attestationKey...)
+	if version == QuoteVersion4 {
+		// v4 wraps the QE report in certification data of type 6
+		signature = binary.LittleEndian.AppendUint16(signature, certDataTypeQe)
+		signature = binary.LittleEndian.AppendUint32(signature, uint32(len(qeCertification)))
+	}
+	signature = append(signature, qeCertification...)
+
+	quote := signed
+	quote = binary.LittleEndian.AppendUint32(quote, uint32(len(signature)))
+	quote = append(quote, signature...)
+	return quote, nil
+}
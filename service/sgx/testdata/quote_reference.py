@@ -0,0 +1,84 @@
+#!/usr/bin/env python3
+"""Reference SGX quote header, report body and report data, written from the DCAP quote layout independently of quote.go.
+
+The public keys are inputs, identity derives them from the root keys. Prints the vectors of quote_test.go as JSON:
+python3 quote_reference.py > quote_vectors.json
+"""
+import hashlib
+import json
+import struct
+
+VENDOR_ROOT = "dbbe0cd0b4c7bc4ab34829c96f35bb0011d06dc3bdf0b900401a71a8f7c4c471"
+ROOT_KEY = "cd2f10b3d7d306a27199ccf51868c1b0859f824b6fab53710f06a092ae40226f"
+VENDOR_ROOT_PUB = ("ec250d04047eb40de9ab27cd4a983b09817bdff7e58050d7725bf45fffb6702a"
+                   "e1be201a59714a9b04d776aa4a984cd71e0d0e8573ab9516495d1a133dc49c7e")
+DEVICE_PUB = ("6e3f2aea1db21430f5975d3ef74ad45a60db33c2f5435af5e22b7418067a7ba9"
+              "52e7ed219ea0ab631fc7dd67bcf592916302a2d5fa58edabe9f406ae8ca9876f")
+QE_VENDOR_ID = bytes.fromhex("939a7233f79c4ca9940a0db3957f0607")
+
+CASES = [
+    {
+        "name": "default",
+        "appName": "EmulatorDefault",
+        "appPubKey": "ef3455e69d8ee1efc3c5b939e84e7c43295e627142a3bfcf7dd44295d0f456b9"
+                     "9b3391879a6ffffc779a3901ae51d65d1c3a9687e015b8fe81d2b5bc5144bba4",
+        "teePlatformVersion": 1,
+        "nonce": "00" * 64,
+        "configId": "00" * 64,
+    },
+    {
+        "name": "measured",
+        "appName": "vector app",
+        "appPubKey": "f4c2e6681364ffe128a34c9eef4ea698f34b40fc65a0eef7b20107dab998ab7e"
+                     "6081023e33b42ddc099995dafedf816870ceebcffd0c834de0e21f1a700d0c85",
+        "teePlatformVersion": 7,
+        "nonce": bytes(range(64)).hex(),
+        "configId": bytes(range(255, 223, -1)).hex() + "00" * 32,
+    },
+]
+
+
+def sha256(data):
+    return hashlib.sha256(data).digest()
+
+
+def header(version, svn):
+    fmspc = sha256(bytes.fromhex(DEVICE_PUB))[16:22]
+    # version, attestation key type ECDSA-256-with-P-256, tee type SGX, qe svn, pce svn, qe vendor id, user data
+    return (struct.pack("<HHIHH", version, 2, 0, svn, svn) + QE_VENDOR_ID + fmspc.ljust(20, b"\0")).hex()
+
+
+def report_body(case, mr_enclave, mr_signer, report_data):
+    svn = case["teePlatformVersion"]
+    attributes = bytearray(16)
+    attributes[0] = 0x05  # INIT | MODE64BIT
+    attributes[8] = 0x03  # XFRM x87 | SSE
+    body = bytes([svn & 0xff]) * 16             # cpu svn
+    body += struct.pack("<I", 0) + bytes(12)     # misc select, reserved
+    body += bytes(16) + bytes(attributes)        # isv ext prod id, attributes
+    body += mr_enclave + bytes(32)               # mr enclave, reserved
+    body += mr_signer + bytes(32)                # mr signer, reserved
+    body += bytes.fromhex(case["configId"])      # config id
+    body += struct.pack("<HHH", 1, svn, 0)       # isv prod id, isv svn, config svn
+    body += bytes(42) + bytes(16)                # reserved, isv family id
+    body += report_data
+    assert len(body) == 384
+    return body.hex()
+
+
+def vector(case):
+    mr_enclave = sha256(b"MRENCLAVE" + case["appName"].encode())
+    mr_signer = sha256(b"MRSIGNER" + bytes.fromhex(VENDOR_ROOT_PUB))
+    report_data = sha256(bytes.fromhex(case["nonce"])) + sha256(bytes.fromhex(case["appPubKey"]))
+    return dict(case,
+                vendorRoot=VENDOR_ROOT,
+                rootKey=ROOT_KEY,
+                mrEnclave=mr_enclave.hex(),
+                mrSigner=mr_signer.hex(),
+                reportData=report_data.hex(),
+                headerV3=header(3, case["teePlatformVersion"]),
+                headerV4=header(4, case["teePlatformVersion"]),
+                reportBody=report_body(case, mr_enclave, mr_signer, report_data))
+
+
+print(json.dumps([vector(case) for case in CASES], indent=2))
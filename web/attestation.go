@@ -1,12 +1,7 @@
 package web
 
 import (
-	"encoding/hex"
-	"fmt"
-	"strings"
-	"teerminal/config"
 	"teerminal/constants"
-	"teerminal/service/encryption"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,89 +13,115 @@ func RegisterAttestationRoutes(router *gin.Engine) {
 	{
 		attestation.GET("/appkey", HandleGetAppDerivedKey)
 		attestation.POST("/sign", HandleSignWithAppDerivedKey)
+		attestation.POST("/vrf", HandleVrfWithAppDerivedKey)
+		attestation.POST("/nostr", HandleNostrWithAppDerivedKey)
 	}
 }
 
 type Attestation struct {
-	Cert           string `json:"deviceCert"`
+	Cert           string `json:"deviceCert" format:"hex"`
 	AttestationVer string `json:"attestationVer"`
 	TeePlatformVer uint32 `json:"teePlatformVer"`
-	Signature      string `json:"signature"`
+	Signature      string `json:"signature" format:"hex"`
+	Timestamp      uint64 `json:"timestamp"` // Timestamp is the unix time in seconds when the attestation was signed
+	Measurements
 }
 
 type ApplicationKey struct {
-	Cert   string `json:"appCert"`
-	PubKey string `json:"appPubKey"`
+	Cert   string `json:"appCert" format:"hex"`
+	PubKey string `json:"appPubKey" format:"hex"`
+	KeyFormats
 }
 
 type SignRequest struct {
-	Data string `json:"data"` // Data is the data to be signed
+	Data string `json:"data" format:"hex"` // Data is the data to be signed
 }
 
 type SignResponse struct {
-	PubKey    string `json:"pubKey"`
-	Signature string `json:"signature"`
+	PubKey    string `json:"pubKey" format:"hex"`
+	Signature string `json:"signature" format:"hex"`
+}
+
+type VrfRequest struct {
+	Alpha string `json:"alpha" format:"hex"` // Alpha is the VRF input
+}
+
+type VrfResponse struct {
+	Alpha  string `json:"alpha" format:"hex"`
+	Output string `json:"output" format:"hex"` // Output is the 32 bytes VRF output (beta)
+	Proof  string `json:"proof" format:"hex"`  // Proof is the 81 bytes ECVRF-SECP256K1-SHA256-TAI proof (gamma || c || s)
+	PubKey string `json:"appPubKey" format:"hex"`
+	Cert   string `json:"appCert" format:"hex"`
 }
 
 // HandleGetAppDerivedKey godoc
 // @Summary Get app derived key for current (simulated) tee version
 // @Description Get app derived key for current (simulated) tee version
 // @Tags attestation
-// @Accept application/json
-// @Produce application/json
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param formats query string false "Additional public key formats, comma separated: compressed, address, xonly, did or all"
 // @Success 200 {object} ApplicationKey
 // @Failure 400 {object} ErrorResponse
 // @Router /api/v1/attestation/appkey [get]
 func HandleGetAppDerivedKey(c *gin.Context) {
-	// First Derive Application Key
-	appKey := encryption.DerivePrivateKey(config.GetRootKey(), []byte(config.GetConfig().AppName))
-	appPublicKey := encryption.GetPublicKey(appKey)
-	// Get Device Cert
-	deviceCert := encryption.GetDeviceRootCert()
-	// Get Device Root Cert
-	deviceRoot := encryption.DerivePrivateKey(config.GetRootKey(), []byte(constants.DeviceRootKey))
-	deviceRootCert := encryption.GenerateCert(config.GetRootKey(), []byte(constants.DeviceRootKey))
-	applicationCert := encryption.GenerateCert(deviceRoot, []byte(config.GetConfig().AppName))
-	var cert []byte
-	cert = append(cert, deviceCert...)
-	cert = append(cert, deviceRootCert...)
-	cert = append(cert, applicationCert...)
-
-	resp := ApplicationKey{
-		Cert:   fmt.Sprintf("%x", cert),
-		PubKey: fmt.Sprintf("%x", appPublicKey),
+	resp, err := GetAppKey(c.Query("formats"))
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
 	}
-
-	c.JSON(200, resp)
+	respond(c, resp)
 }
 
 // HandleSignWithAppDerivedKey godoc
 // @Summary Sign with app derived key for current (simulated) tee version
 // @Description Sign with app derived key for current (simulated) tee version
 // @Tags attestation
-// @Accept application/json
-// @Produce application/json
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
 // @Param data body SignRequest true "Data to be signed"
 // @Success 200 {object} SignResponse
 // @Failure 400 {object} ErrorResponse
 // @Router /api/v1/attestation/sign [post]
 func HandleSignWithAppDerivedKey(c *gin.Context) {
-	appKey := encryption.DerivePrivateKey(config.GetRootKey(), []byte(config.GetConfig().AppName))
-	appPublicKey := encryption.GetPublicKey(appKey)
-	// Sign the data:
 	var req SignRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
 	}
-	// Decode hex string to byte array
-	data, err := hex.DecodeString(strings.TrimPrefix(req.Data, "0x"))
+	resp, err := SignWithAppKey(req)
 	if err != nil {
-		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedDecodeMessage})
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
 	}
-	signature, _ := encryption.Sign(data, appKey)
-	resp := SignResponse{
-		PubKey:    fmt.Sprintf("%x", appPublicKey),
-		Signature: fmt.Sprintf("%x", signature),
+	respond(c, resp)
+}
+
+// HandleVrfWithAppDerivedKey godoc
+// @Summary Compute a verifiable random output with app derived key for current (simulated) tee version
+// @Description Compute ECVRF-SECP256K1-SHA256-TAI output and proof of alpha with the app derived key, the proof can be verified against the appPubKey, which is the leaf of the appCert chain
+// @Tags attestation
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param data body VrfRequest true "VRF input"
+// @Success 200 {object} VrfResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/attestation/vrf [post]
+func HandleVrfWithAppDerivedKey(c *gin.Context) {
+	var req VrfRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	resp, err := VrfWithAppKey(req)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
 	}
-	c.JSON(200, resp)
+	respond(c, resp)
 }
@@ -0,0 +1,233 @@
+package web
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/nostr"
+	"teerminal/service/publisher"
+	"teerminal/service/sensor"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ugorji/go/codec"
+)
+
+// MIMECbor is served instead of JSON to clients accepting it, and accepted as request body
+const MIMECbor = "application/cbor"
+
+var cborHandle = &codec.CborHandle{BasicHandle: codec.BasicHandle{EncodeOptions: codec.EncodeOptions{Canonical: true}}}
+
+// cborTypeKey is the context key of the response type, whose fields tagged format:"hex" are raw byte strings in CBOR
+const cborTypeKey = "cborType"
+
+// respond writes v as the 200 JSON response, along with its type for the CBOR translation
+func respond(c *gin.Context, v interface{}) {
+	c.Set(cborTypeKey, responseType(v))
+	c.JSON(200, v)
+}
+
+func responseType(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// responseTypes are the types passed to respond, by name, for replayed responses
+var responseTypes = typesByName(
+	Attestation{}, ApplicationKey{}, SignResponse{}, VrfResponse{}, nostr.Event{}, Challenge{}, CounterResponse{},
+	Enrollment{}, StructuredEnrollment{}, DeviceKey{}, EatToken{}, NitroDocument{}, DcapQuote{},
+	WriteKvResponse{}, ReadKvResponse{}, DeleteKvResponse{}, QuotaResponse{},
+	ExtendMeasurementResponse{}, Measurements{}, HandshakeSession{}, RespondHandshakeResponse{}, ConfirmHandshakeResponse{},
+	[]publisher.RelayStatus{}, []publisher.Command{}, []config.Sensor{}, []sensor.Reading{},
+	SignatureDiagnostics{}, ChainDiagnostics{}, VerifyAttestationResponse{}, VerifyEnrollmentResponse{}, VerifyVrfResponse{},
+)
+
+func typesByName(values ...interface{}) map[string]reflect.Type {
+	types := make(map[string]reflect.Type, len(values))
+	for _, v := range values {
+		t := responseType(v)
+		types[t.String()] = t
+	}
+	return types
+}
+
+// jsonFields are the fields of the struct type by JSON name, with the fields of embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for embeddedName, embedded := range jsonFields(f.Type) {
+				fields[embeddedName] = embedded
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// toCbor converts a decoded JSON value of type t to its CBOR representation: the hex strings of the fields tagged
+// format:"hex" become byte strings, every other string is kept as text, and so is everything if t is unknown
+func toCbor(v interface{}, t reflect.Type, hexField bool) interface{} {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		var fields map[string]reflect.StructField
+		if t != nil && t.Kind() == reflect.Struct {
+			fields = jsonFields(t)
+		}
+		for key, value := range v {
+			var valueType reflect.Type
+			if t != nil && t.Kind() == reflect.Map {
+				valueType = t.Elem()
+			}
+			f, ok := fields[key]
+			if ok {
+				valueType = f.Type
+			}
+			v[key] = toCbor(value, valueType, ok && f.Tag.Get("format") == "hex")
+		}
+		return v
+	case []interface{}:
+		var itemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			itemType = t.Elem()
+		}
+		for i, item := range v {
+			v[i] = toCbor(item, itemType, hexField)
+		}
+		return v
+	case string:
+		if hexField && v != "" {
+			if decoded, err := decodeHex(v); err == nil {
+				return decoded
+			}
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+// fromCbor converts a decoded CBOR value to its JSON representation: byte strings become hex strings
+func fromCbor(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if s, ok := key.(string); ok {
+				converted[s] = fromCbor(value)
+			}
+		}
+		return converted
+	case []interface{}:
+		for i, item := range v {
+			v[i] = fromCbor(item)
+		}
+		return v
+	case []byte:
+		return hex.EncodeToString(v)
+	default:
+		return v
+	}
+}
+
+// wantsCbor reports whether the client prefers CBOR to JSON
+func wantsCbor(c *gin.Context) bool {
+	return c.GetHeader("Accept") != "" && c.NegotiateFormat(binding.MIMEJSON, MIMECbor) == MIMECbor
+}
+
+// HandleCbor is the middleware translating CBOR requests to JSON and JSON responses to CBOR for the /api/v1 routes,
+// so the handlers, recordings and fault injection only deal with JSON
+func HandleCbor(c *gin.Context) {
+	if !strings.HasPrefix(c.Request.URL.Path, "/api/v1/") || isStreaming(c.Request.URL.Path) {
+		c.Next()
+		return
+	}
+	cborRequest := c.ContentType() == MIMECbor
+	cborResponse := wantsCbor(c)
+	if !cborRequest && !cborResponse {
+		c.Next()
+		return
+	}
+	original := c.Writer
+	writer := &bufferedWriter{ResponseWriter: original}
+	if cborResponse {
+		c.Writer = writer
+	}
+	if cborRequest {
+		var decoded interface{}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, constants.MaxRequestBodyLength))
+		if err == nil && len(body) > 0 {
+			err = codec.NewDecoderBytes(body, cborHandle).Decode(&decoded)
+		}
+		var converted []byte
+		if err == nil {
+			converted, err = json.Marshal(fromCbor(decoded))
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(413, ErrorResponse{Error: constants.MsgErrorRequestTooLarge})
+		} else if err != nil {
+			c.AbortWithStatusJSON(400, ErrorResponse{Error: constants.MsgErrorInvalidCbor})
+		} else {
+			c.Request.Body = io.NopCloser(bytes.NewReader(converted))
+			c.Request.ContentLength = int64(len(converted))
+			c.Request.Header.Set("Content-Type", binding.MIMEJSON)
+			c.Next()
+		}
+	} else {
+		c.Next()
+	}
+	if !cborResponse {
+		return
+	}
+	c.Writer = original
+	body := writer.body.Bytes()
+	if strings.HasPrefix(original.Header().Get("Content-Type"), binding.MIMEJSON) {
+		var decoded interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		// Error responses have no bytes fields
+		var t reflect.Type
+		if value, ok := c.Get(cborTypeKey); ok && original.Status() < 300 {
+			t = value.(reflect.Type)
+		}
+		var encoded []byte
+		if decoder.Decode(&decoded) == nil && codec.NewEncoderBytes(&encoded, cborHandle).Encode(toCbor(decoded, t, false)) == nil {
+			body = encoded
+			original.Header().Set("Content-Type", MIMECbor)
+		}
+	}
+	original.Write(body)
+}
@@ -0,0 +1,48 @@
+package web
+
+import (
+	"errors"
+	"teerminal/constants"
+	"teerminal/service/challenge"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Challenge struct {
+	Nonce     string `json:"nonce" format:"hex"` // Nonce is the 64 bytes device-issued nonce in hex
+	ExpiresAt int64  `json:"expiresAt"`          // ExpiresAt is the unix timestamp in seconds after which the nonce is rejected
+}
+
+// HandleGetChallenge godoc
+// @Summary Get a nonce for version attestation
+// @Description Issue a fresh nonce, which must be used as the nonce of /api/v1/device/version before it expires, and can only be used once
+// @Tags device
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Success 200 {object} Challenge
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/device/challenge [get]
+func HandleGetChallenge(c *gin.Context) {
+	resp, err := IssueChallenge()
+	if errors.Is(err, constants.ErrorTooManyChallenges) {
+		c.JSON(429, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	respond(c, resp)
+}
+
+// consumeChallenge consumes the device-issued nonce, and writes the error response if it is unknown, expired or reused
+func consumeChallenge(c *gin.Context, nonce []byte) bool {
+	if err := challenge.Consume(nonce); err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package web
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/counter"
+	"teerminal/service/encryption"
+	"teerminal/service/identity"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /api/v1/counter
+
+func RegisterCounterRoutes(router *gin.Engine) {
+	counterGroup := router.Group("/api/v1/counter")
+	{
+		counterGroup.POST("/create", HandleCreateCounter)
+		counterGroup.POST("/increment", HandleIncrementCounter)
+		counterGroup.GET("/read", HandleReadCounter)
+	}
+}
+
+type CounterRequest struct {
+	Name  string `json:"name"`                         // Name is the name of the counter
+	Nonce string `json:"nonce,omitempty" format:"hex"` // Nonce is the caller's nonce of up to 64 bytes, signed along with the value to prove it is fresh, optional
+}
+
+type CounterResponse struct {
+	Name      string `json:"name"`
+	Value     uint64 `json:"value"`
+	Nonce     string `json:"nonce,omitempty" format:"hex"` // Nonce is the caller's nonce, if any
+	PubKey    string `json:"appPubKey" format:"hex"`
+	Signature string `json:"signature" format:"hex"` // Signature is the app key signature over CounterSignable(name, value, nonce)
+}
+
+// CounterSignable builds the payload signed by the app key for a counter value:
+// "TEERMINAL_COUNTER:" || keccak256(name) || value (8 bytes big endian) [|| nonce]
+func CounterSignable(name string, value uint64, nonce []byte) (signable []byte) {
+	signable = append(signable, []byte(constants.CounterSignPrefix)...)
+	signable = append(signable, crypto.Keccak256([]byte(name))...)
+	signable = binary.BigEndian.AppendUint64(signable, value)
+	signable = append(signable, nonce...)
+	return
+}
+
+func respondCounter(c *gin.Context, entry counter.Entry, nonce []byte, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, constants.ErrorCounterExists),
+			errors.Is(err, constants.ErrorCounterDoesNotExist),
+			errors.Is(err, constants.ErrorCounterQuotaReached),
+			errors.Is(err, constants.ErrorCounterOverflow):
+			c.JSON(400, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(500, ErrorResponse{Error: constants.MsgErrorFailedPersistCounter})
+		}
+		c.Next()
+		return
+	}
+	id := identity.Get()
+	signature, _ := encryption.Sign(id.AppKey, CounterSignable(entry.Name, entry.Value, nonce))
+	respond(c, CounterResponse{
+		Name:      entry.Name,
+		Value:     entry.Value,
+		Nonce:     fmt.Sprintf("%x", nonce),
+		PubKey:    fmt.Sprintf("%x", id.AppPubKey),
+		Signature: fmt.Sprintf("%x", signature),
+	})
+}
+
+func bindCounter(c *gin.Context) (string, []byte, bool) {
+	var req CounterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return "", nil, false
+	}
+	return checkCounter(c, req.Name, req.Nonce)
+}
+
+func checkCounter(c *gin.Context, name string, nonceHex string) (string, []byte, bool) {
+	if name == "" || len(name) > constants.MaxCounterNameLength {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidCounterName})
+		c.Next()
+		return "", nil, false
+	}
+	nonce, err := decodeHex(nonceHex)
+	if err != nil || len(nonce) > constants.MaxCounterNonceLength {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidCounterNonce})
+		c.Next()
+		return "", nil, false
+	}
+	return name, nonce, true
+}
+
+// HandleCreateCounter godoc
+// @Summary Create a persistent monotonic counter
+// @Description Create a named monotonic counter for the current application starting at 0, the counter survives restarts
+// @Tags counter
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param request body CounterRequest true "Counter to create"
+// @Success 200 {object} CounterResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/counter/create [post]
+func HandleCreateCounter(c *gin.Context) {
+	name, nonce, ok := bindCounter(c)
+	if !ok {
+		return
+	}
+	entry, err := counter.Create(config.GetConfig().AppName, name)
+	respondCounter(c, entry, nonce, err)
+}
+
+// HandleIncrementCounter godoc
+// @Summary Increment a persistent monotonic counter
+// @Description Increment a named monotonic counter of the current application, the new value is persisted before it is returned
+// @Tags counter
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param request body CounterRequest true "Counter to increment"
+// @Success 200 {object} CounterResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/counter/increment [post]
+func HandleIncrementCounter(c *gin.Context) {
+	name, nonce, ok := bindCounter(c)
+	if !ok {
+		return
+	}
+	entry, err := counter.Increment(config.GetConfig().AppName, name)
+	respondCounter(c, entry, nonce, err)
+}
+
+// HandleReadCounter godoc
+// @Summary Read a persistent monotonic counter
+// @Description Read a named monotonic counter of the current application, the value is signed by the app key
+// @Tags counter
+// @Produce application/json,application/cbor
+// @Param name query string true "Counter name"
+// @Param nonce query string false "Caller's nonce of up to 64 bytes in hex, signed along with the value"
+// @Success 200 {object} CounterResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/counter/read [get]
+func HandleReadCounter(c *gin.Context) {
+	name, nonce, ok := checkCounter(c, c.Query("name"), c.Query("nonce"))
+	if !ok {
+		return
+	}
+	entry, err := counter.Load(config.GetConfig().AppName, name)
+	respondCounter(c, entry, nonce, err)
+}
@@ -2,32 +2,42 @@ package web
 
 import (
 	"encoding/binary"
-	"encoding/hex"
 	"fmt"
-	"strings"
 	"teerminal/config"
 	"teerminal/constants"
 	"teerminal/service/encryption"
+	"teerminal/service/identity"
+	"teerminal/service/measurement"
+	"time"
 
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/gin-gonic/gin"
 )
 
 type Enrollment struct {
-	DeviceKey string `json:"deviceKey"`
-	Payload   string `json:"payload"`
-	Signature string `json:"signature"`
+	DeviceKey string `json:"deviceKey" format:"hex"`
+	Payload   string `json:"payload" format:"hex"`
+	Signature string `json:"signature" format:"hex"`
 }
 
+const (
+	AttestationFormatNative = "native"
+	AttestationFormatDcap   = "dcap"
+	AttestationFormatNitro  = "nitro"
+	AttestationFormatEat    = "eat"
+)
+
 type DeviceKey struct {
-	Cert   string `json:"deviceCert"`
-	PubKey string `json:"devicePubKey"`
+	Cert   string `json:"deviceCert" format:"hex"`
+	PubKey string `json:"devicePubKey" format:"hex"`
+	KeyFormats
 }
 
 func RegisterDeviceRoutes(router *gin.Engine) {
 	device := router.Group("/api/v1/device")
 	{
 		device.POST("/sign", HandleDeviceSign)
+		device.POST("/enroll", HandleDeviceEnroll)
+		device.GET("/challenge", HandleGetChallenge)
 		device.GET("/version", HandleGetVersionAttestation)
 		device.GET("/key", HandleDeviceKey)
 	}
@@ -36,68 +46,79 @@ func RegisterDeviceRoutes(router *gin.Engine) {
 // HandleGetVersionAttestation godoc
 // @Summary Get version attestation for current (simulated) tee version
 // @Description Get version attestation for current (simulated) tee version
+// @Description With format=dcap, an emulated SGX DCAP quote (web.DcapQuote) is returned instead, whose report data is sha256(nonce) || sha256(app public key)
+// @Description With format=nitro, an emulated Nitro Enclaves attestation document (web.NitroDocument) is returned instead
+// @Description With format=eat, an IETF Entity Attestation Token (web.EatToken) signed by the device root key is returned instead, encoded as a JWT (ES256K) or a CWT, the registers digest and the event log are the claims teerminal_registers_digest and teerminal_event_log (JWT) or -65538 and -65539 (CWT)
+// @Description Nonces must be issued by /api/v1/device/challenge, and are rejected once expired or used
 // @Tags device
-// @Accept application/json
-// @Produce application/json
-// @Param attestation query string false "Remote requester's nonce and signature, serialized as hex(64b nonce || 64b pubKey || 65b signature), in which signature is the signature of nonce || pubKey, if signature not provided, omit signature"
-// @Success 200 {object} Attestation
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param attestation query string false "Remote requester's nonce and signature, serialized as hex(64b nonce || 64b pubKey || 65b signature), in which nonce is issued by /api/v1/device/challenge and signature is the requester's signature of nonce || pubKey, leave empty for an unsigned attestation without nonce"
+// @Param format query string false "Attestation format: native (default), dcap, nitro or eat"
+// @Param nonce query string false "Nonce issued by /api/v1/device/challenge in hex, required by dcap and eat formats, optional for nitro format"
+// @Param quoteVersion query int false "DCAP quote version, 3 (default) or 4"
+// @Param userData query string false "User data in hex, embedded by nitro format"
+// @Param publicKey query string false "Public key in hex, embedded by nitro format"
+// @Param encoding query string false "EAT encoding: jwt (default) or cwt"
+// @Success 200 {object} Attestation "Attestation in native format, DcapQuote in dcap format, NitroDocument in nitro format, EatToken in eat format"
 // @Failure 400 {object} ErrorResponse
 // @Router /api/v1/device/version [get]
 func HandleGetVersionAttestation(c *gin.Context) {
-	// First check if attestation is provided
-	attestation := c.Query("attestation")
-	if attestation == "" {
-		c.JSON(200, Attestation{AttestationVer: config.GetConfig().Version, TeePlatformVer: config.GetConfig().TeePlatformVersion})
-		c.Next()
+	switch c.Query("format") {
+	case "", AttestationFormatNative:
+	case AttestationFormatDcap:
+		HandleGetDcapQuote(c)
 		return
-	}
-	// Decode Attestation to bytes
-	attestationRawHex := strings.TrimPrefix("0x", attestation)
-	attestationRaw, err := hex.DecodeString(attestationRawHex)
-	if err != nil {
-		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedHexDecodeRemoteAttestation})
-		c.Next()
+	case AttestationFormatNitro:
+		HandleGetNitroDocument(c)
 		return
-	}
-	// Check attestationRaw length
-	if len(attestationRaw) != 173 {
-		c.JSON(400, ErrorResponse{Error: constants.MsgErrorWrongRemoteAttestationLength})
+	case AttestationFormatEat:
+		HandleGetEat(c)
+		return
+	default:
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorUnknownAttestationFormat})
 		c.Next()
 		return
 	}
-	// Parse nonce, pubKey and signature
-	nonce := attestationRaw[:64]
-	pubKey := attestationRaw[64:128]
-	signature := attestationRaw[128:]
-	// First do the verification of the signature
-	if !encryption.VerifySignature(nonce, pubKey, signature) {
-		c.JSON(400, ErrorResponse{Error: constants.MsgErrorWrongRemoteAttestationSignature})
+	resp, err := GetVersionAttestation(c.Query("attestation"))
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
 		c.Next()
 		return
 	}
-	// Then sign (nonce || pubKey || teePlatformVersion || version) with the local private key
+	respond(c, resp)
+}
+
+// SignVersionAttestation signs (nonce || pubKey || teePlatformVersion || version || timestamp || keccak256(registers)) with the device root key
+func SignVersionAttestation(nonce []byte, pubKey []byte) Attestation {
 	version := config.GetConfig().Version
-	var signable []byte
-	signable = append(signable, nonce...)
-	signable = append(signable, pubKey...)
-	platformVersionBytes := binary.BigEndian.AppendUint32([]byte{}, config.GetConfig().TeePlatformVersion)
-	signable = append(signable, platformVersionBytes...)
-	signable = append(signable, []byte(version)...)
-	deviceRoot := encryption.DerivePrivateKey(config.GetRootKey(), []byte(constants.DeviceRootKey))
-	deviceCert := encryption.GetDeviceRootCert()
-	deviceRootCert := encryption.GenerateCert(config.GetRootKey(), []byte(constants.DeviceRootKey))
-	signature, _ = encryption.Sign(signable, deviceRoot)
-	// Create Concrete Cert
-	var cert []byte
-	cert = append(cert, deviceCert...)
-	cert = append(cert, deviceRootCert...)
-	// Return the attestation
-	c.JSON(200, Attestation{
-		Cert:           fmt.Sprintf("%x", cert),
+	timestamp := uint64(time.Now().Unix())
+	registers, measurements := currentMeasurements()
+	signable := VersionSignable(nonce, pubKey, config.GetConfig().TeePlatformVersion, version, timestamp, registers)
+	id := identity.Get()
+	signature, _ := encryption.Sign(id.DeviceKey, signable)
+	// Concrete Cert is device cert || device root cert
+	return Attestation{
+		Cert:           fmt.Sprintf("%x", id.DeviceChain),
 		AttestationVer: version,
 		TeePlatformVer: config.GetConfig().TeePlatformVersion,
 		Signature:      fmt.Sprintf("%x", signature),
-	})
+		Timestamp:      timestamp,
+		Measurements:   measurements,
+	}
+}
+
+// VersionSignable builds the payload signed by the device root key for version attestation:
+// nonce || pubKey || teePlatformVersion (4 bytes big endian) || version || timestamp (8 bytes big endian)
+// || keccak256(register 0 || ... || register n)
+func VersionSignable(nonce []byte, pubKey []byte, teePlatformVersion uint32, version string, timestamp uint64, registers [][]byte) (signable []byte) {
+	signable = append(signable, nonce...)
+	signable = append(signable, pubKey...)
+	signable = binary.BigEndian.AppendUint32(signable, teePlatformVersion)
+	signable = append(signable, []byte(version)...)
+	signable = binary.BigEndian.AppendUint64(signable, timestamp)
+	signable = append(signable, measurement.Digest(registers)...)
+	return
 }
 
 // HandleDeviceSign godoc
@@ -105,9 +126,9 @@ func HandleGetVersionAttestation(c *gin.Context) {
 // @Description Get device enrollment key for current (simulated) tee version
 // @Description Please see also the DePhy evm sdk.
 // @Tags device
-// @Accept application/json
+// @Accept application/json,application/cbor
 // @Param signRequest body SignRequest true "Data to be signed"
-// @Produce application/json
+// @Produce application/json,application/cbor
 // @Success 200 {object} Enrollment
 // @Router /api/v1/device/sign [post]
 func HandleDeviceSign(c *gin.Context) {
@@ -117,49 +138,30 @@ func HandleDeviceSign(c *gin.Context) {
 		c.Next()
 		return
 	}
-	data, err := hex.DecodeString(strings.TrimPrefix(req.Data, "0x"))
+	resp, err := SignEnrollment(req)
 	if err != nil {
-		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedDecodeMessage})
+		c.JSON(400, ErrorResponse{Error: err.Error()})
 		return
 	}
-	// eth keccak256 hash of the deadline
-	msgHash := crypto.Keccak256(data)
-	msgSignPayload := append([]byte(constants.DeviceEnrollmentKey), msgHash...)
-	// Sign the payload with the root key
-	deviceRoot := encryption.DerivePrivateKey(config.GetRootKey(), []byte(constants.DeviceRootKey))
-	deviceRootPublic := encryption.GetPublicKey(deviceRoot)
-	signature, _ := encryption.Sign(deviceRoot, msgSignPayload)
-	// Return the enrollment key and deadline as hex
-	c.JSON(200, Enrollment{
-		DeviceKey: fmt.Sprintf("%x", deviceRootPublic),
-		Payload:   req.Data,
-		Signature: fmt.Sprintf("%x", signature),
-	})
-
+	respond(c, resp)
 }
 
 // HandleDeviceKey godoc
 // @Summary Get device key for current (simulated) tee version
 // @Description Get device key for current (simulated) tee version
 // @Tags device
-// @Accept application/json
-// @Produce application/json
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param formats query string false "Additional public key formats, comma separated: compressed, address, xonly, did or all"
 // @Success 200 {object} DeviceKey
+// @Failure 400 {object} ErrorResponse
 // @Router /api/v1/device/key [get]
-func HandleDeviceKey(c *gin.Context) { // Get Device Cert
-	deviceCert := encryption.GetDeviceRootCert()
-	// Get Device Root Cert
-	deviceRoot := encryption.DerivePrivateKey(config.GetRootKey(), []byte(constants.DeviceRootKey))
-	deviceRootCert := encryption.GenerateCert(config.GetRootKey(), []byte(constants.DeviceRootKey))
-	deviceCertPubKey := encryption.GetPublicKey(deviceRoot)
-	var cert []byte
-	cert = append(cert, deviceCert...)
-	cert = append(cert, deviceRootCert...)
-
-	resp := ApplicationKey{
-		Cert:   fmt.Sprintf("%x", cert),
-		PubKey: fmt.Sprintf("%x", deviceCertPubKey),
+func HandleDeviceKey(c *gin.Context) { // Get Device Cert and Device Root Cert
+	resp, err := GetDeviceKey(c.Query("formats"))
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
 	}
-
-	c.JSON(200, resp)
+	respond(c, resp)
 }
@@ -0,0 +1,67 @@
+package web
+
+import (
+	"fmt"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/eat"
+	"teerminal/service/identity"
+	"teerminal/service/measurement"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EatToken struct {
+	Token    string `json:"token"`    // Token is the compact JWT, or the hex encoded CWT, text in CBOR as well
+	Encoding string `json:"encoding"` // Encoding is jwt or cwt
+}
+
+// HandleGetEat serves /api/v1/device/version?format=eat
+func HandleGetEat(c *gin.Context) {
+	encoding := c.DefaultQuery("encoding", eat.EncodingJwt)
+	if encoding != eat.EncodingJwt && encoding != eat.EncodingCwt {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorUnknownEatEncoding})
+		c.Next()
+		return
+	}
+	nonceHex := c.Query("nonce")
+	if nonceHex == "" {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorMissingNonce})
+		c.Next()
+		return
+	}
+	nonce, err := decodeHex(nonceHex)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedDecodeNonce})
+		c.Next()
+		return
+	}
+	if !consumeChallenge(c, nonce) {
+		return
+	}
+	cfg := config.GetConfig()
+	id := identity.Get()
+	registers, measurements := currentMeasurements()
+	token, err := eat.Build(encoding, eat.Claims{
+		Nonce:              nonce,
+		Ueid:               eat.Ueid(id.DevicePubKey),
+		HardwareModel:      eat.HardwareModel,
+		SoftwareName:       cfg.AppName,
+		SoftwareVersion:    cfg.Version,
+		TeePlatformVersion: cfg.TeePlatformVersion,
+		IssuedAt:           time.Now().Unix(),
+		RegistersDigest:    measurement.Digest(registers),
+		EventLog:           measurements.EventLog,
+	}, id.DeviceKey, id.DevicePubKey, id.DeviceChain)
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: constants.MsgErrorFailedGenerateEat})
+		c.Next()
+		return
+	}
+	resp := EatToken{Token: string(token), Encoding: encoding}
+	if encoding == eat.EncodingCwt {
+		resp.Token = fmt.Sprintf("%x", token)
+	}
+	respond(c, resp)
+}
@@ -0,0 +1,56 @@
+package web
+
+import (
+	"teerminal/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EnrollRequest struct {
+	ChainId  uint64 `json:"chainId"`            // ChainId is the EVM chain id the enrollment is valid on
+	Registry string `json:"registry"`           // Registry is the address of the registry contract the enrollment is submitted to
+	Owner    string `json:"owner"`              // Owner is the address the device is enrolled to
+	Deadline uint64 `json:"deadline"`           // Deadline is the unix timestamp in seconds after which the enrollment is refused, must be in the future
+	Encoding string `json:"encoding,omitempty"` // Encoding is canonical (default) or eip712
+}
+
+type StructuredEnrollment struct {
+	DeviceKey string `json:"deviceKey" format:"hex"`
+	Encoding  string `json:"encoding"`
+	ChainId   uint64 `json:"chainId"`
+	Registry  string `json:"registry"`
+	Owner     string `json:"owner"`
+	Deadline  uint64 `json:"deadline"`
+	Payload   string `json:"payload" format:"hex"`   // Payload is abi.encode(chainId, registry, owner, deadline)
+	Signable  string `json:"signable" format:"hex"`  // Signable is the exact payload signed by the device root key, see enrollment.StructuredSignable
+	Digest    string `json:"digest" format:"hex"`    // Digest is keccak256(signable), the hash passed to ecrecover
+	Signature string `json:"signature" format:"hex"` // Signature is the 65 bytes r || s || v signature
+}
+
+// HandleDeviceEnroll godoc
+// @Summary Sign a structured enrollment with the device root key
+// @Description Sign an enrollment bound to a chain id, a registry contract, an owner and a deadline, so it can not be replayed on another chain or registry
+// @Description With encoding=canonical, the signable is "TEERMINAL_STRUCTURED_ENROLLMENT:" || keccak256(abi.encode(uint256 chainId, address registry, address owner, uint256 deadline)), whose prefix differs from /api/v1/device/sign
+// @Description With encoding=eip712, the signable is "\x19\x01" || domainSeparator || hashStruct(Enrollment(address owner,uint256 deadline)), in which the domain is EIP712Domain(name "Teerminal", version "1", chainId, verifyingContract registry)
+// @Tags device
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param request body EnrollRequest true "Enrollment fields"
+// @Success 200 {object} StructuredEnrollment
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/device/enroll [post]
+func HandleDeviceEnroll(c *gin.Context) {
+	req := EnrollRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	resp, err := Enroll(req)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	respond(c, resp)
+}
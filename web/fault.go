@@ -0,0 +1,274 @@
+package web
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/fault"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /api/v1/admin
+
+const FaultHeader = "X-Teerminal-Fault" // FaultHeader lists the ids of the faults injected into a response
+
+func RegisterAdminRoutes(router *gin.Engine) {
+	admin := router.Group("/api/v1/admin", HandleAdminAuth)
+	{
+		admin.GET("/fault", HandleGetFaultProfile)
+		admin.PUT("/fault", HandleSetFaultProfile)
+		admin.DELETE("/fault", HandleResetFaultProfile)
+		admin.GET("/fault/events", HandleGetFaultEvents)
+	}
+}
+
+// HandleAdminAuth requires the admin token from the config as a bearer token, the admin api is disabled without a token
+func HandleAdminAuth(c *gin.Context) {
+	token := config.GetConfig().AdminToken
+	if token == "" {
+		c.AbortWithStatusJSON(403, ErrorResponse{Error: constants.MsgErrorAdminDisabled})
+		return
+	}
+	given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		c.AbortWithStatusJSON(401, ErrorResponse{Error: constants.MsgErrorUnauthorized})
+		return
+	}
+	c.Next()
+}
+
+// bufferedWriter buffers the response body, so it can be inspected or changed before it is sent
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// faultInjection applies the body faults of a rule to a decoded json response
+type faultInjection struct {
+	rule     fault.Rule
+	method   string
+	endpoint string
+	ids      []string
+}
+
+func (f *faultInjection) record(kind string, detail string) {
+	f.ids = append(f.ids, strconv.FormatUint(fault.Record(f.method, f.endpoint, kind, detail), 10))
+}
+
+func (f *faultInjection) mutateChain(field string, value string) string {
+	chain, err := decodeHex(value)
+	if err != nil || len(chain) == 0 || len(chain)%encryption.CertLength != 0 {
+		return value
+	}
+	if fault.Fire(f.rule.TruncateChainRate) {
+		chain = chain[:len(chain)-encryption.CertLength]
+		f.record(fault.KindTruncateChain, field)
+	}
+	if len(chain) > 0 && fault.Fire(f.rule.CorruptCertRate) {
+		// The cert signature is the last 65 bytes of the cert, corrupt r
+		chain[len(chain)-65] ^= 0xff
+		f.record(fault.KindCorruptCert, field)
+	}
+	return fmt.Sprintf("%x", chain)
+}
+
+func (f *faultInjection) mutateSignature(field string, value string) string {
+	signature, err := decodeHex(value)
+	if err != nil || len(signature) == 0 || !fault.Fire(f.rule.FlipSignatureRate) {
+		return value
+	}
+	signature[0] ^= 0x01
+	f.record(fault.KindFlipSignature, field)
+	return fmt.Sprintf("%x", signature)
+}
+
+func (f *faultInjection) walk(v interface{}) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, item := range v {
+			f.walk(item)
+		}
+	case map[string]interface{}:
+		for key, value := range v {
+			switch value := value.(type) {
+			case string:
+				if strings.HasSuffix(key, "Cert") || strings.HasSuffix(key, "Chain") {
+					v[key] = f.mutateChain(key, value)
+				} else if key == "signature" || strings.HasSuffix(key, "Signature") {
+					v[key] = f.mutateSignature(key, value)
+				}
+			case json.Number:
+				if key == "teePlatformVer" && fault.Fire(f.rule.DowngradeRate) {
+					if n, err := value.Int64(); err == nil && n > 0 {
+						v[key] = n - 1
+						f.record(fault.KindDowngrade, fmt.Sprintf("%s: %d -> %d", key, n, n-1))
+					}
+				}
+			default:
+				f.walk(value)
+			}
+		}
+	}
+}
+
+// HandleFaultInjection is the middleware injecting the faults of the active profile
+func HandleFaultInjection(c *gin.Context) {
+	endpoint := c.FullPath()
+	if !strings.HasPrefix(endpoint, "/api/") || strings.HasPrefix(endpoint, "/api/v1/admin") {
+		c.Next()
+		return
+	}
+	rule, ok := fault.Match(endpoint)
+	if !ok {
+		c.Next()
+		return
+	}
+	f := &faultInjection{rule: rule, method: c.Request.Method, endpoint: endpoint}
+	if rule.LatencyMs > 0 {
+		time.Sleep(time.Duration(rule.LatencyMs) * time.Millisecond)
+		f.record(fault.KindLatency, fmt.Sprintf("%dms", rule.LatencyMs))
+	}
+	if fault.Fire(rule.ErrorRate) {
+		status := rule.ErrorStatus
+		if status == 0 {
+			status = 500
+		}
+		f.record(fault.KindServerError, strconv.Itoa(status))
+		c.Header(FaultHeader, strings.Join(f.ids, ","))
+		c.AbortWithStatusJSON(status, ErrorResponse{Error: constants.MsgErrorInjectedFault})
+		return
+	}
+	// Streams can not be buffered, only latency and errors apply to them
+	if isStreaming(endpoint) || (rule.CorruptCertRate == 0 && rule.TruncateChainRate == 0 && rule.DowngradeRate == 0 && rule.FlipSignatureRate == 0) {
+		if len(f.ids) > 0 {
+			c.Header(FaultHeader, strings.Join(f.ids, ","))
+		}
+		c.Next()
+		return
+	}
+	original := c.Writer
+	writer := &bufferedWriter{ResponseWriter: original}
+	c.Writer = writer
+	c.Next()
+	c.Writer = original
+	body := writer.body.Bytes()
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err == nil {
+		f.walk(decoded)
+		if mutated, err := json.Marshal(decoded); err == nil && len(f.ids) > 0 {
+			body = mutated
+		}
+	}
+	if len(f.ids) > 0 {
+		original.Header().Set(FaultHeader, strings.Join(f.ids, ","))
+	}
+	original.Write(body)
+}
+
+// HandleGetFaultProfile godoc
+// @Summary Get the fault injection profile
+// @Description Get the active fault injection profile, requires the admin token
+// @Tags admin
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param Authorization header string true "Bearer admin token"
+// @Success 200 {object} fault.Profile
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/fault [get]
+func HandleGetFaultProfile(c *gin.Context) {
+	respond(c, fault.Get())
+}
+
+// HandleSetFaultProfile godoc
+// @Summary Set the fault injection profile
+// @Description Replace the active fault injection profile, faults are scoped per endpoint and fire at their configured rates, requires the admin token
+// @Tags admin
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param Authorization header string true "Bearer admin token"
+// @Param profile body fault.Profile true "Fault injection profile"
+// @Success 200 {object} fault.Profile
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/fault [put]
+func HandleSetFaultProfile(c *gin.Context) {
+	var profile fault.Profile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	if err := fault.Set(profile); err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	respond(c, fault.Get())
+}
+
+// HandleResetFaultProfile godoc
+// @Summary Clear the fault injection profile
+// @Description Clear the active fault injection profile and the recorded fault events, requires the admin token
+// @Tags admin
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param Authorization header string true "Bearer admin token"
+// @Success 200 {object} fault.Profile
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/fault [delete]
+func HandleResetFaultProfile(c *gin.Context) {
+	fault.Reset()
+	respond(c, fault.Get())
+}
+
+// HandleGetFaultEvents godoc
+// @Summary Get the injected faults
+// @Description Get the injected faults, every response with injected faults carries their ids in the X-Teerminal-Fault header, requires the admin token
+// @Tags admin
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param Authorization header string true "Bearer admin token"
+// @Param since query int false "Only return events with an id greater than since"
+// @Success 200 {array} fault.Event
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/fault/events [get]
+func HandleGetFaultEvents(c *gin.Context) {
+	since := uint64(0)
+	if s := c.Query("since"); s != "" {
+		parsed, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidFaultEventId})
+			c.Next()
+			return
+		}
+		since = parsed
+	}
+	events := fault.Events(since)
+	if events == nil {
+		events = []fault.Event{}
+	}
+	respond(c, events)
+}
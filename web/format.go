@@ -0,0 +1,63 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+)
+
+const (
+	KeyFormatCompressed = "compressed"
+	KeyFormatAddress    = "address"
+	KeyFormatXOnly      = "xonly"
+	KeyFormatDidKey     = "did"
+	KeyFormatAll        = "all"
+)
+
+// KeyFormats holds the additional representations of a public key, only the requested ones are filled
+type KeyFormats struct {
+	Compressed string `json:"compressed,omitempty" format:"hex"` // Compressed is the 33 bytes SEC1 compressed public key
+	Address    string `json:"address,omitempty"`                 // Address is the EIP-55 checksummed ethereum address
+	XOnly      string `json:"xOnly,omitempty" format:"hex"`      // XOnly is the 32 bytes BIP-340 x-only public key
+	DidKey     string `json:"didKey,omitempty"`                  // DidKey is the did:key identifier of the public key
+}
+
+// NewKeyFormats builds the formats listed in query (comma separated) for a 64 bytes public key
+func NewKeyFormats(pubKey []byte, query string) (KeyFormats, error) {
+	formats := KeyFormats{}
+	if query == "" {
+		return formats, nil
+	}
+	requested := strings.Split(strings.ToLower(query), ",")
+	for _, format := range requested {
+		if strings.TrimSpace(format) == KeyFormatAll {
+			requested = []string{KeyFormatCompressed, KeyFormatAddress, KeyFormatXOnly, KeyFormatDidKey}
+			break
+		}
+	}
+	for _, format := range requested {
+		var err error
+		switch strings.TrimSpace(format) {
+		case KeyFormatCompressed:
+			var compressed []byte
+			compressed, err = encryption.CompressPublicKey(pubKey)
+			formats.Compressed = fmt.Sprintf("%x", compressed)
+		case KeyFormatAddress:
+			formats.Address, err = encryption.PublicKeyToAddress(pubKey)
+		case KeyFormatXOnly:
+			var xOnly []byte
+			xOnly, err = encryption.XOnlyPublicKey(pubKey)
+			formats.XOnly = fmt.Sprintf("%x", xOnly)
+		case KeyFormatDidKey:
+			formats.DidKey, err = encryption.PublicKeyToDidKey(pubKey)
+		default:
+			err = errors.New(constants.MsgErrorUnknownKeyFormat)
+		}
+		if err != nil {
+			return KeyFormats{}, err
+		}
+	}
+	return formats, nil
+}
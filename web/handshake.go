@@ -0,0 +1,462 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/challenge"
+	"teerminal/service/encryption"
+	"teerminal/service/handshake"
+	"teerminal/service/identity"
+	"teerminal/service/ratls"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /api/v1/handshake
+
+// Mutual attestation between two devices, device A (initiator) and device B (responder):
+// 1. A fetches a nonce from B's /api/v1/device/challenge, and issues its own nonce
+// 2. A sends its version attestation over (B's nonce, A's ephemeral key) to B's /api/v1/handshake/respond
+// 3. B verifies A, derives the session key, and answers with its attestation over (A's nonce, B's ephemeral key) and its session signature
+// 4. A verifies B, derives the same session key, and sends its session signature to B's /api/v1/handshake/confirm
+
+func RegisterHandshakeRoutes(router *gin.Engine) {
+	hs := router.Group("/api/v1/handshake")
+	{
+		// Initiating makes the device call out to the peer url, and sessions carry the session key,
+		// so both are for the application on this device only
+		hs.POST("/initiate", HandleAdminAuth, HandleInitiateHandshake)
+		hs.POST("/respond", HandleRespondHandshake)
+		hs.POST("/confirm", HandleConfirmHandshake)
+		hs.GET("/session", HandleAdminAuth, HandleGetSession)
+	}
+}
+
+type InitiateHandshakeRequest struct {
+	Peer string `json:"peer"`                        // Peer is the base url of the peer device, e.g. http://127.0.0.1:4101
+	Root string `json:"root,omitempty" format:"hex"` // Root is the 64 bytes vendor root public key of the peer, leave empty to use the trusted vendor roots
+}
+
+type RespondHandshakeRequest struct {
+	Nonce        string      `json:"nonce" format:"hex"`        // Nonce is the nonce issued by the responder's /api/v1/device/challenge
+	EphemeralKey string      `json:"ephemeralKey" format:"hex"` // EphemeralKey is the initiator's 64 bytes ephemeral public key
+	Challenge    string      `json:"challenge" format:"hex"`    // Challenge is the nonce issued by the initiator, which the responder attests over
+	Attestation  Attestation `json:"attestation"`               // Attestation is the initiator's version attestation over nonce || ephemeralKey
+}
+
+type RespondHandshakeResponse struct {
+	EphemeralKey string      `json:"ephemeralKey" format:"hex"` // EphemeralKey is the responder's 64 bytes ephemeral public key
+	Attestation  Attestation `json:"attestation"`               // Attestation is the responder's version attestation over challenge || ephemeralKey
+	SessionId    string      `json:"sessionId" format:"hex"`    // SessionId is the transcript hash
+	Signature    string      `json:"signature" format:"hex"`    // Signature is the responder's device signature over the session
+}
+
+type ConfirmHandshakeRequest struct {
+	SessionId string `json:"sessionId" format:"hex"`
+	Signature string `json:"signature" format:"hex"` // Signature is the initiator's device signature over the session
+}
+
+type ConfirmHandshakeResponse struct {
+	SessionId   string `json:"sessionId" format:"hex"`
+	Established bool   `json:"established"`
+}
+
+type HandshakeSession struct {
+	SessionId      string `json:"sessionId" format:"hex"`      // SessionId is the transcript hash
+	SessionKey     string `json:"sessionKey" format:"hex"`     // SessionKey is the 32 bytes key shared with the peer
+	Initiator      bool   `json:"initiator"`                   // Initiator is true if this device started the handshake
+	PeerDeviceKey  string `json:"peerDeviceKey" format:"hex"`  // PeerDeviceKey is the leaf of the peer's device cert chain
+	LocalSignature string `json:"localSignature" format:"hex"` // LocalSignature is this device's signature over "TEERMINAL_SESSION:" || sessionId || keccak256(sessionKey)
+	PeerSignature  string `json:"peerSignature" format:"hex"`  // PeerSignature is the peer's signature over the same payload, empty until the peer has signed
+	Established    bool   `json:"established"`
+}
+
+func NewHandshakeSession(s handshake.Session) HandshakeSession {
+	return HandshakeSession{
+		SessionId:      fmt.Sprintf("%x", s.Id),
+		SessionKey:     fmt.Sprintf("%x", s.Key),
+		Initiator:      s.Initiator,
+		PeerDeviceKey:  fmt.Sprintf("%x", s.PeerDeviceKey),
+		LocalSignature: fmt.Sprintf("%x", s.LocalSignature),
+		PeerSignature:  fmt.Sprintf("%x", s.PeerSignature),
+		Established:    s.Established(),
+	}
+}
+
+// trustedRoots returns the vendor roots accepted for peer devices
+func trustedRoots(override string) ([][]byte, error) {
+	if override != "" {
+		root, err := decodeRoot(override)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{root}, nil
+	}
+	configured := config.GetConfig().TrustedVendorRoots
+	if len(configured) == 0 {
+		return [][]byte{identity.Get().VendorRootPubKey}, nil
+	}
+	var roots [][]byte
+	for _, r := range configured {
+		root, err := decodeRoot(r)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, root)
+	}
+	return roots, nil
+}
+
+// verifyPeerAttestation checks the peer's attestation against the trusted roots, and returns the peer's device key
+func verifyPeerAttestation(nonce []byte, ephemeralKey []byte, attestation Attestation, roots [][]byte) ([]byte, error) {
+	// Only a device chain is accepted, an app chain of the peer would make its app key pass as the device key
+	chain, err := decodeHex(attestation.Cert)
+	if err != nil || len(chain) != constants.DeviceChainCerts*encryption.CertLength {
+		return nil, fmt.Errorf("%w: %s", constants.ErrorPeerAttestationInvalid, constants.MsgErrorInvalidChainLength)
+	}
+	// The chain must start from one of the trusted roots
+	prover := chain[:64]
+	for _, root := range roots {
+		if !bytes.Equal(root, prover) {
+			continue
+		}
+		resp, err := CheckAttestation(nonce, ephemeralKey, attestation, root)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", constants.ErrorPeerAttestationInvalid, err.Error())
+		}
+		if !resp.Valid {
+			return nil, fmt.Errorf("%w: %s", constants.ErrorPeerAttestationInvalid, resp.Error)
+		}
+		return decodeHex(resp.Chain.Leaf)
+	}
+	return nil, constants.ErrorUntrustedVendorRoot
+}
+
+// newPeerClient returns an http client for the peer's api, an https peer must serve RA-TLS attesting to one of the roots
+// The client has its own transport, close its idle connections once the handshake is over
+func newPeerClient(roots [][]byte) *http.Client {
+	return &http.Client{
+		Timeout:   constants.PeerRequestTimeoutSeconds * time.Second,
+		Transport: &http.Transport{TLSClientConfig: ratls.ClientConfig(roots, nil)},
+	}
+}
+
+// peerRequest calls the peer's api, posting body as json if it is not nil, and decodes the response into out
+func peerRequest(peerClient *http.Client, peer string, path string, body interface{}, out interface{}) error {
+	var resp *http.Response
+	var err error
+	if body == nil {
+		resp, err = peerClient.Get(peer + path)
+	} else {
+		raw, _ := json.Marshal(body)
+		resp, err = peerClient.Post(peer+path, "application/json", bytes.NewReader(raw))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrorPeerRequestFailed, err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		var e ErrorResponse
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("%w: %s", constants.ErrorPeerRequestFailed, e.Error)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: %s", constants.ErrorPeerRequestFailed, err.Error())
+	}
+	return nil
+}
+
+func decodeFixedHex(s string, length int) ([]byte, bool) {
+	decoded, err := decodeHex(s)
+	return decoded, err == nil && len(decoded) == length
+}
+
+// HandleInitiateHandshake godoc
+// @Summary Run a mutual attestation handshake with a peer device
+// @Description Attest to the peer and verify the peer's attestation against the trusted vendor roots, then agree on a session key signed by both devices, requires the admin token
+// @Tags handshake
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param Authorization header string true "Bearer admin token"
+// @Param request body InitiateHandshakeRequest true "Peer to handshake with"
+// @Success 200 {object} HandshakeSession
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /api/v1/handshake/initiate [post]
+func HandleInitiateHandshake(c *gin.Context) {
+	var req InitiateHandshakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	peer, err := url.Parse(req.Peer)
+	if err != nil || (peer.Scheme != "http" && peer.Scheme != "https") || peer.Host == "" {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidPeer})
+		c.Next()
+		return
+	}
+	peerUrl := strings.TrimSuffix(peer.String(), "/")
+	roots, err := trustedRoots(req.Root)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	peerClient := newPeerClient(roots)
+	defer peerClient.CloseIdleConnections()
+	// Get a nonce from the peer, and issue one for the peer
+	var peerChallenge Challenge
+	if err := peerRequest(peerClient, peerUrl, "/api/v1/device/challenge", nil, &peerChallenge); err != nil {
+		c.JSON(502, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	peerNonce, ok := decodeFixedHex(peerChallenge.Nonce, constants.ChallengeLength)
+	if !ok {
+		c.JSON(502, ErrorResponse{Error: constants.MsgErrorFailedDecodeNonce})
+		c.Next()
+		return
+	}
+	localNonce, _, err := challenge.Issue()
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	ephemeralKey, ephemeralPubKey, err := handshake.GenerateEphemeralKey()
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: constants.MsgErrorFailedGenerateEphemeralKey})
+		c.Next()
+		return
+	}
+	// Attest to the peer, and let the peer attest back
+	var resp RespondHandshakeResponse
+	if err := peerRequest(peerClient, peerUrl, "/api/v1/handshake/respond", RespondHandshakeRequest{
+		Nonce:        fmt.Sprintf("%x", peerNonce),
+		EphemeralKey: fmt.Sprintf("%x", ephemeralPubKey),
+		Challenge:    fmt.Sprintf("%x", localNonce),
+		Attestation:  SignVersionAttestation(peerNonce, ephemeralPubKey),
+	}, &resp); err != nil {
+		c.JSON(502, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	if !consumeChallenge(c, localNonce) {
+		return
+	}
+	peerEphemeralKey, ok := decodeFixedHex(resp.EphemeralKey, 64)
+	if !ok {
+		c.JSON(502, ErrorResponse{Error: constants.MsgErrorInvalidEphemeralKey})
+		c.Next()
+		return
+	}
+	peerDeviceKey, err := verifyPeerAttestation(localNonce, peerEphemeralKey, resp.Attestation, roots)
+	if err != nil {
+		c.JSON(502, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	id := identity.Get()
+	transcript := handshake.Transcript(peerNonce, localNonce, ephemeralPubKey, peerEphemeralKey, id.DevicePubKey, peerDeviceKey)
+	key, err := handshake.DeriveKey(ephemeralKey, peerEphemeralKey, transcript)
+	if err != nil {
+		c.JSON(502, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	// The peer must have derived the same transcript and key
+	peerSignature, err := decodeHex(resp.Signature)
+	if err != nil || resp.SessionId != fmt.Sprintf("%x", transcript) || !encryption.VerifySignature(peerDeviceKey, handshake.Signable(transcript, key), peerSignature) {
+		c.JSON(502, ErrorResponse{Error: constants.MsgErrorSessionSignatureMismatch})
+		c.Next()
+		return
+	}
+	signature, _ := encryption.Sign(id.DeviceKey, handshake.Signable(transcript, key))
+	var confirmed ConfirmHandshakeResponse
+	if err := peerRequest(peerClient, peerUrl, "/api/v1/handshake/confirm", ConfirmHandshakeRequest{
+		SessionId: resp.SessionId,
+		Signature: fmt.Sprintf("%x", signature),
+	}, &confirmed); err != nil {
+		c.JSON(502, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	session := &handshake.Session{
+		Id:             transcript,
+		Key:            key,
+		Initiator:      true,
+		PeerDeviceKey:  peerDeviceKey,
+		LocalSignature: signature,
+		PeerSignature:  peerSignature,
+		CreatedAt:      time.Now(),
+	}
+	handshake.Put(session)
+	respond(c, NewHandshakeSession(*session))
+}
+
+// HandleRespondHandshake godoc
+// @Summary Answer a handshake started by a peer device
+// @Description Called by the initiating device: verify its attestation over our nonce and its ephemeral key, then attest over its nonce and our ephemeral key and sign the session
+// @Tags handshake
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param request body RespondHandshakeRequest true "Initiator's attestation"
+// @Success 200 {object} RespondHandshakeResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/handshake/respond [post]
+func HandleRespondHandshake(c *gin.Context) {
+	var req RespondHandshakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	nonce, ok := decodeFixedHex(req.Nonce, constants.ChallengeLength)
+	if !ok {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedDecodeNonce})
+		c.Next()
+		return
+	}
+	peerNonce, ok := decodeFixedHex(req.Challenge, constants.ChallengeLength)
+	if !ok {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedDecodeNonce})
+		c.Next()
+		return
+	}
+	peerEphemeralKey, ok := decodeFixedHex(req.EphemeralKey, 64)
+	if !ok {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidEphemeralKey})
+		c.Next()
+		return
+	}
+	roots, err := trustedRoots("")
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	if !consumeChallenge(c, nonce) {
+		return
+	}
+	peerDeviceKey, err := verifyPeerAttestation(nonce, peerEphemeralKey, req.Attestation, roots)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	ephemeralKey, ephemeralPubKey, err := handshake.GenerateEphemeralKey()
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: constants.MsgErrorFailedGenerateEphemeralKey})
+		c.Next()
+		return
+	}
+	id := identity.Get()
+	transcript := handshake.Transcript(nonce, peerNonce, peerEphemeralKey, ephemeralPubKey, peerDeviceKey, id.DevicePubKey)
+	key, err := handshake.DeriveKey(ephemeralKey, peerEphemeralKey, transcript)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	signature, _ := encryption.Sign(id.DeviceKey, handshake.Signable(transcript, key))
+	// The session is pending until the initiator signs it
+	handshake.Put(&handshake.Session{
+		Id:             transcript,
+		Key:            key,
+		PeerDeviceKey:  peerDeviceKey,
+		LocalSignature: signature,
+		CreatedAt:      time.Now(),
+	})
+	respond(c, RespondHandshakeResponse{
+		EphemeralKey: fmt.Sprintf("%x", ephemeralPubKey),
+		Attestation:  SignVersionAttestation(peerNonce, ephemeralPubKey),
+		SessionId:    fmt.Sprintf("%x", transcript),
+		Signature:    fmt.Sprintf("%x", signature),
+	})
+}
+
+// HandleConfirmHandshake godoc
+// @Summary Complete a handshake started by a peer device
+// @Description Called by the initiating device: record its signature over the session, after which the session is established
+// @Tags handshake
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param request body ConfirmHandshakeRequest true "Initiator's session signature"
+// @Success 200 {object} ConfirmHandshakeResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/handshake/confirm [post]
+func HandleConfirmHandshake(c *gin.Context) {
+	var req ConfirmHandshakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	sessionId, err := decodeHex(req.SessionId)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorSessionDoesNotExist})
+		c.Next()
+		return
+	}
+	signature, err := decodeHex(req.Signature)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidSignatureLength})
+		c.Next()
+		return
+	}
+	session, err := handshake.Confirm(sessionId, signature)
+	if errors.Is(err, constants.ErrorSessionDoesNotExist) {
+		c.JSON(404, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	respond(c, ConfirmHandshakeResponse{
+		SessionId:   fmt.Sprintf("%x", session.Id),
+		Established: session.Established(),
+	})
+}
+
+// HandleGetSession godoc
+// @Summary Get a handshake session
+// @Description Get a session agreed with a peer device, including the session key, for the application running on this device, requires the admin token
+// @Tags handshake
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param Authorization header string true "Bearer admin token"
+// @Param id query string true "Session id"
+// @Success 200 {object} HandshakeSession
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/handshake/session [get]
+func HandleGetSession(c *gin.Context) {
+	sessionId, err := decodeHex(c.Query("id"))
+	if err != nil {
+		c.JSON(404, ErrorResponse{Error: constants.MsgErrorSessionDoesNotExist})
+		c.Next()
+		return
+	}
+	session, err := handshake.Get(sessionId)
+	if err != nil {
+		c.JSON(404, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	respond(c, NewHandshakeSession(session))
+}
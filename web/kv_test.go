@@ -0,0 +1,62 @@
+package web
+
+import (
+	"encoding/hex"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"testing"
+)
+
+func TestWriteKvProvision(t *testing.T) {
+	var appKey ApplicationKey
+	mustCall(t, "GET", "/api/v1/attestation/appkey", nil, &appKey)
+	appPubKey, _ := decodeHex(appKey.PubKey)
+	provisionerKey := make([]byte, 32)
+	provisionerKey[31] = 7
+	signature, err := encryption.Sign(provisionerKey, ProvisionSignable(appPubKey, "provisioned", "value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	provision := append(encryption.GetPublicKey(provisionerKey), signature...)
+
+	for _, tc := range []struct {
+		name      string
+		provision []byte
+		err       string
+	}{
+		{"truncated", provision[:constants.ProvisionLength-1], constants.MsgErrorInvalidProvisionLength},
+		{"extended", append(append([]byte{}, provision...), 0), constants.MsgErrorInvalidProvisionLength},
+		{"valid", provision, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := WriteKvRequest{Key: "provisioned", Value: "value", Provision: hex.EncodeToString(tc.provision)}
+			if tc.err == "" {
+				var resp WriteKvResponse
+				mustCall(t, "POST", "/api/v1/kv/write", req, &resp)
+				return
+			}
+			var resp ErrorResponse
+			if status := call(t, "POST", "/api/v1/kv/write", req, &resp); status != 400 || resp.Error != tc.err {
+				t.Fatalf("expected 400 %s, got %d %s", tc.err, status, resp.Error)
+			}
+		})
+	}
+}
+
+func TestDeleteKv(t *testing.T) {
+	mustCall(t, "POST", "/api/v1/kv/write", WriteKvRequest{Key: "deleted", Value: "value"}, &WriteKvResponse{})
+	var deleted DeleteKvResponse
+	mustCall(t, "POST", "/api/v1/kv/delete", DeleteKvRequest{Key: "deleted"}, &deleted)
+	if !deleted.Success {
+		t.Fatal("delete failed")
+	}
+	var resp ErrorResponse
+	if status := call(t, "POST", "/api/v1/kv/delete", DeleteKvRequest{Key: "deleted"}, &resp); status != 400 || resp.Error != constants.MsgErrorKeyDoesNotExist {
+		t.Fatalf("expected 400 %s, got %d %s", constants.MsgErrorKeyDoesNotExist, status, resp.Error)
+	}
+
+	// A body that does not bind is answered once, call fails on a second response appended to the body
+	if status := call(t, "POST", "/api/v1/kv/delete", "deleted", &resp); status != 400 {
+		t.Fatalf("expected 400, got %d", status)
+	}
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"fmt"
+	"teerminal/constants"
+	"teerminal/service/measurement"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /api/v1/measurement
+
+func RegisterMeasurementRoutes(router *gin.Engine) {
+	measurementGroup := router.Group("/api/v1/measurement")
+	{
+		// Extending changes what the device attests, so it is for the application on this device only
+		measurementGroup.POST("/extend", HandleAdminAuth, HandleExtendMeasurement)
+		measurementGroup.GET("/registers", HandleGetMeasurements)
+	}
+}
+
+type ExtendMeasurementRequest struct {
+	Register    int    `json:"register"`              // Register is the index of the register to extend
+	Data        string `json:"data" format:"hex"`     // Data is the hex encoded data to extend with
+	Description string `json:"description,omitempty"` // Description is recorded in the event log
+}
+
+type ExtendMeasurementResponse struct {
+	Register int    `json:"register"`
+	Value    string `json:"value" format:"hex"` // Value is the new register value, keccak256(old || data)
+}
+
+type Measurements struct {
+	Registers []string            `json:"registers" format:"hex"` // Registers are the current register values
+	EventLog  []measurement.Event `json:"eventLog"`               // EventLog replays to the register values, starting from zero registers
+}
+
+// currentMeasurements returns the register values along with their response representation
+func currentMeasurements() ([][]byte, Measurements) {
+	values, events := measurement.Snapshot()
+	resp := Measurements{EventLog: events}
+	for _, value := range values {
+		resp.Registers = append(resp.Registers, fmt.Sprintf("%x", value))
+	}
+	return values, resp
+}
+
+// HandleExtendMeasurement godoc
+// @Summary Extend a measurement register
+// @Description Extend a measurement register with data: new = keccak256(old || data), registers can never be reset except by a config reload, the event log holds at most 1024 events, requires the admin token
+// @Tags measurement
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param Authorization header string true "Bearer admin token"
+// @Param request body ExtendMeasurementRequest true "Measurement to extend"
+// @Success 200 {object} ExtendMeasurementResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/measurement/extend [post]
+func HandleExtendMeasurement(c *gin.Context) {
+	var req ExtendMeasurementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	data, err := decodeHex(req.Data)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedDecodeMessage})
+		c.Next()
+		return
+	}
+	if len(data) > constants.MaxMeasurementLength {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorMeasurementTooLarge})
+		c.Next()
+		return
+	}
+	value, err := measurement.Extend(req.Register, data, req.Description)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	respond(c, ExtendMeasurementResponse{Register: req.Register, Value: fmt.Sprintf("%x", value)})
+}
+
+// HandleGetMeasurements godoc
+// @Summary Get measurement registers and event log
+// @Description Get the current measurement register values and the event log which replays to them
+// @Tags measurement
+// @Produce application/json,application/cbor
+// @Success 200 {object} Measurements
+// @Router /api/v1/measurement/registers [get]
+func HandleGetMeasurements(c *gin.Context) {
+	_, resp := currentMeasurements()
+	respond(c, resp)
+}
@@ -0,0 +1,31 @@
+package web
+
+import (
+	"teerminal/constants"
+	"testing"
+)
+
+func TestExtendMeasurementAuth(t *testing.T) {
+	req := ExtendMeasurementRequest{Register: 3, Data: "00ff", Description: "test"}
+	var before Measurements
+	mustCall(t, "GET", "/api/v1/measurement/registers", nil, &before)
+
+	for _, token := range []string{"", "wrong"} {
+		var resp ErrorResponse
+		if status := callAs(t, token, "POST", "/api/v1/measurement/extend", req, &resp); status != 401 || resp.Error != constants.MsgErrorUnauthorized {
+			t.Fatalf("token %q: expected 401 %s, got %d %s", token, constants.MsgErrorUnauthorized, status, resp.Error)
+		}
+	}
+	var after Measurements
+	mustCall(t, "GET", "/api/v1/measurement/registers", nil, &after)
+	if len(after.EventLog) != len(before.EventLog) || after.Registers[3] != before.Registers[3] {
+		t.Fatal("unauthorized extension changed the registers")
+	}
+
+	var extended ExtendMeasurementResponse
+	mustCall(t, "POST", "/api/v1/measurement/extend", req, &extended)
+	mustCall(t, "GET", "/api/v1/measurement/registers", nil, &after)
+	if after.Registers[3] != extended.Value || len(after.EventLog) != len(before.EventLog)+1 {
+		t.Fatal("extension is not in the registers")
+	}
+}
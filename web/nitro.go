@@ -0,0 +1,74 @@
+package web
+
+import (
+	"encoding/pem"
+	"fmt"
+	"teerminal/constants"
+	"teerminal/service/nitro"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NitroDocument struct {
+	Document string `json:"document" format:"hex"` // Document is the COSE_Sign1 CBOR attestation document
+	RootCert string `json:"rootCert"`              // RootCert is the mock root CA in PEM, the first cert of the cabundle
+	Measurements
+}
+
+// decodeNitroField decodes an optional hex query parameter, returns nil when it is absent
+func decodeNitroField(c *gin.Context, name string, msg string) ([]byte, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	decoded, err := decodeHex(value)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: msg})
+		c.Next()
+		return nil, false
+	}
+	if len(decoded) > constants.MaxNitroFieldLength {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorAttestationFieldTooLarge})
+		c.Next()
+		return nil, false
+	}
+	return decoded, true
+}
+
+// HandleGetNitroDocument serves /api/v1/device/version?format=nitro
+func HandleGetNitroDocument(c *gin.Context) {
+	nonce, ok := decodeNitroField(c, "nonce", constants.MsgErrorFailedDecodeNonce)
+	if !ok {
+		return
+	}
+	userData, ok := decodeNitroField(c, "userData", constants.MsgErrorFailedDecodeUserData)
+	if !ok {
+		return
+	}
+	publicKey, ok := decodeNitroField(c, "publicKey", constants.MsgErrorFailedDecodePublicKey)
+	if !ok {
+		return
+	}
+	// The nonce is consumed only once the whole request is valid, so a malformed field does not burn it
+	if nonce != nil && !consumeChallenge(c, nonce) {
+		return
+	}
+	registers, measurements := currentMeasurements()
+	document, err := nitro.BuildDocument(nonce, userData, publicKey, registers)
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: constants.MsgErrorFailedGenerateDocument})
+		c.Next()
+		return
+	}
+	certs, err := nitro.GetCerts()
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: constants.MsgErrorFailedGenerateDocument})
+		c.Next()
+		return
+	}
+	respond(c, NitroDocument{
+		Document:     fmt.Sprintf("%x", document),
+		RootCert:     string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certs.Cabundle[0]})),
+		Measurements: measurements,
+	})
+}
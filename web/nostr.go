@@ -0,0 +1,40 @@
+package web
+
+import (
+	"teerminal/constants"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NostrEventRequest struct {
+	Kind    int        `json:"kind"`    // Kind is the NIP-01 event kind, 0 to 65535
+	Tags    [][]string `json:"tags"`    // Tags are the event tags, the chain tag is appended by the device
+	Content string     `json:"content"` // Content is the event content
+}
+
+// HandleNostrWithAppDerivedKey godoc
+// @Summary Sign a nostr event with app derived key for current (simulated) tee version
+// @Description Build a NIP-01 event of kind, tags and content, whose pubkey is the x-only app public key, compute its id and sign it with BIP-340
+// @Description The app cert chain is appended as a ["teerminal_chain", <appCert>] tag, so relays and clients can verify the pubkey against the vendor root
+// @Tags attestation
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param event body NostrEventRequest true "Event to sign"
+// @Success 200 {object} nostr.Event
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/attestation/nostr [post]
+func HandleNostrWithAppDerivedKey(c *gin.Context) {
+	var req NostrEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	signed, err := SignNostrEvent(req)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	respond(c, signed)
+}
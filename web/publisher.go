@@ -0,0 +1,55 @@
+package web
+
+import (
+	"strconv"
+	"teerminal/constants"
+	"teerminal/service/publisher"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /api/v1/publisher
+
+func RegisterPublisherRoutes(router *gin.Engine) {
+	pub := router.Group("/api/v1/publisher")
+	{
+		pub.GET("/relays", HandleGetRelays)
+		pub.GET("/commands", HandleGetCommands)
+	}
+}
+
+// HandleGetRelays godoc
+// @Summary Get the nostr relays the device publishes to
+// @Description Get the connection state of every configured relay, status (kind 30078) and telemetry (kind 1573) events signed by the app key are published to the connected relays every publishInterval seconds
+// @Tags publisher
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Success 200 {array} publisher.RelayStatus
+// @Router /api/v1/publisher/relays [get]
+func HandleGetRelays(c *gin.Context) {
+	respond(c, publisher.Relays())
+}
+
+// HandleGetCommands godoc
+// @Summary Get the commands received from the nostr relays
+// @Description Get the verified command events (kind 1574) addressed to the device by a p tag of its x-only app public key
+// @Tags publisher
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param since query int false "Only return commands with an id greater than since"
+// @Success 200 {array} publisher.Command
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/publisher/commands [get]
+func HandleGetCommands(c *gin.Context) {
+	since := uint64(0)
+	if s := c.Query("since"); s != "" {
+		parsed, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidCommandId})
+			c.Next()
+			return
+		}
+		since = parsed
+	}
+	respond(c, publisher.Commands(since))
+}
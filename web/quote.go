@@ -0,0 +1,91 @@
+package web
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"teerminal/constants"
+	"teerminal/service/identity"
+	"teerminal/service/measurement"
+	"teerminal/service/sgx"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DcapQuote struct {
+	Quote        string `json:"quote" format:"hex"`      // Quote is the emulated SGX ECDSA quote
+	QuoteVersion uint16 `json:"quoteVersion"`            // QuoteVersion is 3 or 4
+	ReportData   string `json:"reportData" format:"hex"` // ReportData is sha256(nonce) || sha256(app public key)
+	MrEnclave    string `json:"mrEnclave" format:"hex"`  // MrEnclave is sha256("MRENCLAVE" || AppName)
+	MrSigner     string `json:"mrSigner" format:"hex"`   // MrSigner is sha256("MRSIGNER" || vendor root public key)
+	PckCertChain string `json:"pckCertChain"`            // PckCertChain is the mock PCK cert chain in PEM, the last cert is the mock root CA
+	ConfigId     string `json:"configId" format:"hex"`   // ConfigId is keccak256(registers) zero padded to 64 bytes
+	Measurements
+}
+
+// QuoteReportData binds the requester's nonce and the app key into the 64 bytes report data
+func QuoteReportData(nonce []byte, appPubKey []byte) (reportData [64]byte) {
+	nonceHash := sha256.Sum256(nonce)
+	appHash := sha256.Sum256(appPubKey)
+	copy(reportData[:32], nonceHash[:])
+	copy(reportData[32:], appHash[:])
+	return
+}
+
+// HandleGetDcapQuote serves /api/v1/device/version?format=dcap
+func HandleGetDcapQuote(c *gin.Context) {
+	nonceHex := c.Query("nonce")
+	if nonceHex == "" {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorMissingNonce})
+		c.Next()
+		return
+	}
+	nonce, err := decodeHex(nonceHex)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedDecodeNonce})
+		c.Next()
+		return
+	}
+	version := uint16(sgx.QuoteVersion3)
+	if v := c.Query("quoteVersion"); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 16)
+		if err != nil || (parsed != sgx.QuoteVersion3 && parsed != sgx.QuoteVersion4) {
+			c.JSON(400, ErrorResponse{Error: constants.MsgErrorUnsupportedQuoteVersion})
+			c.Next()
+			return
+		}
+		version = uint16(parsed)
+	}
+	if !consumeChallenge(c, nonce) {
+		return
+	}
+	reportData := QuoteReportData(nonce, identity.Get().AppPubKey)
+	// The measurement registers are bound through the config id
+	registers, measurements := currentMeasurements()
+	var configId [64]byte
+	copy(configId[:], measurement.Digest(registers))
+	quote, err := sgx.BuildQuote(version, reportData, configId)
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: constants.MsgErrorFailedGenerateQuote})
+		c.Next()
+		return
+	}
+	pck, err := sgx.GetPckChain()
+	if err != nil {
+		c.JSON(500, ErrorResponse{Error: constants.MsgErrorFailedGenerateQuote})
+		c.Next()
+		return
+	}
+	mrEnclave := sgx.MrEnclave()
+	mrSigner := sgx.MrSigner()
+	respond(c, DcapQuote{
+		Quote:        fmt.Sprintf("%x", quote),
+		QuoteVersion: version,
+		ReportData:   fmt.Sprintf("%x", reportData),
+		MrEnclave:    fmt.Sprintf("%x", mrEnclave),
+		MrSigner:     fmt.Sprintf("%x", mrSigner),
+		PckCertChain: string(pck.Pem),
+		ConfigId:     fmt.Sprintf("%x", configId),
+		Measurements: measurements,
+	})
+}
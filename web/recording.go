@@ -0,0 +1,90 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/recording"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	recorder *recording.Recorder
+	replayer *recording.Replayer
+)
+
+// SetupRecording opens the recording or replay file from the config, replay takes precedence if both are set
+func SetupRecording() error {
+	cfg := config.GetConfig()
+	var err error
+	switch {
+	case cfg.Replay != "":
+		replayer, err = recording.NewReplayer(cfg.Replay, cfg.ReplayByRoute)
+	case cfg.Record != "":
+		recorder, err = recording.NewRecorder(cfg.Record)
+	}
+	return err
+}
+
+// HandleRecordReplay is the middleware recording the /api/v1 responses, or serving them from the recording in replay mode
+func HandleRecordReplay(c *gin.Context) {
+	if (recorder == nil && replayer == nil) || !strings.HasPrefix(c.Request.URL.Path, "/api/v1/") || strings.HasPrefix(c.Request.URL.Path, "/api/v1/admin") || isStreaming(c.Request.URL.Path) {
+		c.Next()
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, constants.MaxRequestBodyLength))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.AbortWithStatusJSON(413, ErrorResponse{Error: constants.MsgErrorRequestTooLarge})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	uri := c.Request.URL.RequestURI()
+	if replayer != nil {
+		entry, ok := replayer.Lookup(c.Request.Method, uri, string(body))
+		if !ok {
+			c.AbortWithStatusJSON(404, ErrorResponse{Error: constants.MsgErrorNoRecordedResponse})
+			return
+		}
+		if t, ok := responseTypes[entry.Type]; ok {
+			c.Set(cborTypeKey, t)
+		}
+		c.Data(entry.Status, entry.ContentType, []byte(entry.Response))
+		c.Abort()
+		return
+	}
+	original := c.Writer
+	writer := &bufferedWriter{ResponseWriter: original}
+	c.Writer = writer
+	c.Next()
+	c.Writer = original
+	original.Write(writer.body.Bytes())
+	var responseType string
+	if t, ok := c.Get(cborTypeKey); ok {
+		responseType = t.(reflect.Type).String()
+	}
+	if err := recorder.Record(recording.Entry{
+		Time:        time.Now(),
+		Method:      c.Request.Method,
+		Uri:         uri,
+		Body:        string(body),
+		Status:      original.Status(),
+		ContentType: original.Header().Get("Content-Type"),
+		Response:    writer.body.String(),
+		Type:        responseType,
+	}); err != nil {
+		log.Printf("failed to record %s %s: %v", c.Request.Method, uri, err)
+	}
+}
@@ -3,7 +3,20 @@ package web
 import "github.com/gin-gonic/gin"
 
 func RegisterRoutes(e *gin.Engine) {
+	// Middlewares apply to the routes registered after them, recording sees the responses after fault injection,
+	// and both see JSON since CBOR is translated around them
+	e.Use(HandleCbor)
+	e.Use(HandleRecordReplay)
+	e.Use(HandleFaultInjection)
 	RegisterDeviceRoutes(e)
 	RegisterAttestationRoutes(e)
 	RegisterKvRoutes(e)
+	RegisterVerifyRoutes(e)
+	RegisterCounterRoutes(e)
+	RegisterMeasurementRoutes(e)
+	RegisterHandshakeRoutes(e)
+	RegisterAdminRoutes(e)
+	RegisterPublisherRoutes(e)
+	RegisterSensorRoutes(e)
+	RegisterRpcRoutes(e)
 }
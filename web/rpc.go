@@ -0,0 +1,249 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/kv"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+const rpcPath = "/api/v1/ws"
+
+const (
+	RpcMethodDeviceKey     = "device.key"
+	RpcMethodDeviceVersion = "device.version"
+	RpcMethodAppKey        = "attestation.appkey"
+	RpcMethodSign          = "attestation.sign"
+	RpcMethodWriteKv       = "kv.write"
+	RpcMethodReadKv        = "kv.read"
+	RpcMethodDeleteKv      = "kv.delete"
+	RpcMethodQuota         = "kv.quota"
+	RpcNotificationKv      = "kv.changed"
+	RpcNotificationConfig  = "config.changed"
+	rpcVersion             = "2.0"
+)
+
+type RpcRequest struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id" swaggertype:"integer"` // Id is echoed in the response, any JSON value
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty" swaggertype:"object"` // Params is the request body of the REST endpoint, or its query parameters as an object
+}
+
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"` // Message is the error of the REST endpoint for application errors
+}
+
+type RpcResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id" swaggertype:"integer"`
+	Result  interface{}     `json:"result,omitempty"` // Result is the response body of the REST endpoint
+	Error   *RpcError       `json:"error,omitempty"`
+}
+
+// RpcNotification is pushed without request, Params is a kv.Change for kv.changed, and a ConfigChange for config.changed
+type RpcNotification struct {
+	JsonRpc string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type ConfigChange struct {
+	Generation     uint64 `json:"generation"` // Generation is bumped on every (re)load of the config
+	AttestationVer string `json:"attestationVer"`
+	TeePlatformVer uint32 `json:"teePlatformVer"`
+}
+
+type KeyRequest struct {
+	Formats string `json:"formats"` // Formats are the additional public key formats, see /api/v1/device/key
+}
+
+type VersionRequest struct {
+	Attestation string `json:"attestation" format:"hex"` // Attestation is the requester's nonce and signature, see /api/v1/device/version
+}
+
+type ReadKvRequest struct {
+	Key string `json:"key"`
+}
+
+// rpcMethods decode the params and call the operations shared with the REST handlers
+var rpcMethods = map[string]func(params json.RawMessage) (interface{}, error){
+	RpcMethodDeviceKey: func(params json.RawMessage) (interface{}, error) {
+		var req KeyRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return GetDeviceKey(req.Formats)
+	},
+	RpcMethodDeviceVersion: func(params json.RawMessage) (interface{}, error) {
+		var req VersionRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return GetVersionAttestation(req.Attestation)
+	},
+	RpcMethodAppKey: func(params json.RawMessage) (interface{}, error) {
+		var req KeyRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return GetAppKey(req.Formats)
+	},
+	RpcMethodSign: func(params json.RawMessage) (interface{}, error) {
+		var req SignRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return SignWithAppKey(req)
+	},
+	RpcMethodWriteKv: func(params json.RawMessage) (interface{}, error) {
+		var req WriteKvRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return WriteKv(req)
+	},
+	RpcMethodReadKv: func(params json.RawMessage) (interface{}, error) {
+		var req ReadKvRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return ReadKv(req.Key)
+	},
+	RpcMethodDeleteKv: func(params json.RawMessage) (interface{}, error) {
+		var req DeleteKvRequest
+		if err := decodeParams(params, &req); err != nil {
+			return nil, err
+		}
+		return DeleteKv(req)
+	},
+	RpcMethodQuota: func(params json.RawMessage) (interface{}, error) {
+		return GetQuota(), nil
+	},
+}
+
+// decodeParams decodes the params object, absent params leave the request empty
+func decodeParams(params json.RawMessage, req interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if json.Unmarshal(params, req) != nil {
+		return constants.ErrorInvalidRpcParams
+	}
+	return nil
+}
+
+func RegisterRpcRoutes(router *gin.Engine) {
+	router.GET(rpcPath, HandleRpc)
+}
+
+// HandleRpc godoc
+// @Summary WebSocket API mirroring the device, attestation and kv endpoints
+// @Description Upgrades to a WebSocket speaking JSON-RPC 2.0 in text frames, one request {"jsonrpc": "2.0", "id": 1, "method": "kv.read", "params": {"key": "k"}} per frame, answered by {"jsonrpc": "2.0", "id": 1, "result": {...}} or {"jsonrpc": "2.0", "id": 1, "error": {"code": -32000, "message": "..."}}
+// @Description Methods and their params: device.key {formats}, device.version {attestation}, attestation.appkey {formats}, attestation.sign (web.SignRequest), kv.write (web.WriteKvRequest), kv.read {key}, kv.delete (web.DeleteKvRequest) and kv.quota, results are the responses of the REST endpoints
+// @Description Requests without id are notifications: they are run but not answered
+// @Description Browsers may only connect from the device's own host or the rpcOrigins of the config, connections without Origin are accepted
+// @Description Notifications are pushed without id: kv.changed (kv.Change) after every kv write or delete, and config.changed (web.ConfigChange) after the config is reloaded, the connection is closed if it falls behind
+// @Tags rpc
+// @Success 101 {object} RpcResponse
+// @Failure 403 {string} string "Origin not allowed"
+// @Router /api/v1/ws [get]
+func HandleRpc(c *gin.Context) {
+	websocket.Server{Handshake: checkRpcOrigin, Handler: serveRpc}.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkRpcOrigin refuses cross-site browser connections: gateways send no Origin and are accepted,
+// browsers must come from the device's own host or one of the configured rpc origins
+func checkRpcOrigin(cfg *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	parsed, err := websocket.Origin(cfg, req)
+	if err != nil || parsed == nil {
+		return constants.ErrorForbiddenRpcOrigin
+	}
+	if parsed.Host == req.Host || slices.Contains(config.GetConfig().RpcOrigins, origin) {
+		return nil
+	}
+	return constants.ErrorForbiddenRpcOrigin
+}
+
+func serveRpc(conn *websocket.Conn) {
+	var mu sync.Mutex
+	send := func(v interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		websocket.JSON.Send(conn, v)
+	}
+	changes, cancelKv := kv.Subscribe()
+	defer cancelKv()
+	watch, cancelConfig := config.Watch()
+	defer cancelConfig()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case change, ok := <-changes:
+				if !ok {
+					// The connection fell behind, close it instead of skipping changes
+					conn.Close()
+					return
+				}
+				send(RpcNotification{JsonRpc: rpcVersion, Method: RpcNotificationKv, Params: change})
+			case <-watch:
+				send(RpcNotification{JsonRpc: rpcVersion, Method: RpcNotificationConfig, Params: GetConfigChange()})
+			}
+		}
+	}()
+	for {
+		var frame []byte
+		if err := websocket.Message.Receive(conn, &frame); err != nil {
+			return
+		}
+		if resp, ok := handleRpcRequest(frame); ok {
+			send(resp)
+		}
+	}
+}
+
+// handleRpcRequest runs the request, and returns false for notifications, which are requests without id and get no response
+func handleRpcRequest(frame []byte) (RpcResponse, bool) {
+	resp := RpcResponse{JsonRpc: rpcVersion, Id: json.RawMessage("null")}
+	var req RpcRequest
+	if err := json.Unmarshal(frame, &req); err != nil {
+		resp.Error = &RpcError{Code: constants.RpcErrorParse, Message: err.Error()}
+		return resp, true
+	}
+	// Invalid requests are answered with a null id, even without id
+	if req.Method == "" {
+		resp.Error = &RpcError{Code: constants.RpcErrorInvalidRequest, Message: constants.MsgErrorInvalidRpcRequest}
+		return resp, true
+	}
+	notification := len(req.Id) == 0
+	resp.Id = req.Id
+	method, ok := rpcMethods[req.Method]
+	if !ok {
+		resp.Error = &RpcError{Code: constants.RpcErrorUnknownMethod, Message: constants.MsgErrorUnknownRpcMethod}
+		return resp, !notification
+	}
+	result, err := method(req.Params)
+	if err == constants.ErrorInvalidRpcParams {
+		resp.Error = &RpcError{Code: constants.RpcErrorInvalidParams, Message: err.Error()}
+	} else if err != nil {
+		resp.Error = &RpcError{Code: constants.RpcErrorApplication, Message: err.Error()}
+	} else {
+		resp.Result = result
+	}
+	return resp, !notification
+}
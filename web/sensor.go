@@ -0,0 +1,127 @@
+package web
+
+import (
+	"io"
+	"strconv"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/sensor"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /api/v1/sensor
+
+const sensorStreamPath = "/api/v1/sensor/stream"
+
+func RegisterSensorRoutes(router *gin.Engine) {
+	sensorGroup := router.Group("/api/v1/sensor")
+	{
+		sensorGroup.GET("/list", HandleGetSensors)
+		sensorGroup.GET("/readings", HandleGetReadings)
+		sensorGroup.GET("/stream", HandleStreamReadings)
+	}
+}
+
+// isStreaming reports whether the route streams its response, which the middlewares must not buffer
+func isStreaming(path string) bool {
+	return path == sensorStreamPath || path == rpcPath
+}
+
+// parseSequence parses the sequence readings are returned after, 0 if empty
+func parseSequence(s string) (uint64, bool) {
+	if s == "" {
+		return 0, true
+	}
+	parsed, err := strconv.ParseUint(s, 10, 64)
+	return parsed, err == nil
+}
+
+// HandleGetSensors godoc
+// @Summary Get the simulated sensors
+// @Description Get the running simulated sensors and their models
+// @Tags sensor
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Success 200 {array} config.Sensor
+// @Router /api/v1/sensor/list [get]
+func HandleGetSensors(c *gin.Context) {
+	list := sensor.Sensors()
+	if list == nil {
+		list = []config.Sensor{}
+	}
+	respond(c, list)
+}
+
+// HandleGetReadings godoc
+// @Summary Get the readings of the simulated sensors
+// @Description Get the stored readings in sequence order, every reading is signed by the app key over
+// @Description "TEERMINAL_READING:" || sequence || timestamp || keccak256(sensor) || keccak256(keccak256(name) || value for every value sorted by name)
+// @Tags sensor
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param since query int false "Only return readings with a sequence greater than since"
+// @Param sensor query string false "Only return readings of the sensor"
+// @Success 200 {array} sensor.Reading
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/sensor/readings [get]
+func HandleGetReadings(c *gin.Context) {
+	since, ok := parseSequence(c.Query("since"))
+	if !ok {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidSequence})
+		c.Next()
+		return
+	}
+	respond(c, sensor.Readings(since, c.Query("sensor")))
+}
+
+// HandleStreamReadings godoc
+// @Summary Stream the readings of the simulated sensors
+// @Description Stream the readings as server-sent events named reading, whose id is the sequence, starting with the stored readings after since or Last-Event-ID
+// @Description The stream is closed if the client falls behind, reconnect with Last-Event-ID to resume without gaps
+// @Tags sensor
+// @Produce text/event-stream
+// @Param since query int false "Only stream readings with a sequence greater than since"
+// @Param sensor query string false "Only stream readings of the sensor"
+// @Param Last-Event-ID header int false "Sequence of the last received reading, takes precedence over since"
+// @Success 200 {object} sensor.Reading
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/sensor/stream [get]
+func HandleStreamReadings(c *gin.Context) {
+	raw := c.Query("since")
+	if lastEventId := c.GetHeader("Last-Event-ID"); lastEventId != "" {
+		raw = lastEventId
+	}
+	since, ok := parseSequence(raw)
+	if !ok {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidSequence})
+		c.Next()
+		return
+	}
+	name := c.Query("sensor")
+	backlog, ch, cancel := sensor.Subscribe(since)
+	defer cancel()
+	send := func(r sensor.Reading) {
+		if name == "" || r.Sensor == name {
+			c.Render(-1, sse.Event{Id: strconv.FormatUint(r.Sequence, 10), Event: "reading", Data: r})
+		}
+	}
+	c.Header("Cache-Control", "no-cache")
+	for _, r := range backlog {
+		send(r)
+	}
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case r, ok := <-ch:
+			if !ok {
+				return false
+			}
+			send(r)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package web
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"teerminal/config"
+	"teerminal/constants"
+	"teerminal/service/challenge"
+	"teerminal/service/encryption"
+	"teerminal/service/enrollment"
+	"teerminal/service/identity"
+	"teerminal/service/kv"
+	"teerminal/service/nostr"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Operations shared by the REST handlers, the WebSocket API and the gRPC services, so the transports behave the same
+
+// GetDeviceKey returns the device public key and cert chain, with the requested additional formats
+func GetDeviceKey(formats string) (DeviceKey, error) {
+	id := identity.Get()
+	keyFormats, err := NewKeyFormats(id.DevicePubKey, formats)
+	if err != nil {
+		return DeviceKey{}, err
+	}
+	return DeviceKey{
+		Cert:       fmt.Sprintf("%x", id.DeviceChain),
+		PubKey:     fmt.Sprintf("%x", id.DevicePubKey),
+		KeyFormats: keyFormats,
+	}, nil
+}
+
+// GetVersionAttestation returns the native version attestation, signed for the requester if attestation is not empty,
+// see HandleGetVersionAttestation for its encoding
+func GetVersionAttestation(attestation string) (Attestation, error) {
+	if attestation == "" {
+		return Attestation{AttestationVer: config.GetConfig().Version, TeePlatformVer: config.GetConfig().TeePlatformVersion}, nil
+	}
+	// Decode Attestation to bytes
+	attestationRaw, err := hex.DecodeString(strings.TrimPrefix(attestation, "0x"))
+	if err != nil {
+		return Attestation{}, constants.ErrorFailedHexDecodeRemoteAttestation
+	}
+	// Check attestationRaw length
+	if len(attestationRaw) != constants.AttestationQueryLength {
+		return Attestation{}, constants.ErrorWrongRemoteAttestationLength
+	}
+	// Parse nonce, pubKey and signature
+	nonce := attestationRaw[:64]
+	pubKey := attestationRaw[64:128]
+	signature := attestationRaw[128:]
+	// First do the verification of the signature
+	if !encryption.VerifySignature(pubKey, attestationRaw[:128], signature) {
+		return Attestation{}, constants.ErrorWrongRemoteAttestationSignature
+	}
+	// The nonce must be issued by /api/v1/device/challenge, and is consumed only after the request is authenticated
+	if err := challenge.Consume(nonce); err != nil {
+		return Attestation{}, err
+	}
+	return SignVersionAttestation(nonce, pubKey), nil
+}
+
+// IssueChallenge issues a fresh nonce for version attestation
+func IssueChallenge() (Challenge, error) {
+	nonce, expiresAt, err := challenge.Issue()
+	if errors.Is(err, constants.ErrorTooManyChallenges) {
+		return Challenge{}, err
+	}
+	if err != nil {
+		return Challenge{}, constants.ErrorFailedIssueChallenge
+	}
+	return Challenge{
+		Nonce:     fmt.Sprintf("%x", nonce),
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+// SignEnrollment signs the hex encoded data with the device root key, see enrollment.Signable
+func SignEnrollment(req SignRequest) (Enrollment, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(req.Data, "0x"))
+	if err != nil {
+		return Enrollment{}, constants.ErrorFailedDecodeMessage
+	}
+	id := identity.Get()
+	signature, _ := encryption.Sign(id.DeviceKey, enrollment.Signable(data))
+	return Enrollment{
+		DeviceKey: fmt.Sprintf("%x", id.DevicePubKey),
+		Payload:   req.Data,
+		Signature: fmt.Sprintf("%x", signature),
+	}, nil
+}
+
+// Enroll signs a structured enrollment with the device root key, see enrollment.StructuredSignable
+func Enroll(req EnrollRequest) (StructuredEnrollment, error) {
+	if !common.IsHexAddress(req.Registry) || !common.IsHexAddress(req.Owner) {
+		return StructuredEnrollment{}, constants.ErrorInvalidAddress
+	}
+	fields := enrollment.Fields{
+		ChainId:  req.ChainId,
+		Registry: common.HexToAddress(req.Registry),
+		Owner:    common.HexToAddress(req.Owner),
+		Deadline: req.Deadline,
+	}
+	if err := fields.Validate(time.Now()); err != nil {
+		return StructuredEnrollment{}, err
+	}
+	signable, err := enrollment.StructuredSignable(req.Encoding, fields)
+	if err != nil {
+		return StructuredEnrollment{}, err
+	}
+	encoding := req.Encoding
+	if encoding == "" {
+		encoding = enrollment.EncodingCanonical
+	}
+	id := identity.Get()
+	signature, _ := encryption.Sign(id.DeviceKey, signable)
+	return StructuredEnrollment{
+		DeviceKey: fmt.Sprintf("%x", id.DevicePubKey),
+		Encoding:  encoding,
+		ChainId:   fields.ChainId,
+		Registry:  fields.Registry.Hex(),
+		Owner:     fields.Owner.Hex(),
+		Deadline:  fields.Deadline,
+		Payload:   fmt.Sprintf("%x", fields.Canonical()),
+		Signable:  fmt.Sprintf("%x", signable),
+		Digest:    fmt.Sprintf("%x", crypto.Keccak256(signable)),
+		Signature: fmt.Sprintf("%x", signature),
+	}, nil
+}
+
+// GetAppKey returns the app public key and cert chain, with the requested additional formats
+func GetAppKey(formats string) (ApplicationKey, error) {
+	// Application Key and Cert Chain are derived once per config generation
+	id := identity.Get()
+	keyFormats, err := NewKeyFormats(id.AppPubKey, formats)
+	if err != nil {
+		return ApplicationKey{}, err
+	}
+	return ApplicationKey{
+		Cert:       fmt.Sprintf("%x", id.AppChain),
+		PubKey:     fmt.Sprintf("%x", id.AppPubKey),
+		KeyFormats: keyFormats,
+	}, nil
+}
+
+// SignWithAppKey signs the hex encoded data with the app key
+func SignWithAppKey(req SignRequest) (SignResponse, error) {
+	id := identity.Get()
+	data, err := hex.DecodeString(strings.TrimPrefix(req.Data, "0x"))
+	if err != nil {
+		return SignResponse{}, constants.ErrorFailedDecodeMessage
+	}
+	signature, _ := encryption.Sign(id.AppKey, data)
+	return SignResponse{
+		PubKey:    fmt.Sprintf("%x", id.AppPubKey),
+		Signature: fmt.Sprintf("%x", signature),
+	}, nil
+}
+
+// VrfWithAppKey evaluates the VRF of the app key on the hex encoded alpha
+func VrfWithAppKey(req VrfRequest) (VrfResponse, error) {
+	alpha, err := hex.DecodeString(strings.TrimPrefix(req.Alpha, "0x"))
+	if err != nil {
+		return VrfResponse{}, constants.ErrorFailedDecodeMessage
+	}
+	id := identity.Get()
+	output, proof, err := encryption.VrfProve(id.AppKey, alpha)
+	if err != nil {
+		return VrfResponse{}, constants.ErrorFailedVrfProve
+	}
+	return VrfResponse{
+		Alpha:  fmt.Sprintf("%x", alpha),
+		Output: fmt.Sprintf("%x", output),
+		Proof:  fmt.Sprintf("%x", proof),
+		PubKey: fmt.Sprintf("%x", id.AppPubKey),
+		Cert:   fmt.Sprintf("%x", id.AppChain),
+	}, nil
+}
+
+// SignNostrEvent signs the nostr event with the app key, after appending the app cert chain tag
+func SignNostrEvent(req NostrEventRequest) (nostr.Event, error) {
+	// The chain tag is set by the device only
+	for _, tag := range req.Tags {
+		if len(tag) > 0 && tag[0] == constants.NostrChainTag {
+			return nostr.Event{}, constants.ErrorInvalidNostrTags
+		}
+	}
+	event := nostr.Event{Kind: req.Kind, Tags: req.Tags, Content: req.Content, CreatedAt: time.Now().Unix()}
+	if err := event.Validate(); err != nil {
+		return nostr.Event{}, err
+	}
+	id := identity.Get()
+	event.Tags = append(event.Tags, nostr.ChainTag(id.AppChain))
+	return nostr.Sign(id.AppKey, event)
+}
+
+// WriteKv stores the key-value pair after checking its provision, see HandleWriteKv
+func WriteKv(req WriteKvRequest) (WriteKvResponse, error) {
+	// Check key & value status
+	if req.Key == "" || req.Value == "" {
+		return WriteKvResponse{}, constants.ErrorKeyOrValueNotFound
+	}
+	// Check provision & protected
+	var provisioner string
+	if req.Provision != "" {
+		payload, err := hex.DecodeString(strings.TrimPrefix(req.Provision, "0x"))
+		if err != nil {
+			return WriteKvResponse{}, constants.ErrorFailedProvisionDecoding
+		}
+		// Check Length: 64 bytes public key || 65 bytes signature, see CHANGELOG.md for the former 128 bytes
+		if len(payload) != constants.ProvisionLength {
+			return WriteKvResponse{}, constants.ErrorInvalidProvisionLength
+		}
+		pubKey := payload[:64]
+		sig := payload[64:]
+		message := ProvisionSignable(identity.Get().AppPubKey, req.Key, req.Value)
+		if !encryption.VerifySignature(pubKey, message, sig) {
+			return WriteKvResponse{}, constants.ErrorFailedProvisionVerification
+		}
+		provisioner = hex.EncodeToString(pubKey)
+	}
+	// Check value length
+	if len(req.Value) > constants.MaxKvLength {
+		return WriteKvResponse{}, constants.ErrorValueTooLarge
+	}
+	// Check if the key exists
+	if kv.Exists(req.Key) && !req.Overwrite {
+		return WriteKvResponse{}, constants.ErrorKeyExists
+	}
+	kv.Store(kv.Entry{
+		Key:         req.Key,
+		Value:       req.Value,
+		Provisioner: provisioner,
+		Protector:   req.Protected,
+	})
+	return WriteKvResponse{Success: true}, nil
+}
+
+// ReadKv returns the value of the key
+func ReadKv(key string) (ReadKvResponse, error) {
+	if key == "" {
+		return ReadKvResponse{}, constants.ErrorKeyOrValueNotFound
+	}
+	entry, exists := kv.Load(key)
+	if !exists {
+		return ReadKvResponse{}, constants.ErrorKeyExists
+	}
+	// Todo: encrypt protected value using protector's public key
+	return ReadKvResponse{
+		Present:     true,
+		Value:       entry.Value,
+		Provisioned: entry.Provisioner != "",
+		Protected:   entry.Protector != "",
+		Provisioner: entry.Provisioner,
+		Protector:   entry.Protector,
+	}, nil
+}
+
+// DeleteKv deletes the key
+func DeleteKv(req DeleteKvRequest) (DeleteKvResponse, error) {
+	if req.Key == "" {
+		return DeleteKvResponse{}, constants.ErrorKeyOrValueNotFound
+	}
+	if !kv.Exists(req.Key) {
+		return DeleteKvResponse{}, constants.ErrorKeyDoesNotExist
+	}
+	kv.Delete(req.Key)
+	return DeleteKvResponse{Success: true}, nil
+}
+
+// GetQuota returns the number of keys used and the number of keys that can be written
+func GetQuota() QuotaResponse {
+	return QuotaResponse{Used: kv.Length(), Quota: constants.MaxKvEntries}
+}
+
+// GetConfigChange describes the current config to the subscribers of config changes, without its keys
+func GetConfigChange() ConfigChange {
+	return ConfigChange{
+		Generation:     config.Generation(),
+		AttestationVer: config.GetConfig().Version,
+		TeePlatformVer: config.GetConfig().TeePlatformVersion,
+	}
+}
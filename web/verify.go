@@ -0,0 +1,526 @@
+package web
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"teerminal/service/enrollment"
+	"teerminal/service/identity"
+	"teerminal/service/measurement"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /api/v1/verify
+
+func RegisterVerifyRoutes(router *gin.Engine) {
+	verify := router.Group("/api/v1/verify")
+	{
+		verify.POST("/signature", HandleVerifySignature)
+		verify.POST("/chain", HandleVerifyChain)
+		verify.POST("/attestation", HandleVerifyAttestation)
+		verify.POST("/enrollment", HandleVerifyEnrollment)
+		verify.POST("/vrf", HandleVerifyVrf)
+	}
+}
+
+type VerifySignatureRequest struct {
+	Data      string `json:"data" format:"hex"`             // Data is the signed data, the signature is over keccak256(data)
+	Signature string `json:"signature" format:"hex"`        // Signature is the 65 bytes r || s || v signature
+	PubKey    string `json:"pubKey,omitempty" format:"hex"` // PubKey is the expected 64 bytes public key, either PubKey or Address is required
+	Address   string `json:"address,omitempty"`             // Address is the expected ethereum address, either PubKey or Address is required
+}
+
+type VerifyChainRequest struct {
+	Chain string `json:"chain" format:"hex"`          // Chain is the concatenated 257 bytes certs
+	Root  string `json:"root,omitempty" format:"hex"` // Root is the 64 bytes vendor root public key, leave empty to use this device's vendor root
+}
+
+type VerifyAttestationRequest struct {
+	Attestation string      `json:"attestation" format:"hex"`    // Attestation is the requester's hex(64b nonce || 64b pubKey [|| 65b signature]) sent to /api/v1/device/version
+	Response    Attestation `json:"response"`                    // Response is the attestation returned by /api/v1/device/version
+	Root        string      `json:"root,omitempty" format:"hex"` // Root is the 64 bytes vendor root public key, leave empty to use this device's vendor root
+}
+
+type VerifyEnrollmentRequest struct {
+	Enrollment Enrollment `json:"enrollment"`                  // Enrollment is the enrollment returned by /api/v1/device/sign
+	Chain      string     `json:"chain" format:"hex"`          // Chain is the device cert chain from /api/v1/device/key, an enrollment is only valid with the device chain
+	Root       string     `json:"root,omitempty" format:"hex"` // Root is the 64 bytes vendor root public key, leave empty to use this device's vendor root
+}
+
+type VerifyVrfRequest struct {
+	PubKey string `json:"pubKey" format:"hex"` // PubKey is the 64 bytes public key, e.g. appPubKey from /api/v1/attestation/appkey
+	Alpha  string `json:"alpha" format:"hex"`  // Alpha is the VRF input
+	Proof  string `json:"proof" format:"hex"`  // Proof is the 81 bytes proof returned by /api/v1/attestation/vrf
+}
+
+type VerifyVrfResponse struct {
+	Valid  bool   `json:"valid"`
+	Output string `json:"output,omitempty" format:"hex"` // Output is the 32 bytes VRF output derived from the proof
+	Error  string `json:"error,omitempty"`
+}
+
+type SignatureDiagnostics struct {
+	Valid            bool   `json:"valid"`
+	Hash             string `json:"hash" format:"hex"`                      // Hash is keccak256 of the signed data
+	RecoveryId       int    `json:"recoveryId"`                             // RecoveryId is the normalized v (0 or 1), -1 if v is out of range
+	RecoveredPubKey  string `json:"recoveredPubKey,omitempty" format:"hex"` // RecoveredPubKey is the 64 bytes public key recovered from the signature
+	RecoveredAddress string `json:"recoveredAddress,omitempty"`             // RecoveredAddress is the address of the recovered public key
+	ExpectedAddress  string `json:"expectedAddress,omitempty"`              // ExpectedAddress is the address the signature was checked against
+	Error            string `json:"error,omitempty"`
+}
+
+type CertDiagnostics struct {
+	Index           int    `json:"index"`
+	Prover          string `json:"prover" format:"hex"`
+	Provee          string `json:"provee" format:"hex"`
+	Derivation      string `json:"derivation" format:"hex"`
+	Signature       string `json:"signature" format:"hex"`
+	Signer          string `json:"signer,omitempty" format:"hex"` // Signer is the public key recovered from the cert signature
+	SignatureValid  bool   `json:"signatureValid"`                // SignatureValid is true if the signer is the prover
+	DerivationValid bool   `json:"derivationValid"`               // DerivationValid is true if the prover is the root or the previous provee
+	Error           string `json:"error,omitempty"`
+}
+
+type ChainDiagnostics struct {
+	Valid bool              `json:"valid"`
+	Root  string            `json:"root" format:"hex"`
+	Leaf  string            `json:"leaf,omitempty" format:"hex"` // Leaf is the last provee of the chain
+	Certs []CertDiagnostics `json:"certs"`
+	Error string            `json:"error,omitempty"`
+}
+
+type MeasurementDiagnostics struct {
+	Valid     bool     `json:"valid"`
+	Registers []string `json:"registers" format:"hex"` // Registers are the values replayed from the event log
+	Error     string   `json:"error,omitempty"`
+}
+
+type VerifyAttestationResponse struct {
+	Valid        bool                   `json:"valid"`
+	Signable     string                 `json:"signable" format:"hex"` // Signable is the reconstructed nonce || pubKey || teePlatformVersion || version || timestamp || keccak256(registers)
+	Chain        ChainDiagnostics       `json:"chain"`
+	Measurements MeasurementDiagnostics `json:"measurements"`
+	Signature    SignatureDiagnostics   `json:"signature"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+type VerifyEnrollmentResponse struct {
+	Valid     bool                 `json:"valid"`
+	Signable  string               `json:"signable" format:"hex"` // Signable is the reconstructed "DEPHY_ID_SIGNED_MESSAGE:" || keccak256(payload)
+	Chain     *ChainDiagnostics    `json:"chain,omitempty"`
+	Signature SignatureDiagnostics `json:"signature"`
+	Error     string               `json:"error,omitempty"`
+}
+
+func decodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(s, "0x"))
+}
+
+// decodeField decodes the hex of a request field, the error names the field
+func decodeField(name string, s string) ([]byte, error) {
+	decoded, err := decodeHex(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", constants.ErrorFailedDecodeMessage, name)
+	}
+	return decoded, nil
+}
+
+func decodeRoot(s string) ([]byte, error) {
+	if s == "" {
+		return identity.Get().VendorRootPubKey, nil
+	}
+	root, err := decodeHex(s)
+	if err != nil || len(root) != 64 {
+		return nil, errors.New(constants.MsgErrorInvalidPublicKey)
+	}
+	return root, nil
+}
+
+// CheckSignature verifies the signature over data against the expected address, collecting diagnostics
+func CheckSignature(data []byte, signature []byte, expected common.Address) SignatureDiagnostics {
+	diag := SignatureDiagnostics{
+		Hash:            fmt.Sprintf("%x", crypto.Keccak256(data)),
+		RecoveryId:      -1,
+		ExpectedAddress: expected.Hex(),
+	}
+	if len(signature) != 65 {
+		diag.Error = constants.MsgErrorInvalidSignatureLength
+		return diag
+	}
+	if v := signature[64]; v <= 1 || v == 27 || v == 28 {
+		diag.RecoveryId = int(v % 27)
+	}
+	rec, err := encryption.RecoverPublicKey(data, signature)
+	if err != nil {
+		diag.Error = err.Error()
+		return diag
+	}
+	recovered := rec.SerializeUncompressed()[1:]
+	diag.RecoveredPubKey = fmt.Sprintf("%x", recovered)
+	diag.RecoveredAddress = common.BytesToAddress(crypto.Keccak256(recovered)[12:]).Hex()
+	diag.Valid = diag.RecoveredAddress == diag.ExpectedAddress
+	if !diag.Valid {
+		diag.Error = constants.MsgErrorSignatureMismatch
+	}
+	return diag
+}
+
+// CheckChain verifies the cert chain against the root, collecting diagnostics for every cert
+func CheckChain(chain []byte, root []byte) ChainDiagnostics {
+	diag := ChainDiagnostics{Root: fmt.Sprintf("%x", root), Certs: []CertDiagnostics{}}
+	checks, err := encryption.CheckCertChain(chain, root)
+	for i, check := range checks {
+		cert := CertDiagnostics{
+			Index:           i,
+			Prover:          fmt.Sprintf("%x", check.Prover),
+			Provee:          fmt.Sprintf("%x", check.Provee),
+			Derivation:      fmt.Sprintf("%x", check.Derivation),
+			Signature:       fmt.Sprintf("%x", check.Signature),
+			Signer:          fmt.Sprintf("%x", check.Signer),
+			SignatureValid:  check.SignatureValid,
+			DerivationValid: check.DerivationValid,
+		}
+		if check.Error != nil {
+			cert.Error = check.Error.Error()
+		}
+		diag.Certs = append(diag.Certs, cert)
+	}
+	if err != nil {
+		diag.Error = err.Error()
+		return diag
+	}
+	diag.Valid = true
+	diag.Leaf = diag.Root
+	if len(checks) > 0 {
+		diag.Leaf = fmt.Sprintf("%x", checks[len(checks)-1].Provee)
+	}
+	return diag
+}
+
+// CheckMeasurements replays the event log and compares the result with the reported registers
+func CheckMeasurements(m Measurements) MeasurementDiagnostics {
+	diag := MeasurementDiagnostics{Registers: []string{}}
+	replayed, err := measurement.Replay(m.EventLog)
+	if err != nil {
+		diag.Error = err.Error()
+		return diag
+	}
+	for _, register := range replayed {
+		diag.Registers = append(diag.Registers, fmt.Sprintf("%x", register))
+	}
+	if len(m.Registers) != len(diag.Registers) {
+		diag.Error = constants.MsgErrorEventLogMismatch
+		return diag
+	}
+	for i := range m.Registers {
+		if strings.TrimPrefix(strings.ToLower(m.Registers[i]), "0x") != diag.Registers[i] {
+			diag.Error = constants.MsgErrorEventLogMismatch
+			return diag
+		}
+	}
+	diag.Valid = true
+	return diag
+}
+
+// CheckAttestation verifies a version attestation over nonce and pubKey against the root, collecting diagnostics
+func CheckAttestation(nonce []byte, pubKey []byte, attestation Attestation, root []byte) (VerifyAttestationResponse, error) {
+	chain, err := decodeField("deviceCert", attestation.Cert)
+	if err != nil {
+		return VerifyAttestationResponse{}, err
+	}
+	signature, err := decodeField("signature", attestation.Signature)
+	if err != nil {
+		return VerifyAttestationResponse{}, err
+	}
+	// Registers are signed as a digest, so they are taken from the replayed event log
+	measurements := CheckMeasurements(attestation.Measurements)
+	registers := make([][]byte, len(measurements.Registers))
+	for i, register := range measurements.Registers {
+		registers[i], _ = decodeHex(register)
+	}
+	signable := VersionSignable(nonce, pubKey, attestation.TeePlatformVer, attestation.AttestationVer, attestation.Timestamp, registers)
+	resp := VerifyAttestationResponse{
+		Signable:     fmt.Sprintf("%x", signable),
+		Chain:        CheckChain(chain, root),
+		Measurements: measurements,
+	}
+	// The attestation is signed by the leaf of the device chain
+	leaf, _ := decodeHex(resp.Chain.Leaf)
+	resp.Signature = CheckSignature(signable, signature, common.BytesToAddress(crypto.Keccak256(leaf)[12:]))
+	// Only the device chain is accepted, the app key of an app chain signs any data through /api/v1/attestation/sign
+	derivation := make([]byte, 64)
+	copy(derivation, constants.DeviceRootKey)
+	switch {
+	case len(chain) != constants.DeviceChainCerts*encryption.CertLength:
+		resp.Error = constants.MsgErrorInvalidChainLength
+	case !resp.Chain.Valid:
+		resp.Error = resp.Chain.Error
+	case resp.Chain.Certs[len(resp.Chain.Certs)-1].Derivation != hex.EncodeToString(derivation):
+		resp.Error = constants.MsgErrorNotDeviceChain
+	case !resp.Measurements.Valid:
+		resp.Error = resp.Measurements.Error
+	case !resp.Signature.Valid:
+		resp.Error = resp.Signature.Error
+	default:
+		resp.Valid = true
+	}
+	return resp, nil
+}
+
+// HandleVerifySignature godoc
+// @Summary Verify a signature against a public key or address
+// @Description Verify a 65 bytes signature over keccak256(data), against either the 64 bytes public key or the ethereum address, returns the recovered signer for diagnostics
+// @Tags verify
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param request body VerifySignatureRequest true "Signature to verify"
+// @Success 200 {object} SignatureDiagnostics
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/verify/signature [post]
+func HandleVerifySignature(c *gin.Context) {
+	var req VerifySignatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	data, err := decodeField("data", req.Data)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	signature, err := decodeField("signature", req.Signature)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	// Resolve the expected address from the public key or the address
+	var expected common.Address
+	switch {
+	case req.PubKey != "":
+		pubKey, err := decodeField("pubKey", req.PubKey)
+		if err != nil {
+			c.JSON(400, ErrorResponse{Error: err.Error()})
+			c.Next()
+			return
+		}
+		if len(pubKey) != 64 {
+			c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidPublicKey})
+			c.Next()
+			return
+		}
+		expected = common.BytesToAddress(crypto.Keccak256(pubKey)[12:])
+	case req.Address != "":
+		if !common.IsHexAddress(req.Address) {
+			c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidAddress})
+			c.Next()
+			return
+		}
+		expected = common.HexToAddress(req.Address)
+	default:
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorMissingVerificationKey})
+		c.Next()
+		return
+	}
+	respond(c, CheckSignature(data, signature, expected))
+}
+
+// HandleVerifyChain godoc
+// @Summary Verify a cert chain against a vendor root
+// @Description Verify a cert chain with the same rules as CertLib.verifyCertChain, returns the result of every cert for diagnostics
+// @Tags verify
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param request body VerifyChainRequest true "Chain to verify"
+// @Success 200 {object} ChainDiagnostics
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/verify/chain [post]
+func HandleVerifyChain(c *gin.Context) {
+	var req VerifyChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	chain, err := decodeField("chain", req.Chain)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	root, err := decodeRoot(req.Root)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	respond(c, CheckChain(chain, root))
+}
+
+// HandleVerifyAttestation godoc
+// @Summary Verify a version attestation produced by /api/v1/device/version
+// @Description Verify the device cert chain against the vendor root, replay the measurement event log, and verify the device signature over nonce || pubKey || teePlatformVersion || version || keccak256(registers)
+// @Tags verify
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param request body VerifyAttestationRequest true "Attestation to verify"
+// @Success 200 {object} VerifyAttestationResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/verify/attestation [post]
+func HandleVerifyAttestation(c *gin.Context) {
+	var req VerifyAttestationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	attestationRaw, err := decodeHex(req.Attestation)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedHexDecodeRemoteAttestation})
+		c.Next()
+		return
+	}
+	if len(attestationRaw) < 128 {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorWrongRemoteAttestationLength})
+		c.Next()
+		return
+	}
+	root, err := decodeRoot(req.Root)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	resp, err := CheckAttestation(attestationRaw[:64], attestationRaw[64:128], req.Response, root)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	respond(c, resp)
+}
+
+// HandleVerifyEnrollment godoc
+// @Summary Verify an enrollment produced by /api/v1/device/sign
+// @Description Verify the device chain against the vendor root, and the device signature over "DEPHY_ID_SIGNED_MESSAGE:" || keccak256(payload) against its leaf
+// @Tags verify
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param request body VerifyEnrollmentRequest true "Enrollment to verify"
+// @Success 200 {object} VerifyEnrollmentResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/verify/enrollment [post]
+func HandleVerifyEnrollment(c *gin.Context) {
+	var req VerifyEnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	deviceKey, err := decodeField("enrollment.deviceKey", req.Enrollment.DeviceKey)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	if len(deviceKey) != 64 {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidPublicKey})
+		c.Next()
+		return
+	}
+	payload, err := decodeField("enrollment.payload", req.Enrollment.Payload)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	signature, err := decodeField("enrollment.signature", req.Enrollment.Signature)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	chain, err := decodeField("chain", req.Chain)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	root, err := decodeRoot(req.Root)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	// The diagnostics explain the outcome, which is decided by enrollment.Verify alone
+	signable := enrollment.Signable(payload)
+	chainDiag := CheckChain(chain, root)
+	resp := VerifyEnrollmentResponse{
+		Signable:  fmt.Sprintf("%x", signable),
+		Chain:     &chainDiag,
+		Signature: CheckSignature(signable, signature, common.BytesToAddress(crypto.Keccak256(deviceKey)[12:])),
+	}
+	if _, err := enrollment.Verify(chain, root, deviceKey, payload, signature); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Valid = true
+	}
+	respond(c, resp)
+}
+
+// HandleVerifyVrf godoc
+// @Summary Verify a VRF proof produced by /api/v1/attestation/vrf
+// @Description Verify an ECVRF-SECP256K1-SHA256-TAI proof of alpha against the public key, and return the VRF output
+// @Tags verify
+// @Accept application/json,application/cbor
+// @Produce application/json,application/cbor
+// @Param request body VerifyVrfRequest true "Proof to verify"
+// @Success 200 {object} VerifyVrfResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/verify/vrf [post]
+func HandleVerifyVrf(c *gin.Context) {
+	var req VerifyVrfRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorFailedToBindRequest})
+		c.Next()
+		return
+	}
+	pubKey, err := decodeField("pubKey", req.PubKey)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	if len(pubKey) != 64 {
+		c.JSON(400, ErrorResponse{Error: constants.MsgErrorInvalidPublicKey})
+		c.Next()
+		return
+	}
+	alpha, err := decodeField("alpha", req.Alpha)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	proof, err := decodeField("proof", req.Proof)
+	if err != nil {
+		c.JSON(400, ErrorResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	output, err := encryption.VrfVerify(pubKey, alpha, proof)
+	if err != nil {
+		respond(c, VerifyVrfResponse{Error: err.Error()})
+		c.Next()
+		return
+	}
+	respond(c, VerifyVrfResponse{Valid: true, Output: fmt.Sprintf("%x", output)})
+}
@@ -0,0 +1,131 @@
+package web
+
+import (
+	"encoding/hex"
+	"fmt"
+	"teerminal/constants"
+	"teerminal/service/encryption"
+	"testing"
+)
+
+type verifyResult struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error"`
+}
+
+// requestAttestation requests a version attestation signed for a fresh nonce, and returns the attestation query with it
+func requestAttestation(t *testing.T, requesterKey []byte) (string, Attestation) {
+	var challenge Challenge
+	mustCall(t, "GET", "/api/v1/device/challenge", nil, &challenge)
+	nonce, _ := decodeHex(challenge.Nonce)
+	request := append(nonce, encryption.GetPublicKey(requesterKey)...)
+	signature, err := encryption.Sign(requesterKey, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := hex.EncodeToString(append(request, signature...))
+	var attestation Attestation
+	mustCall(t, "GET", "/api/v1/device/version?attestation="+query, nil, &attestation)
+	return query, attestation
+}
+
+func TestVerify(t *testing.T) {
+	data := hex.EncodeToString([]byte("verify"))
+	var signed SignResponse
+	mustCall(t, "POST", "/api/v1/attestation/sign", SignRequest{Data: data}, &signed)
+	var deviceKey DeviceKey
+	mustCall(t, "GET", "/api/v1/device/key", nil, &deviceKey)
+	var enrollment Enrollment
+	mustCall(t, "POST", "/api/v1/device/sign", SignRequest{Data: data}, &enrollment)
+	var vrf VrfResponse
+	mustCall(t, "POST", "/api/v1/attestation/vrf", VrfRequest{Alpha: data}, &vrf)
+	requesterKey := encryption.DerivePrivateKey([]byte("requester"), []byte("web"))
+	query, attestation := requestAttestation(t, requesterKey)
+
+	tamperedAttestation := attestation
+	tamperedAttestation.TeePlatformVer++
+	tamperedEnrollment := enrollment
+	tamperedEnrollment.Payload = flip(enrollment.Payload)
+
+	cases := []struct {
+		name  string
+		path  string
+		body  interface{}
+		valid bool
+	}{
+		{"signature", "/api/v1/verify/signature", VerifySignatureRequest{Data: data, Signature: signed.Signature, PubKey: signed.PubKey}, true},
+		{"tampered signature", "/api/v1/verify/signature", VerifySignatureRequest{Data: data, Signature: flip(signed.Signature[:128]) + signed.Signature[128:], PubKey: signed.PubKey}, false},
+		{"tampered signed data", "/api/v1/verify/signature", VerifySignatureRequest{Data: flip(data), Signature: signed.Signature, PubKey: signed.PubKey}, false},
+		{"chain", "/api/v1/verify/chain", VerifyChainRequest{Chain: deviceKey.Cert}, true},
+		{"tampered chain", "/api/v1/verify/chain", VerifyChainRequest{Chain: flip(deviceKey.Cert[:len(deviceKey.Cert)-2]) + deviceKey.Cert[len(deviceKey.Cert)-2:]}, false},
+		{"attestation", "/api/v1/verify/attestation", VerifyAttestationRequest{Attestation: query, Response: attestation}, true},
+		{"tampered attestation", "/api/v1/verify/attestation", VerifyAttestationRequest{Attestation: query, Response: tamperedAttestation}, false},
+		{"attestation for another nonce", "/api/v1/verify/attestation", VerifyAttestationRequest{Attestation: flip(query[:128]) + query[128:], Response: attestation}, false},
+		{"enrollment", "/api/v1/verify/enrollment", VerifyEnrollmentRequest{Enrollment: enrollment, Chain: deviceKey.Cert}, true},
+		{"tampered enrollment", "/api/v1/verify/enrollment", VerifyEnrollmentRequest{Enrollment: tamperedEnrollment, Chain: deviceKey.Cert}, false},
+		{"vrf", "/api/v1/verify/vrf", VerifyVrfRequest{PubKey: vrf.PubKey, Alpha: data, Proof: vrf.Proof}, true},
+		{"tampered vrf", "/api/v1/verify/vrf", VerifyVrfRequest{PubKey: vrf.PubKey, Alpha: data, Proof: flip(vrf.Proof)}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var result verifyResult
+			mustCall(t, "POST", tc.path, tc.body, &result)
+			if result.Valid != tc.valid {
+				t.Errorf("expected valid %v, got %+v", tc.valid, result)
+			}
+		})
+	}
+}
+
+// An app key signature over a made-up version attestation must not pass as an attestation of the device
+func TestVerifyAttestationAppChain(t *testing.T) {
+	requesterKey := encryption.DerivePrivateKey([]byte("requester"), []byte("app chain"))
+	query, attestation := requestAttestation(t, requesterKey)
+	var appKey ApplicationKey
+	mustCall(t, "GET", "/api/v1/attestation/appkey", nil, &appKey)
+
+	forged := attestation
+	forged.Cert = appKey.Cert
+	forged.AttestationVer = "9.9.9"
+	forged.TeePlatformVer = 99
+	registers := make([][]byte, len(forged.Registers))
+	for i, register := range forged.Registers {
+		registers[i], _ = decodeHex(register)
+	}
+	raw, _ := decodeHex(query)
+	signable := VersionSignable(raw[:64], raw[64:128], forged.TeePlatformVer, forged.AttestationVer, forged.Timestamp, registers)
+	var signed SignResponse
+	mustCall(t, "POST", "/api/v1/attestation/sign", SignRequest{Data: hex.EncodeToString(signable)}, &signed)
+	forged.Signature = signed.Signature
+
+	var resp VerifyAttestationResponse
+	mustCall(t, "POST", "/api/v1/verify/attestation", VerifyAttestationRequest{Attestation: query, Response: forged}, &resp)
+	if !resp.Chain.Valid || !resp.Signature.Valid {
+		t.Fatalf("expected the app chain and the app key signature to verify on their own, got %+v", resp)
+	}
+	if resp.Valid || resp.Error != constants.MsgErrorInvalidChainLength {
+		t.Errorf("expected %q, got valid %v with %q", constants.MsgErrorInvalidChainLength, resp.Valid, resp.Error)
+	}
+}
+
+func TestVerifyMalformedHex(t *testing.T) {
+	cases := []struct {
+		path  string
+		body  interface{}
+		field string
+	}{
+		{"/api/v1/verify/chain", VerifyChainRequest{Chain: "zz"}, "chain"},
+		{"/api/v1/verify/signature", VerifySignatureRequest{Data: "00", Signature: "zz"}, "signature"},
+		{"/api/v1/verify/enrollment", VerifyEnrollmentRequest{Enrollment: Enrollment{DeviceKey: hex.EncodeToString(make([]byte, 64)), Signature: "zz"}}, "enrollment.signature"},
+		{"/api/v1/verify/vrf", VerifyVrfRequest{PubKey: hex.EncodeToString(make([]byte, 64)), Alpha: "00", Proof: "0x0g"}, "proof"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			var resp ErrorResponse
+			expected := fmt.Sprintf("%s: %s", constants.MsgErrorFailedDecodeMessage, tc.field)
+			if status := call(t, "POST", tc.path, tc.body, &resp); status != 400 || resp.Error != expected {
+				t.Errorf("expected 400 %q, got %d %q", expected, status, resp.Error)
+			}
+		})
+	}
+}
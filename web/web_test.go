@@ -0,0 +1,83 @@
+package web
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"teerminal/config"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testRouter *gin.Engine
+
+func TestMain(m *testing.M) {
+	dataDir, err := os.MkdirTemp("", "teerminal-web")
+	if err != nil {
+		panic(err)
+	}
+	config.Set(&config.Config{
+		Version:            "0.0.1-test",
+		TeePlatformVersion: 1,
+		VendorRoot:         "dbbe0cd0b4c7bc4ab34829c96f35bb0011d06dc3bdf0b900401a71a8f7c4c471",
+		RootKey:            "cd2f10b3d7d306a27199ccf51868c1b0859f824b6fab53710f06a092ae40226f",
+		AppName:            "EmulatorDefault",
+		DataDir:            dataDir,
+		AdminToken:         "admin",
+	})
+	gin.SetMode(gin.ReleaseMode)
+	testRouter = gin.New()
+	RegisterRoutes(testRouter)
+	code := m.Run()
+	os.RemoveAll(dataDir)
+	os.Exit(code)
+}
+
+// call serves the request with body encoded as JSON, decodes the response into resp and returns the status
+func call(t *testing.T, method string, path string, body interface{}, resp interface{}) int {
+	t.Helper()
+	return callAs(t, "admin", method, path, body, resp)
+}
+
+// callAs is call with the bearer token, an empty token sends no Authorization header
+func callAs(t *testing.T, token string, method string, path string, body interface{}, resp interface{}) int {
+	t.Helper()
+	var encoded []byte
+	if body != nil {
+		var err error
+		if encoded, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(encoded))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	testRouter.ServeHTTP(recorder, req)
+	if resp != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), resp); err != nil {
+			t.Fatalf("%s %s: %v in %s", method, path, err, recorder.Body.String())
+		}
+	}
+	return recorder.Code
+}
+
+// mustCall is call for requests which must succeed
+func mustCall(t *testing.T, method string, path string, body interface{}, resp interface{}) {
+	t.Helper()
+	if status := call(t, method, path, body, resp); status != 200 {
+		t.Fatalf("%s %s: status %d", method, path, status)
+	}
+}
+
+// flip flips a bit of the last byte of a hex string
+func flip(s string) string {
+	decoded, _ := decodeHex(s)
+	decoded[len(decoded)-1] ^= 0x01
+	return hex.EncodeToString(decoded)
+}